@@ -1,11 +1,21 @@
 package config
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/jq1836/DDNS/executor"
 )
 
 // Config holds all configuration for the application
@@ -20,20 +30,180 @@ type Config struct {
 	HTTP HTTPConfig `json:"http"`
 }
 
+// Clone returns a deep copy of the configuration, safe to modify without affecting c
+func (c Config) Clone() Config {
+	clone := c
+
+	// Slices share their backing array, so copy them explicitly
+	if c.DDNS.Providers != nil {
+		clone.DDNS.Providers = make([]ProviderConfig, len(c.DDNS.Providers))
+		copy(clone.DDNS.Providers, c.DDNS.Providers)
+		for i := range clone.DDNS.Providers {
+			clone.DDNS.Providers[i].Headers = maps.Clone(c.DDNS.Providers[i].Headers)
+		}
+	}
+	if c.DDNS.Records != nil {
+		clone.DDNS.Records = make([]RecordConfig, len(c.DDNS.Records))
+		copy(clone.DDNS.Records, c.DDNS.Records)
+	}
+	clone.HTTP.Headers = maps.Clone(c.HTTP.Headers)
+
+	return clone
+}
+
+// Merge returns a new configuration with every non-zero field of override replacing that of base
+// Zero fields leave base intact, nested structs are merged field by field, maps are merged key by key,
+// and slices tagged `merge:"append"` are appended to base's instead of replacing them
+// Either argument may be nil; neither is modified
+func Merge(base, override *Config) *Config {
+	merged := Config{}
+	if base != nil {
+		merged = base.Clone()
+	}
+	if override != nil {
+		mergeStruct(reflect.ValueOf(&merged).Elem(), reflect.ValueOf(override.Clone()))
+	}
+	return &merged
+}
+
+// mergeStruct copies the non-zero fields of override into dst, a settable struct of the same type
+func mergeStruct(dst, override reflect.Value) {
+	for i := 0; i < dst.NumField(); i++ {
+		field, value := dst.Field(i), override.Field(i)
+		if value.IsZero() {
+			continue
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			mergeStruct(field, value)
+		case reflect.Map:
+			if field.IsNil() {
+				field.Set(reflect.MakeMapWithSize(value.Type(), value.Len()))
+			}
+			iter := value.MapRange()
+			for iter.Next() {
+				field.SetMapIndex(iter.Key(), iter.Value())
+			}
+		case reflect.Slice:
+			if dst.Type().Field(i).Tag.Get("merge") == "append" {
+				field.Set(reflect.AppendSlice(field, value))
+			} else {
+				field.Set(value)
+			}
+		default:
+			field.Set(value)
+		}
+	}
+}
+
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port         int      `json:"port"`
 	Host         string   `json:"host"`
 	ReadTimeout  Duration `json:"read_timeout"`
 	WriteTimeout Duration `json:"write_timeout"`
+
+	// ShutdownTimeout is how long an in-flight update may run after a shutdown signal
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+
+	// PIDFile, when set, receives the process ID at startup and is removed on shutdown
+	PIDFile string `json:"pid_file"`
 }
 
 // DDNSConfig holds DDNS-related configuration
 type DDNSConfig struct {
 	Provider       string   `json:"provider"`
-	Domain         string   `json:"domain"`
+	Domain         string   `json:"domain"`   // One domain, or a comma-separated list (see Domains)
+	Username       string   `json:"username"` // Account name for providers that pair it with the API key
 	APIKey         string   `json:"api_key"`
+	APIKeyFile     string   `json:"api_key_file"` // Takes precedence over APIKey when set
+	ZoneID         string   `json:"zone_id"`
+	RecordType     string   `json:"record_type"`
+	TTL            int      `json:"ttl"`    // Record TTL in seconds; providers ignore values below their minimum
+	Target         string   `json:"target"` // Target hostname for CNAME records
+	FilePath       string   `json:"file_path"`
 	UpdateInterval Duration `json:"update_interval"`
+	UpdateJitter   int      `json:"update_jitter_percent"` // Random variation of each interval, as a percentage of it
+
+	// Records lists fully specified records to update instead of Domain and RecordType
+	Records []RecordConfig `json:"records"`
+
+	// Providers lists additional provider blocks the record is mirrored to
+	// When set, each block supplies its own provider name and credentials
+	Providers []ProviderConfig `json:"providers" merge:"append"`
+
+	// FailoverProvider is tried, with FailoverAPIKey, only when an update on the primary fails
+	FailoverProvider string `json:"failover_provider"`
+	FailoverAPIKey   string `json:"failover_api_key"`
+
+	// AllowPrivateIP publishes detected RFC 1918 addresses, e.g. for home labs on private DNS
+	AllowPrivateIP bool `json:"allow_private_ip"`
+
+	// ForceUpdate pushes every update even when the provider's record already matches
+	ForceUpdate bool `json:"force_update"`
+
+	// ConfirmIPChange re-detects a changed IP with an independent method and only updates if both agree
+	ConfirmIPChange bool `json:"confirm_ip_change"`
+
+	// ClearOnShutdown removes the published records after a graceful shutdown, for providers that support it
+	ClearOnShutdown bool `json:"clear_on_shutdown"`
+
+	// RecordCacheTTL trusts the record value last read from the provider for this long when checking
+	// whether an update is needed, saving a provider API call on most checks; 0 disables it
+	RecordCacheTTL Duration `json:"record_cache_ttl"`
+
+	// MaxConsecutiveFailures stops the client after that many failed update cycles in a row; 0 never stops
+	MaxConsecutiveFailures int `json:"max_consecutive_failures"`
+
+	// VerifyPropagation polls DNS after an update until it serves the new value or PropagationTimeout passes
+	VerifyPropagation  bool     `json:"verify_propagation"`
+	PropagationTimeout Duration `json:"propagation_timeout"`
+
+	// Nameserver is the host or host:port asked when verifying propagation and reading records of
+	// providers without read support, such as the zone's authoritative server; the system resolver when empty
+	Nameserver string `json:"nameserver"`
+}
+
+// Domains returns the configured domains: those of Records when set, otherwise Domain split on commas and trimmed
+func (d DDNSConfig) Domains() []string {
+	if len(d.Records) > 0 {
+		domains := make([]string, len(d.Records))
+		for i, record := range d.Records {
+			domains[i] = strings.TrimSpace(record.Domain)
+		}
+		return domains
+	}
+
+	parts := strings.Split(d.Domain, ",")
+	domains := make([]string, 0, len(parts))
+	for _, part := range parts {
+		domains = append(domains, strings.TrimSpace(part))
+	}
+	return domains
+}
+
+// RecordConfig describes one record to update with the detected address of its family
+type RecordConfig struct {
+	Domain     string `json:"domain"`
+	RecordType string `json:"record_type"` // A or AAAA
+	TTL        int    `json:"ttl"`         // Defaults to the global TTL when zero
+}
+
+// ProviderConfig holds the credentials for one provider when mirroring to several
+type ProviderConfig struct {
+	Provider   string `json:"provider"`
+	Username   string `json:"username"`
+	APIKey     string `json:"api_key"`
+	APIKeyFile string `json:"api_key_file"` // Takes precedence over APIKey when set
+	ZoneID     string `json:"zone_id"`
+	FilePath   string `json:"file_path"`
+
+	// Headers are sent with every request to this provider, e.g. a custom auth header
+	Headers map[string]string `json:"headers"`
+
+	// Proxy overrides HTTPConfig.Proxy for this provider's requests
+	Proxy string `json:"proxy"`
 }
 
 // HTTPConfig holds HTTP client configuration
@@ -42,21 +212,95 @@ type HTTPConfig struct {
 	MaxRetries int      `json:"max_retries"`
 	RetryDelay Duration `json:"retry_delay"`
 	UserAgent  string   `json:"user_agent"`
+
+	// Headers are sent with every outgoing request, in addition to User-Agent
+	Headers map[string]string `json:"headers"`
+
+	// Proxy is an http, https or socks5 URL every outgoing request goes through
+	// When empty, HTTP_PROXY, HTTPS_PROXY and NO_PROXY from the environment apply
+	Proxy string `json:"proxy"`
+
+	// IPDetectionMethod is one of "http", "stun", "stun_with_http_fallback" or "interface"
+	IPDetectionMethod string `json:"ip_detection_method"`
+
+	// IPv6Interface limits "interface" detection to one interface, e.g. "eth0"
+	IPv6Interface string `json:"ipv6_interface"`
+
+	// SkipTemporaryIPv6 makes "interface" detection ignore RFC 4941 temporary addresses
+	SkipTemporaryIPv6 bool `json:"skip_temporary_ipv6"`
+}
+
+// RetryStrategy builds the retry strategy described by MaxRetries and RetryDelay
+func (h HTTPConfig) RetryStrategy() executor.RetryStrategy {
+	// MaxRetries is the total number of attempts; always make at least one
+	maxAttempts := h.MaxRetries
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	retryDelay := h.RetryDelay.Duration
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+
+	return executor.NewExponentialBackoffStrategy(maxAttempts, retryDelay, 2.0)
+}
+
+// NewExecutor creates an executor honoring the configured retries and timeout
+// Additional options are applied after the configured strategies
+func (h HTTPConfig) NewExecutor(options ...executor.ExecutorOption) *executor.Executor {
+	timeout := h.Timeout.Duration
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+
+	return executor.NewExecutor(append([]executor.ExecutorOption{
+		executor.WithRetryStrategy(h.RetryStrategy()),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(timeout)),
+	}, options...)...)
 }
 
 // Duration is a wrapper around time.Duration for JSON unmarshaling
+// It accepts either a duration string ("5m") or a number of whole seconds (300)
 type Duration struct {
 	time.Duration
 }
 
 // UnmarshalJSON implements json.Unmarshaler for Duration
 func (d *Duration) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	duration, err := durationFromValue(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+// UnmarshalYAML implements the yaml.v2 Unmarshaler interface for Duration
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
 		return err
 	}
 
-	duration, err := time.ParseDuration(s)
+	duration, err := durationFromValue(value)
+	if err != nil {
+		return err
+	}
+
+	d.Duration = duration
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler for Duration
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := parseDuration(string(text))
 	if err != nil {
 		return err
 	}
@@ -70,14 +314,92 @@ func (d Duration) MarshalJSON() ([]byte, error) {
 	return json.Marshal(d.Duration.String())
 }
 
-// Load loads configuration from JSON file with fallback to environment variables
+// durationFromValue converts a decoded string or number into a duration
+func durationFromValue(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case string:
+		return parseDuration(v)
+	case float64:
+		return secondsToDuration(v)
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case int64:
+		return time.Duration(v) * time.Second, nil
+	case uint64:
+		return time.Duration(v) * time.Second, nil
+	default:
+		return 0, fmt.Errorf("invalid duration: %v", value)
+	}
+}
+
+// parseDuration parses a duration string, treating a bare integer as seconds
+func parseDuration(s string) (time.Duration, error) {
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// secondsToDuration converts a whole number of seconds, rejecting fractions
+func secondsToDuration(seconds float64) (time.Duration, error) {
+	if seconds != float64(int64(seconds)) {
+		return 0, fmt.Errorf("duration in seconds must be a whole number, got %v", seconds)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// Load loads the configuration in layers: defaults, then the JSON file if present, then environment variables
+// Each layer overrides exactly the settings it gives, zero values included; CONFIG_ENV_PREFIX selects a prefix for all other variables
 func Load() (*Config, error) {
-	config := &Config{}
+	return LoadWithPrefix(os.Getenv("CONFIG_ENV_PREFIX"))
+}
+
+// LoadWithPrefix behaves like Load but reads environment variables named prefix + "_" + name
+// An empty prefix reads the unprefixed names; a CONFIG_PATH URL is loaded through LoadFromURL
+func LoadWithPrefix(prefix string) (*Config, error) {
+	configPath := getConfigPath(prefix)
+	if isConfigURL(configPath) {
+		return loadFromURL(context.Background(), configPath, ConfigFormatJSON, prefix)
+	}
+
+	// The JSON file is optional; without one only the environment overrides the defaults
+	config := defaultConfig()
+	if err := loadFromJSON(config, configPath); err != nil {
+		if configPath == configStdinPath {
+			// Piped configuration is asked for explicitly, so failing to load it is fatal
+			return nil, err
+		}
+		config = defaultConfig()
+	}
+
+	return layerConfig(config, prefix)
+}
+
+// LoadFromURL loads the configuration served at url, layered like Load between the defaults and the environment
+// format names the document's encoding, and only ConfigFormatJSON (or "") is supported
+func LoadFromURL(ctx context.Context, url, format string) (*Config, error) {
+	return loadFromURL(ctx, url, format, os.Getenv("CONFIG_ENV_PREFIX"))
+}
 
-	// Try to load from JSON file first
-	if err := loadFromJSON(config); err != nil {
-		// If JSON loading fails, fall back to environment variables
-		loadFromEnvironment(config)
+// LoadFromFile loads the JSON file at path, layered like Load between the defaults and the environment
+// Unlike Load, a missing or unreadable file is an error
+func LoadFromFile(path string) (*Config, error) {
+	config := defaultConfig()
+	if err := loadFromJSON(config, path); err != nil {
+		return nil, err
+	}
+
+	return layerConfig(config, os.Getenv("CONFIG_ENV_PREFIX"))
+}
+
+// layerConfig applies the environment over config, the defaults overlaid with the loaded document,
+// then resolves and validates the result
+func layerConfig(config *Config, prefix string) (*Config, error) {
+	loadFromEnvironment(config, prefix)
+
+	// Read secrets from files (e.g. Docker/Kubernetes secrets)
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
 	}
 
 	// Validate configuration
@@ -88,101 +410,461 @@ func Load() (*Config, error) {
 	return config, nil
 }
 
-// loadFromJSON loads configuration from a JSON file
-func loadFromJSON(config *Config) error {
-	configPath := getConfigPath()
+// loadFromJSON loads configuration from a JSON file or, for "-", standard input
+func loadFromJSON(config *Config, configPath string) error {
+	var data []byte
+	var err error
+	if configPath == configStdinPath {
+		data, err = io.ReadAll(configStdin)
+	} else {
+		data, err = os.ReadFile(configPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read config %s: %w", configSourceName(configPath), err)
+	}
+
+	return parseConfig(data, configSourceName(configPath), config)
+}
+
+// loadFromURL fetches the configuration at url and layers it like LoadWithPrefix
+// CONFIG_URL_TIMEOUT bounds the fetch and CONFIG_URL_AUTH_HEADER, if set, is sent as the Authorization header
+func loadFromURL(ctx context.Context, url, format, prefix string) (*Config, error) {
+	if format != "" && format != ConfigFormatJSON {
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+
+	timeout := configFetchTimeout
+	if value := getEnv(prefix, "CONFIG_URL_TIMEOUT", ""); value != "" {
+		parsed, err := parseDuration(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid CONFIG_URL_TIMEOUT %q", value)
+		}
+		timeout = parsed
+	}
 
-	data, err := os.ReadFile(configPath)
+	authHeader := getEnv(prefix, "CONFIG_URL_AUTH_HEADER", "")
+	data, err := executor.ExecuteWithTimeout(ctx, timeout, func(taskCtx context.Context) ([]byte, error) {
+		return fetchConfig(taskCtx, url, authHeader)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+		return nil, fmt.Errorf("failed to fetch config from %s: %w", url, err)
+	}
+
+	config := defaultConfig()
+	if err := parseConfig(data, "from "+url, config); err != nil {
+		return nil, err
+	}
+
+	return layerConfig(config, prefix)
+}
+
+// parseConfig unmarshals the JSON configuration read from source, described for error messages
+// Only keys present in the document replace config's values, so an explicit zero or false overrides a default
+func parseConfig(data []byte, source string, config *Config) error {
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return fmt.Errorf("config %s is empty", source)
 	}
 
-	if err := json.Unmarshal(data, config); err != nil {
-		return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	if err := json.Unmarshal(expandEnvPlaceholders(data), config); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", source, err)
 	}
 
 	return nil
 }
 
-// loadFromEnvironment loads configuration from environment variables with defaults
-func loadFromEnvironment(config *Config) {
-	// Load server config
-	config.Server = ServerConfig{
-		Port:         getEnvAsInt("SERVER_PORT", 8080),
-		Host:         getEnv("SERVER_HOST", "localhost"),
-		ReadTimeout:  Duration{getEnvAsDuration("SERVER_READ_TIMEOUT", 30*time.Second)},
-		WriteTimeout: Duration{getEnvAsDuration("SERVER_WRITE_TIMEOUT", 30*time.Second)},
+// envPlaceholder matches ${VAR} placeholders in the config file; a bare $ is left alone
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnvPlaceholders replaces ${VAR} placeholders with the variable's value, or nothing if it's unset
+// Values are escaped for a JSON string, so quotes and backslashes in secrets don't break parsing
+func expandEnvPlaceholders(data []byte) []byte {
+	return envPlaceholder.ReplaceAllFunc(data, func(match []byte) []byte {
+		name := envPlaceholder.FindSubmatch(match)[1]
+		escaped, _ := json.Marshal(os.Getenv(string(name)))
+		return escaped[1 : len(escaped)-1]
+	})
+}
+
+// ConfigFormatJSON is the format LoadFromURL accepts for the served configuration
+const ConfigFormatJSON = "json"
+
+// configStdinPath is the CONFIG_PATH that reads the configuration from standard input
+const configStdinPath = "-"
+
+// configFetchTimeout is how long fetching the configuration from a URL may take unless CONFIG_URL_TIMEOUT is set
+const configFetchTimeout = 30 * time.Second
+
+// configStdin is where a CONFIG_PATH of "-" reads from, replaced in tests
+var configStdin io.Reader = os.Stdin
+
+// fetchConfig downloads the configuration from url, sending authHeader as the Authorization header if set
+func fetchConfig(ctx context.Context, url, authHeader string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
 	}
 
-	// Load DDNS config
-	config.DDNS = DDNSConfig{
-		Provider:       getEnv("DDNS_PROVIDER", "duckdns"),
-		Domain:         getEnv("DDNS_DOMAIN", ""),
-		APIKey:         getEnv("DDNS_API_KEY", ""),
-		UpdateInterval: Duration{getEnvAsDuration("DDNS_UPDATE_INTERVAL", 5*time.Minute)},
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %s", resp.Status)
 	}
 
+	return io.ReadAll(resp.Body)
+}
+
+// isConfigURL reports whether path names a configuration served over HTTP(S)
+func isConfigURL(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// configSourceName describes a CONFIG_PATH file or stdin in error messages
+func configSourceName(path string) string {
+	if path == configStdinPath {
+		return "from stdin"
+	}
+	return "file " + path
+}
+
+// defaultConfig returns the configuration used for every setting neither the file nor the environment sets
+func defaultConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            8080,
+			Host:            "localhost",
+			ReadTimeout:     Duration{30 * time.Second},
+			WriteTimeout:    Duration{30 * time.Second},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		DDNS: DDNSConfig{
+			Provider:           "duckdns",
+			RecordType:         "A",
+			TTL:                300,
+			UpdateInterval:     Duration{5 * time.Minute},
+			PropagationTimeout: Duration{2 * time.Minute},
+		},
+		HTTP: HTTPConfig{
+			Timeout:           Duration{30 * time.Second},
+			MaxRetries:        3,
+			RetryDelay:        Duration{1 * time.Second},
+			UserAgent:         "ddns-client/1.0",
+			IPDetectionMethod: "http",
+		},
+	}
+}
+
+// loadFromEnvironment applies the settings given by environment variables to config, leaving the others as they are
+// Only set, non-empty variables count, so HTTP_MAX_RETRIES=0 or DDNS_ALLOW_PRIVATE_IP=false override the file
+func loadFromEnvironment(config *Config, prefix string) {
+	// Load server config
+	setEnvInt(prefix, "SERVER_PORT", &config.Server.Port)
+	setEnvString(prefix, "SERVER_HOST", &config.Server.Host)
+	setEnvDuration(prefix, "SERVER_READ_TIMEOUT", &config.Server.ReadTimeout)
+	setEnvDuration(prefix, "SERVER_WRITE_TIMEOUT", &config.Server.WriteTimeout)
+	setEnvDuration(prefix, "SERVER_SHUTDOWN_TIMEOUT", &config.Server.ShutdownTimeout)
+	setEnvString(prefix, "SERVER_PID_FILE", &config.Server.PIDFile)
+
+	// Load DDNS config
+	setEnvString(prefix, "DDNS_PROVIDER", &config.DDNS.Provider)
+	setEnvString(prefix, "DDNS_DOMAIN", &config.DDNS.Domain)
+	setEnvString(prefix, "DDNS_USERNAME", &config.DDNS.Username)
+	setEnvString(prefix, "DDNS_API_KEY", &config.DDNS.APIKey)
+	setEnvString(prefix, "DDNS_API_KEY_FILE", &config.DDNS.APIKeyFile)
+	setEnvString(prefix, "DDNS_ZONE_ID", &config.DDNS.ZoneID)
+	setEnvString(prefix, "DDNS_RECORD_TYPE", &config.DDNS.RecordType)
+	setEnvInt(prefix, "DDNS_TTL", &config.DDNS.TTL)
+	setEnvString(prefix, "DDNS_TARGET", &config.DDNS.Target)
+	setEnvString(prefix, "DDNS_FILE_PATH", &config.DDNS.FilePath)
+	setEnvDuration(prefix, "DDNS_UPDATE_INTERVAL", &config.DDNS.UpdateInterval)
+	setEnvInt(prefix, "DDNS_UPDATE_JITTER_PERCENT", &config.DDNS.UpdateJitter)
+
+	setEnvString(prefix, "DDNS_FAILOVER_PROVIDER", &config.DDNS.FailoverProvider)
+	setEnvString(prefix, "DDNS_FAILOVER_API_KEY", &config.DDNS.FailoverAPIKey)
+	setEnvBool(prefix, "DDNS_ALLOW_PRIVATE_IP", &config.DDNS.AllowPrivateIP)
+	setEnvBool(prefix, "DDNS_FORCE_UPDATE", &config.DDNS.ForceUpdate)
+	setEnvBool(prefix, "DDNS_CONFIRM_IP_CHANGE", &config.DDNS.ConfirmIPChange)
+
+	setEnvBool(prefix, "DDNS_CLEAR_ON_SHUTDOWN", &config.DDNS.ClearOnShutdown)
+	setEnvDuration(prefix, "DDNS_RECORD_CACHE_TTL", &config.DDNS.RecordCacheTTL)
+	setEnvInt(prefix, "DDNS_MAX_CONSECUTIVE_FAILURES", &config.DDNS.MaxConsecutiveFailures)
+
+	setEnvBool(prefix, "DDNS_VERIFY_PROPAGATION", &config.DDNS.VerifyPropagation)
+	setEnvDuration(prefix, "DDNS_PROPAGATION_TIMEOUT", &config.DDNS.PropagationTimeout)
+	setEnvString(prefix, "DDNS_NAMESERVER", &config.DDNS.Nameserver)
+
 	// Load HTTP config
-	config.HTTP = HTTPConfig{
-		Timeout:    Duration{getEnvAsDuration("HTTP_TIMEOUT", 30*time.Second)},
-		MaxRetries: getEnvAsInt("HTTP_MAX_RETRIES", 3),
-		RetryDelay: Duration{getEnvAsDuration("HTTP_RETRY_DELAY", 1*time.Second)},
-		UserAgent:  getEnv("HTTP_USER_AGENT", "ddns-client/1.0"),
+	setEnvDuration(prefix, "HTTP_TIMEOUT", &config.HTTP.Timeout)
+	setEnvInt(prefix, "HTTP_MAX_RETRIES", &config.HTTP.MaxRetries)
+	setEnvDuration(prefix, "HTTP_RETRY_DELAY", &config.HTTP.RetryDelay)
+	setEnvString(prefix, "HTTP_USER_AGENT", &config.HTTP.UserAgent)
+	setEnvString(prefix, "HTTP_PROXY_URL", &config.HTTP.Proxy)
+
+	setEnvString(prefix, "HTTP_IP_DETECTION_METHOD", &config.HTTP.IPDetectionMethod)
+	setEnvString(prefix, "HTTP_IPV6_INTERFACE", &config.HTTP.IPv6Interface)
+	setEnvBool(prefix, "HTTP_SKIP_TEMPORARY_IPV6", &config.HTTP.SkipTemporaryIPv6)
+
+	// Headers from the environment are added to the file's, replacing those of the same name
+	for name, value := range envHeaders(prefix) {
+		if config.HTTP.Headers == nil {
+			config.HTTP.Headers = make(map[string]string)
+		}
+		config.HTTP.Headers[name] = value
 	}
 }
 
+// resolveSecrets replaces secrets with the contents of their *File counterparts, if set
+func (c *Config) resolveSecrets() error {
+	secrets := []struct {
+		value *string
+		file  string
+	}{
+		{&c.DDNS.APIKey, c.DDNS.APIKeyFile},
+	}
+	for i := range c.DDNS.Providers {
+		secrets = append(secrets, struct {
+			value *string
+			file  string
+		}{&c.DDNS.Providers[i].APIKey, c.DDNS.Providers[i].APIKeyFile})
+	}
+
+	for _, secret := range secrets {
+		if secret.file == "" {
+			continue
+		}
+
+		value, err := readSecretFile(secret.file)
+		if err != nil {
+			return err
+		}
+		*secret.value = value
+	}
+
+	return nil
+}
+
+// readSecretFile reads a secret from a file, trimming surrounding whitespace
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file %s: %w", path, err)
+	}
+
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return "", fmt.Errorf("secret file %s is empty", path)
+	}
+
+	return secret, nil
+}
+
 // getConfigPath returns the path to the configuration file
-func getConfigPath() string {
-	if configPath := os.Getenv("CONFIG_PATH"); configPath != "" {
+func getConfigPath(prefix string) string {
+	if configPath := getEnv(prefix, "CONFIG_PATH", ""); configPath != "" {
 		return configPath
 	}
 	return "config.json" // Default config file name
 }
 
-// Validate validates the configuration
+// ValidationError describes an invalid configuration field
+type ValidationError struct {
+	Field   string      // JSON path of the field, e.g. "ddns.domain"
+	Value   interface{} // The offending value
+	Message string
+}
+
+// Error formats the field path and message into a single message
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// Validate validates the configuration, reporting every invalid field together
 func (c *Config) Validate() error {
-	if c.DDNS.Domain == "" {
-		return fmt.Errorf("DDNS domain is required")
+	var errs []error
+	for _, err := range c.ValidateAll() {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAll checks every field and returns all validation errors, or nil if the configuration is valid
+func (c *Config) ValidateAll() []ValidationError {
+	var errs []ValidationError
+	add := func(field string, value interface{}, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Field: field, Value: value, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if len(c.DDNS.Records) > 0 {
+		if c.DDNS.Domain != "" {
+			add("ddns.records", len(c.DDNS.Records), "DDNS records cannot be combined with a domain")
+		}
+		for i, record := range c.DDNS.Records {
+			if strings.TrimSpace(record.Domain) == "" {
+				add(fmt.Sprintf("ddns.records[%d].domain", i), record.Domain, "DDNS record %d: domain is required", i)
+			}
+			if record.RecordType != "A" && record.RecordType != "AAAA" {
+				add(fmt.Sprintf("ddns.records[%d].record_type", i), record.RecordType, "DDNS record %d: record type must be A or AAAA, got %q", i, record.RecordType)
+			}
+			if record.TTL < 0 {
+				add(fmt.Sprintf("ddns.records[%d].ttl", i), record.TTL, "DDNS record %d: TTL cannot be negative, got %d", i, record.TTL)
+			}
+		}
+	} else if c.DDNS.Domain == "" {
+		add("ddns.domain", c.DDNS.Domain, "DDNS domain is required")
+	} else {
+		for i, domain := range c.DDNS.Domains() {
+			if domain == "" {
+				add("ddns.domain", c.DDNS.Domain, "DDNS domain %d is empty in %q", i, c.DDNS.Domain)
+			}
+		}
 	}
 
-	if c.DDNS.APIKey == "" {
-		return fmt.Errorf("DDNS API key is required")
+	for i, provider := range c.DDNS.Providers {
+		if provider.Provider == "" {
+			add(fmt.Sprintf("ddns.providers[%d].provider", i), provider.Provider, "DDNS provider %d: provider name is required", i)
+		}
+	}
+
+	if c.DDNS.FailoverProvider != "" {
+		if len(c.DDNS.Providers) > 0 {
+			add("ddns.failover_provider", c.DDNS.FailoverProvider, "DDNS failover provider cannot be combined with mirrored providers")
+		}
+		if c.DDNS.FailoverAPIKey == "" {
+			add("ddns.failover_api_key", c.DDNS.FailoverAPIKey, "DDNS failover provider (%s): API key is required", c.DDNS.FailoverProvider)
+		}
+	}
+
+	if c.DDNS.TTL < 0 {
+		add("ddns.ttl", c.DDNS.TTL, "DDNS TTL cannot be negative, got %d", c.DDNS.TTL)
+	}
+
+	if c.DDNS.UpdateJitter < 0 || c.DDNS.UpdateJitter >= 100 {
+		add("ddns.update_jitter_percent", c.DDNS.UpdateJitter, "DDNS update jitter must be between 0 and 99 percent, got %d", c.DDNS.UpdateJitter)
+	}
+
+	if c.DDNS.MaxConsecutiveFailures < 0 {
+		add("ddns.max_consecutive_failures", c.DDNS.MaxConsecutiveFailures, "DDNS max consecutive failures cannot be negative, got %d", c.DDNS.MaxConsecutiveFailures)
+	}
+
+	if c.DDNS.RecordCacheTTL.Duration < 0 {
+		add("ddns.record_cache_ttl", c.DDNS.RecordCacheTTL, "DDNS record cache TTL cannot be negative, got %s", c.DDNS.RecordCacheTTL.Duration)
+	}
+
+	if c.DDNS.PropagationTimeout.Duration < 0 {
+		add("ddns.propagation_timeout", c.DDNS.PropagationTimeout, "DDNS propagation timeout cannot be negative, got %s", c.DDNS.PropagationTimeout.Duration)
 	}
 
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
-		return fmt.Errorf("server port must be between 1 and 65535, got %d", c.Server.Port)
+		add("server.port", c.Server.Port, "server port must be between 1 and 65535, got %d", c.Server.Port)
 	}
 
 	if c.HTTP.MaxRetries < 0 {
-		return fmt.Errorf("HTTP max retries cannot be negative, got %d", c.HTTP.MaxRetries)
+		add("http.max_retries", c.HTTP.MaxRetries, "HTTP max retries cannot be negative, got %d", c.HTTP.MaxRetries)
 	}
 
-	return nil
+	switch c.HTTP.IPDetectionMethod {
+	case "", "http", "stun", "stun_with_http_fallback", "interface":
+	default:
+		add("http.ip_detection_method", c.HTTP.IPDetectionMethod, "unsupported IP detection method: %s", c.HTTP.IPDetectionMethod)
+	}
+
+	return errs
 }
 
 // Helper functions for environment variable parsing
 
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
+// envName returns the environment variable name for key with the prefix applied
+func envName(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+// lookupEnv returns the variable named key with the prefix applied, and whether it is set to a non-empty value
+func lookupEnv(prefix, key string) (string, bool) {
+	value, ok := os.LookupEnv(envName(prefix, key))
+	return value, ok && value != ""
+}
+
+func getEnv(prefix, key, fallback string) string {
+	if value, ok := lookupEnv(prefix, key); ok {
 		return value
 	}
 	return fallback
 }
 
-func getEnvAsInt(key string, fallback int) int {
-	if value := os.Getenv(key); value != "" {
+// setEnvString sets *dst to the variable's value if it is set
+func setEnvString(prefix, key string, dst *string) {
+	if value, ok := lookupEnv(prefix, key); ok {
+		*dst = value
+	}
+}
+
+// setEnvInt sets *dst to the variable's value if it is set to an integer
+func setEnvInt(prefix, key string, dst *int) {
+	if value, ok := lookupEnv(prefix, key); ok {
 		if intVal, err := strconv.Atoi(value); err == nil {
-			return intVal
+			*dst = intVal
 		}
 	}
-	return fallback
 }
 
-func getEnvAsDuration(key string, fallback time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
-		if duration, err := time.ParseDuration(value); err == nil {
-			return duration
+// setEnvDuration sets *dst to the variable's value if it is set to a duration
+func setEnvDuration(prefix, key string, dst *Duration) {
+	if value, ok := lookupEnv(prefix, key); ok {
+		if duration, err := parseDuration(value); err == nil {
+			dst.Duration = duration
 		}
 	}
-	return fallback
+}
+
+// setEnvBool sets *dst to the variable's value if it is set to a boolean
+func setEnvBool(prefix, key string, dst *bool) {
+	if value, ok := lookupEnv(prefix, key); ok {
+		if boolVal, err := strconv.ParseBool(value); err == nil {
+			*dst = boolVal
+		}
+	}
+}
+
+// envHeaders returns the headers given by HTTP_HEADERS and HTTP_EXTRA_HEADERS, the former winning conflicts
+func envHeaders(prefix string) map[string]string {
+	headers := getEnvAsMap(prefix, "HTTP_EXTRA_HEADERS")
+	for name, value := range getEnvAsMap(prefix, "HTTP_HEADERS") {
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[name] = value
+	}
+	return headers
+}
+
+// getEnvAsMap parses a comma-separated list of name=value (or name:value) pairs, skipping malformed entries
+// Each pair is split at its first = or :, whichever comes first, so values may contain the other
+func getEnvAsMap(prefix, key string) map[string]string {
+	value := os.Getenv(envName(prefix, key))
+	if value == "" {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		sep := strings.IndexAny(pair, "=:")
+		if sep < 0 {
+			continue
+		}
+		name := strings.TrimSpace(pair[:sep])
+		if name == "" {
+			continue
+		}
+		result[name] = strings.TrimSpace(pair[sep+1:])
+	}
+	return result
 }
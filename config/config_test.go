@@ -1,7 +1,17 @@
 package config
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,11 +48,14 @@ func TestLoad(t *testing.T) {
 			wantErr: true,
 		},
 		{
-			name: "missing required API key",
+			// Whether a provider needs an API key is checked by the provider factory
+			name: "file provider without API key",
 			envVars: map[string]string{
-				"DDNS_DOMAIN": "example.com",
+				"DDNS_PROVIDER":  "file",
+				"DDNS_DOMAIN":    "example.com",
+				"DDNS_FILE_PATH": "/etc/hosts",
 			},
-			wantErr: true,
+			wantErr: false,
 		},
 		{
 			name: "custom values from environment",
@@ -51,8 +64,16 @@ func TestLoad(t *testing.T) {
 				"DDNS_API_KEY":         "custom-key",
 				"DDNS_PROVIDER":        "route53",
 				"DDNS_UPDATE_INTERVAL": "10m",
+				"DDNS_TTL":             "600",
 				"SERVER_PORT":          "9090",
 				"HTTP_MAX_RETRIES":     "5",
+				"HTTP_HEADERS":         "X-Api-Key=abc, X-Env = prod,malformed",
+				"HTTP_EXTRA_HEADERS":   "X-App:ddns,X-Env:staging,Authorization:Basic dXNlcjpwYXNz==",
+				"HTTP_PROXY_URL":       "socks5://proxy.internal:1080",
+
+				"HTTP_IP_DETECTION_METHOD": "interface",
+				"HTTP_SKIP_TEMPORARY_IPV6": "true",
+				"SERVER_SHUTDOWN_TIMEOUT":  "45s",
 			},
 			wantErr: false,
 			validate: func(c *Config) error {
@@ -62,12 +83,30 @@ func TestLoad(t *testing.T) {
 				if c.DDNS.UpdateInterval.Duration != 10*time.Minute {
 					t.Errorf("expected update interval 10m, got %s", c.DDNS.UpdateInterval.Duration)
 				}
+				if c.DDNS.TTL != 600 {
+					t.Errorf("expected TTL 600, got %d", c.DDNS.TTL)
+				}
 				if c.Server.Port != 9090 {
 					t.Errorf("expected port 9090, got %d", c.Server.Port)
 				}
 				if c.HTTP.MaxRetries != 5 {
 					t.Errorf("expected max retries 5, got %d", c.HTTP.MaxRetries)
 				}
+				if c.Server.ShutdownTimeout.Duration != 45*time.Second {
+					t.Errorf("expected shutdown timeout 45s, got %s", c.Server.ShutdownTimeout.Duration)
+				}
+				if len(c.HTTP.Headers) != 4 || c.HTTP.Headers["X-Api-Key"] != "abc" || c.HTTP.Headers["X-Env"] != "prod" || c.HTTP.Headers["X-App"] != "ddns" {
+					t.Errorf("expected headers X-Api-Key, X-Env, X-App and Authorization, got %v", c.HTTP.Headers)
+				}
+				if c.HTTP.Headers["Authorization"] != "Basic dXNlcjpwYXNz==" {
+					t.Errorf("expected a Name:value pair to keep the = in its value, got %v", c.HTTP.Headers)
+				}
+				if c.HTTP.Proxy != "socks5://proxy.internal:1080" {
+					t.Errorf("expected SOCKS5 proxy, got %q", c.HTTP.Proxy)
+				}
+				if c.HTTP.IPDetectionMethod != "interface" || !c.HTTP.SkipTemporaryIPv6 {
+					t.Errorf("expected interface detection skipping temporary addresses, got %q and %v", c.HTTP.IPDetectionMethod, c.HTTP.SkipTemporaryIPv6)
+				}
 				return nil
 			},
 		},
@@ -103,6 +142,124 @@ func TestLoad(t *testing.T) {
 	}
 }
 
+func TestLoadAPIKeyFromFile(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "api_key")
+	if err := os.WriteFile(secretPath, []byte("  file-api-key\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		envVars map[string]string
+		wantKey string
+		wantErr bool
+	}{
+		{
+			name: "key file only",
+			envVars: map[string]string{
+				"DDNS_API_KEY_FILE": secretPath,
+			},
+			wantKey: "file-api-key",
+		},
+		{
+			name: "key file takes precedence over explicit key",
+			envVars: map[string]string{
+				"DDNS_API_KEY":      "env-api-key",
+				"DDNS_API_KEY_FILE": secretPath,
+			},
+			wantKey: "file-api-key",
+		},
+		{
+			name: "explicit key without key file",
+			envVars: map[string]string{
+				"DDNS_API_KEY": "env-api-key",
+			},
+			wantKey: "env-api-key",
+		},
+		{
+			name: "missing key file",
+			envVars: map[string]string{
+				"DDNS_API_KEY":      "env-api-key",
+				"DDNS_API_KEY_FILE": filepath.Join(t.TempDir(), "missing"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv()
+			defer clearEnv()
+
+			os.Setenv("CONFIG_PATH", "non-existent-config.json")
+			os.Setenv("DDNS_DOMAIN", "example.com")
+			defer os.Unsetenv("DDNS_DOMAIN")
+			for key, value := range tt.envVars {
+				os.Setenv(key, value)
+			}
+
+			config, err := Load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && config.DDNS.APIKey != tt.wantKey {
+				t.Errorf("expected API key '%s', got '%s'", tt.wantKey, config.DDNS.APIKey)
+			}
+		})
+	}
+}
+
+func TestLoadWithPrefix(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("CONFIG_PATH", "non-existent-config.json")
+	os.Setenv("DDNS_DOMAIN", "unprefixed.com")
+	os.Setenv("DDNS_API_KEY", "unprefixed-key")
+	os.Setenv("MYAPP_DDNS_DOMAIN", "prefixed.com")
+	os.Setenv("MYAPP_DDNS_API_KEY", "prefixed-key")
+	os.Setenv("MYAPP_SERVER_PORT", "9090")
+	defer func() {
+		for _, key := range []string{"MYAPP_DDNS_DOMAIN", "MYAPP_DDNS_API_KEY", "MYAPP_SERVER_PORT"} {
+			os.Unsetenv(key)
+		}
+	}()
+
+	// Empty prefix reads the plain variable names
+	config, err := LoadWithPrefix("")
+	if err != nil {
+		t.Fatalf("LoadWithPrefix(\"\") error = %v", err)
+	}
+	if config.DDNS.Domain != "unprefixed.com" {
+		t.Errorf("expected domain 'unprefixed.com', got '%s'", config.DDNS.Domain)
+	}
+
+	config, err = LoadWithPrefix("MYAPP")
+	if err != nil {
+		t.Fatalf("LoadWithPrefix(\"MYAPP\") error = %v", err)
+	}
+	if config.DDNS.Domain != "prefixed.com" {
+		t.Errorf("expected domain 'prefixed.com', got '%s'", config.DDNS.Domain)
+	}
+	if config.DDNS.APIKey != "prefixed-key" {
+		t.Errorf("expected API key 'prefixed-key', got '%s'", config.DDNS.APIKey)
+	}
+	if config.Server.Port != 9090 {
+		t.Errorf("expected port 9090, got %d", config.Server.Port)
+	}
+
+	// CONFIG_ENV_PREFIX selects the prefix for Load
+	os.Setenv("CONFIG_ENV_PREFIX", "MYAPP")
+	config, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if config.DDNS.Domain != "prefixed.com" {
+		t.Errorf("expected Load to honor CONFIG_ENV_PREFIX, got domain '%s'", config.DDNS.Domain)
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -172,6 +329,127 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "provider blocks without top-level API key",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain: "example.com",
+					Providers: []ProviderConfig{
+						{Provider: "duckdns", APIKey: "duck-token"},
+						{Provider: "linode", APIKey: "linode-token"},
+					},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			// Providers without credentials, such as file, are left to the provider factory
+			name: "provider block without API key",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain: "example.com",
+					Providers: []ProviderConfig{
+						{Provider: "file", FilePath: "/etc/hosts"},
+						{Provider: "mock"},
+					},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "failover provider missing API key",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain:           "example.com",
+					APIKey:           "test-key",
+					FailoverProvider: "linode",
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "update jitter of 100 percent",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain:       "example.com",
+					APIKey:       "test-key",
+					UpdateJitter: 100,
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max consecutive failures",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain:                 "example.com",
+					APIKey:                 "test-key",
+					MaxConsecutiveFailures: -1,
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative propagation timeout",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain:             "example.com",
+					APIKey:             "test-key",
+					VerifyPropagation:  true,
+					PropagationTimeout: Duration{-time.Second},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "provider block missing provider name",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain: "example.com",
+					Providers: []ProviderConfig{
+						{APIKey: "duck-token"},
+					},
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported IP detection method",
+			config: &Config{
+				DDNS: DDNSConfig{
+					Domain: "example.com",
+					APIKey: "test-key",
+				},
+				Server: ServerConfig{
+					Port: 8080,
+				},
+				HTTP: HTTPConfig{
+					IPDetectionMethod: "carrier-pigeon",
+				},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,16 +462,535 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestHTTPConfigRetryStrategy(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      HTTPConfig
+		maxAttempts int
+		firstDelay  time.Duration
+	}{
+		{
+			name:        "configured retries and delay",
+			config:      HTTPConfig{MaxRetries: 5, RetryDelay: Duration{2 * time.Second}},
+			maxAttempts: 5,
+			firstDelay:  2 * time.Second,
+		},
+		{
+			name:        "zero retries still makes one attempt",
+			config:      HTTPConfig{MaxRetries: 0},
+			maxAttempts: 1,
+			firstDelay:  time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := tt.config.RetryStrategy()
+			if strategy.GetMaxAttempts() != tt.maxAttempts {
+				t.Errorf("expected %d max attempts, got %d", tt.maxAttempts, strategy.GetMaxAttempts())
+			}
+			if strategy.GetDelay(1) != tt.firstDelay {
+				t.Errorf("expected first delay %s, got %s", tt.firstDelay, strategy.GetDelay(1))
+			}
+		})
+	}
+}
+
+func TestDurationUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "integer seconds", input: `300`, want: 5 * time.Minute},
+		{name: "whole float seconds", input: `60.0`, want: time.Minute},
+		{name: "fractional float seconds", input: `1.5`, wantErr: true},
+		{name: "string with units", input: `"5m"`, want: 5 * time.Minute},
+		{name: "string of integer seconds", input: `"90"`, want: 90 * time.Second},
+		{name: "invalid string", input: `"five minutes"`, wantErr: true},
+		{name: "boolean", input: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d Duration
+			err := json.Unmarshal([]byte(tt.input), &d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UnmarshalJSON(%s) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && d.Duration != tt.want {
+				t.Errorf("UnmarshalJSON(%s) = %s, want %s", tt.input, d.Duration, tt.want)
+			}
+		})
+	}
+}
+
+func TestDurationMarshalJSON(t *testing.T) {
+	var d Duration
+	if err := json.Unmarshal([]byte(`300`), &d); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if string(data) != `"5m0s"` {
+		t.Errorf("expected human-readable duration, got %s", data)
+	}
+}
+
+func TestDurationUnmarshalYAMLAndText(t *testing.T) {
+	var fromYAML Duration
+	err := fromYAML.UnmarshalYAML(func(v interface{}) error {
+		*(v.(*interface{})) = 120
+		return nil
+	})
+	if err != nil || fromYAML.Duration != 2*time.Minute {
+		t.Errorf("UnmarshalYAML(120) = %s, %v, want 2m", fromYAML.Duration, err)
+	}
+
+	var fromText Duration
+	if err := fromText.UnmarshalText([]byte("45s")); err != nil || fromText.Duration != 45*time.Second {
+		t.Errorf("UnmarshalText(45s) = %s, %v, want 45s", fromText.Duration, err)
+	}
+
+	if err := fromText.UnmarshalText([]byte("soon")); err == nil {
+		t.Error("expected error for invalid text duration")
+	}
+}
+
+func TestConfigClone(t *testing.T) {
+	original := Config{
+		DDNS: DDNSConfig{
+			Domain: "example.com",
+			APIKey: "test-key",
+			Providers: []ProviderConfig{
+				{Provider: "duckdns", APIKey: "duck-token", Headers: map[string]string{"X-Api-Key": "abc"}},
+			},
+		},
+		Server: ServerConfig{Port: 8080},
+	}
+
+	clone := original.Clone()
+	clone.DDNS.Domain = "other.example.com"
+	clone.DDNS.Providers[0].APIKey = "changed"
+	clone.DDNS.Providers[0].Headers["X-Api-Key"] = "changed"
+	clone.DDNS.Providers = append(clone.DDNS.Providers, ProviderConfig{Provider: "linode"})
+	clone.Server.Port = 9090
+
+	if original.DDNS.Domain != "example.com" {
+		t.Errorf("Expected original domain unchanged, got %s", original.DDNS.Domain)
+	}
+	if original.DDNS.Providers[0].APIKey != "duck-token" {
+		t.Errorf("Expected original provider API key unchanged, got %s", original.DDNS.Providers[0].APIKey)
+	}
+	if original.DDNS.Providers[0].Headers["X-Api-Key"] != "abc" {
+		t.Errorf("Expected original provider headers unchanged, got %v", original.DDNS.Providers[0].Headers)
+	}
+	if len(original.DDNS.Providers) != 1 {
+		t.Errorf("Expected original to keep 1 provider, got %d", len(original.DDNS.Providers))
+	}
+	if original.Server.Port != 8080 {
+		t.Errorf("Expected original port unchanged, got %d", original.Server.Port)
+	}
+
+	if (Config{}).Clone().DDNS.Providers != nil {
+		t.Error("Expected nil providers to stay nil")
+	}
+}
+
+func TestDDNSConfigDomains(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("DDNS_DOMAIN", " home.example.com, vpn.example.com ,nas.example.com")
+	os.Setenv("DDNS_API_KEY", "test-key")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{"home.example.com", "vpn.example.com", "nas.example.com"}
+	if got := cfg.DDNS.Domains(); !slices.Equal(got, want) {
+		t.Errorf("Expected domains %v, got %v", want, got)
+	}
+
+	if got := (DDNSConfig{Domain: "example.com"}).Domains(); !slices.Equal(got, []string{"example.com"}) {
+		t.Errorf("Expected single domain, got %v", got)
+	}
+
+	invalid := Config{
+		DDNS:   DDNSConfig{Domain: "home.example.com,,vpn.example.com", APIKey: "test-key"},
+		Server: ServerConfig{Port: 8080},
+	}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for an empty domain in the list")
+	}
+}
+
 // Helper function to clear environment variables
 func clearEnv() {
 	envVars := []string{
-		"SERVER_PORT", "SERVER_HOST", "SERVER_READ_TIMEOUT", "SERVER_WRITE_TIMEOUT",
-		"DDNS_PROVIDER", "DDNS_DOMAIN", "DDNS_API_KEY", "DDNS_UPDATE_INTERVAL",
-		"HTTP_TIMEOUT", "HTTP_MAX_RETRIES", "HTTP_RETRY_DELAY", "HTTP_USER_AGENT",
-		"CONFIG_PATH",
+		"SERVER_PORT", "SERVER_HOST", "SERVER_READ_TIMEOUT", "SERVER_WRITE_TIMEOUT", "SERVER_SHUTDOWN_TIMEOUT", "SERVER_PID_FILE",
+		"DDNS_PROVIDER", "DDNS_DOMAIN", "DDNS_USERNAME", "DDNS_API_KEY", "DDNS_API_KEY_FILE", "DDNS_ZONE_ID", "DDNS_RECORD_TYPE", "DDNS_TTL", "DDNS_TARGET", "DDNS_FILE_PATH", "DDNS_UPDATE_INTERVAL", "DDNS_UPDATE_JITTER_PERCENT", "DDNS_FAILOVER_PROVIDER", "DDNS_FAILOVER_API_KEY", "DDNS_ALLOW_PRIVATE_IP", "DDNS_FORCE_UPDATE", "DDNS_CONFIRM_IP_CHANGE", "DDNS_RECORD_CACHE_TTL", "DDNS_CLEAR_ON_SHUTDOWN", "DDNS_MAX_CONSECUTIVE_FAILURES", "DDNS_VERIFY_PROPAGATION", "DDNS_PROPAGATION_TIMEOUT", "DDNS_NAMESERVER",
+		"HTTP_TIMEOUT", "HTTP_MAX_RETRIES", "HTTP_RETRY_DELAY", "HTTP_USER_AGENT", "HTTP_HEADERS", "HTTP_EXTRA_HEADERS", "HTTP_PROXY_URL", "HTTP_IP_DETECTION_METHOD", "HTTP_IPV6_INTERFACE", "HTTP_SKIP_TEMPORARY_IPV6",
+		"CONFIG_PATH", "CONFIG_ENV_PREFIX", "CONFIG_URL_AUTH_HEADER", "CONFIG_URL_TIMEOUT",
 	}
 
 	for _, env := range envVars {
 		os.Unsetenv(env)
 	}
 }
+
+func TestConfigValidateAll(t *testing.T) {
+	cfg := &Config{
+		DDNS: DDNSConfig{
+			Providers: []ProviderConfig{
+				{Provider: ""},
+			},
+		},
+		Server: ServerConfig{Port: 70000},
+	}
+
+	errs := cfg.ValidateAll()
+
+	var fields []string
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{"ddns.domain", "ddns.providers[0].provider", "server.port"}
+	if !slices.Equal(fields, want) {
+		t.Fatalf("Expected errors for %v, got %v", want, fields)
+	}
+	if errs[2].Value != 70000 {
+		t.Errorf("Expected offending port value, got %v", errs[2].Value)
+	}
+
+	// Validate joins every error so none is hidden behind the first
+	err := cfg.Validate()
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "ddns.domain" {
+		t.Errorf("Expected the joined error to expose ValidationErrors, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "server.port: server port must be between 1 and 65535") {
+		t.Errorf("Expected every error in the message, got %q", err.Error())
+	}
+
+	valid := &Config{DDNS: DDNSConfig{Domain: "example.com", APIKey: "key"}, Server: ServerConfig{Port: 8080}}
+	if errs := valid.ValidateAll(); errs != nil {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+}
+
+func TestConfigRecords(t *testing.T) {
+	data := `{"ddns": {"api_key": "test-key", "records": [
+		{"domain": "home.example.com", "record_type": "A"},
+		{"domain": "ipv6.example.com", "record_type": "AAAA", "ttl": 60}
+	]}}`
+	var cfg Config
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	cfg.Server.Port = 8080
+
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Expected valid config, got %v", err)
+	}
+	if got := cfg.DDNS.Domains(); !slices.Equal(got, []string{"home.example.com", "ipv6.example.com"}) {
+		t.Errorf("Expected record domains, got %v", got)
+	}
+	if cfg.DDNS.Records[1].TTL != 60 {
+		t.Errorf("Expected TTL 60, got %d", cfg.DDNS.Records[1].TTL)
+	}
+
+	// Each entry is validated on its own
+	cfg.DDNS.Records = append(cfg.DDNS.Records, RecordConfig{RecordType: "TXT", TTL: -1})
+	var fields []string
+	for _, err := range cfg.ValidateAll() {
+		fields = append(fields, err.Field)
+	}
+	want := []string{"ddns.records[2].domain", "ddns.records[2].record_type", "ddns.records[2].ttl"}
+	if !slices.Equal(fields, want) {
+		t.Errorf("Expected errors for %v, got %v", want, fields)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &Config{
+		Server: ServerConfig{Port: 8080, Host: "localhost", ReadTimeout: Duration{30 * time.Second}},
+		DDNS: DDNSConfig{
+			Provider:       "duckdns",
+			Domain:         "base.example.com",
+			UpdateInterval: Duration{5 * time.Minute},
+			Records:        []RecordConfig{{Domain: "a.example.com", RecordType: "A"}},
+			Providers:      []ProviderConfig{{Provider: "linode", APIKey: "linode-token"}},
+		},
+		HTTP: HTTPConfig{MaxRetries: 3, Headers: map[string]string{"X-Base": "1", "X-Shared": "base"}},
+	}
+	override := &Config{
+		Server: ServerConfig{Port: 9090},
+		DDNS: DDNSConfig{
+			Domain:         "override.example.com",
+			UpdateInterval: Duration{time.Minute},
+			AllowPrivateIP: true,
+			Records:        []RecordConfig{{Domain: "b.example.com", RecordType: "AAAA"}},
+			Providers:      []ProviderConfig{{Provider: "vultr", APIKey: "vultr-key"}},
+		},
+		HTTP: HTTPConfig{Headers: map[string]string{"X-Shared": "override"}},
+	}
+
+	merged := Merge(base, override)
+
+	tests := []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"string overridden", merged.DDNS.Domain, "override.example.com"},
+		{"string kept when zero", merged.DDNS.Provider, "duckdns"},
+		{"int overridden", merged.Server.Port, 9090},
+		{"int kept when zero", merged.HTTP.MaxRetries, 3},
+		{"bool overridden", merged.DDNS.AllowPrivateIP, true},
+		{"Duration overridden", merged.DDNS.UpdateInterval.Duration, time.Minute},
+		{"Duration kept when zero", merged.Server.ReadTimeout.Duration, 30 * time.Second},
+		{"nested struct merged field by field", merged.Server.Host, "localhost"},
+		{"slice replaced", fmt.Sprint(merged.DDNS.Records), fmt.Sprint([]RecordConfig{{Domain: "b.example.com", RecordType: "AAAA"}})},
+		{"tagged slice appended", len(merged.DDNS.Providers), 2},
+		{"map key kept", merged.HTTP.Headers["X-Base"], "1"},
+		{"map key overridden", merged.HTTP.Headers["X-Shared"], "override"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Neither input is modified
+	if base.DDNS.Domain != "base.example.com" || len(base.DDNS.Providers) != 1 || base.HTTP.Headers["X-Shared"] != "base" {
+		t.Errorf("Expected base to be unchanged, got %+v", base)
+	}
+	merged.DDNS.Providers[1].APIKey = "changed"
+	if override.DDNS.Providers[0].APIKey != "vultr-key" {
+		t.Error("Expected merged providers not to share override's backing array")
+	}
+
+	if got := Merge(nil, override); got.DDNS.Domain != "override.example.com" {
+		t.Errorf("Expected nil base to yield override, got %+v", got)
+	}
+	if got := Merge(base, nil); got.DDNS.Domain != "base.example.com" {
+		t.Errorf("Expected nil override to yield base, got %+v", got)
+	}
+}
+
+func TestLoadLayersFileAndEnvironment(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data := `{"ddns": {"domain": "file.example.com", "api_key": "file-key", "update_interval": "10m"}, "server": {"port": 9000}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	os.Setenv("CONFIG_PATH", configPath)
+	os.Setenv("DDNS_DOMAIN", "env.example.com")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.DDNS.Domain != "env.example.com" {
+		t.Errorf("expected the environment to override the file, got domain '%s'", config.DDNS.Domain)
+	}
+	if config.DDNS.APIKey != "file-key" || config.DDNS.UpdateInterval.Duration != 10*time.Minute || config.Server.Port != 9000 {
+		t.Errorf("expected file values where the environment is unset, got %+v", config)
+	}
+	if config.DDNS.Provider != "duckdns" || config.HTTP.MaxRetries != 3 {
+		t.Errorf("expected defaults where neither the file nor the environment sets a value, got %+v", config)
+	}
+}
+
+func TestLoadKeepsExplicitZeroValues(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data := `{"ddns": {"domain": "file.example.com", "api_key": "file-key", "allow_private_ip": true}, "http": {"max_retries": 0}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	os.Setenv("CONFIG_PATH", configPath)
+	os.Setenv("DDNS_ALLOW_PRIVATE_IP", "false")
+	os.Setenv("DDNS_TTL", "0")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.HTTP.MaxRetries != 0 {
+		t.Errorf("expected max_retries 0 from the file to replace the default, got %d", config.HTTP.MaxRetries)
+	}
+	if config.DDNS.AllowPrivateIP {
+		t.Error("expected DDNS_ALLOW_PRIVATE_IP=false to override the file")
+	}
+	if config.DDNS.TTL != 0 {
+		t.Errorf("expected DDNS_TTL=0 to replace the default, got %d", config.DDNS.TTL)
+	}
+	if config.HTTP.Timeout.Duration != 30*time.Second {
+		t.Errorf("expected the default timeout where nothing sets one, got %s", config.HTTP.Timeout.Duration)
+	}
+}
+
+func TestLoadConfigFromURL(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config.json":
+			w.Write([]byte(`{"ddns": {"domain": "remote.example.com", "api_key": "remote-key"}}`))
+		case "/empty.json":
+			w.Write([]byte("  \n"))
+		case "/malformed.json":
+			w.Write([]byte(`{"ddns": `))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	os.Setenv("CONFIG_PATH", server.URL+"/config.json")
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if config.DDNS.Domain != "remote.example.com" || config.DDNS.APIKey != "remote-key" {
+		t.Errorf("expected the fetched config, got %+v", config.DDNS)
+	}
+
+	tests := map[string]string{
+		"/empty.json":     "is empty",
+		"/malformed.json": "failed to parse config",
+		"/missing.json":   "404",
+	}
+	for path, wantErr := range tests {
+		os.Setenv("CONFIG_PATH", server.URL+path)
+		os.Setenv("DDNS_DOMAIN", "env.example.com")
+		os.Setenv("DDNS_API_KEY", "env-key")
+
+		if _, err := Load(); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Errorf("%s: expected error containing %q, got %v", path, wantErr, err)
+		}
+	}
+}
+
+func TestLoadConfigFromStdin(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+	defer func(stdin io.Reader) { configStdin = stdin }(configStdin)
+
+	os.Setenv("CONFIG_PATH", "-")
+
+	configStdin = strings.NewReader(`{"ddns": {"domain": "piped.example.com", "api_key": "piped-key"}}`)
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if config.DDNS.Domain != "piped.example.com" {
+		t.Errorf("expected the piped config, got domain '%s'", config.DDNS.Domain)
+	}
+
+	configStdin = strings.NewReader("")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "config from stdin is empty") {
+		t.Errorf("expected an error for empty stdin, got %v", err)
+	}
+
+	configStdin = strings.NewReader("not json")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "failed to parse config from stdin") {
+		t.Errorf("expected an error for malformed stdin, got %v", err)
+	}
+}
+
+func TestLoadExpandsEnvPlaceholders(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	data := `{"ddns": {"domain": "home.example.com", "api_key": "${DDNS_TOKEN}", "username": "user$1${UNSET_PLACEHOLDER}"}, "server": {"port": ${DDNS_TEST_PORT}}}`
+	if err := os.WriteFile(configPath, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	os.Setenv("CONFIG_PATH", configPath)
+	t.Setenv("DDNS_TOKEN", `s3cr"et\`)
+	t.Setenv("DDNS_TEST_PORT", "9100")
+
+	config, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if config.DDNS.APIKey != `s3cr"et\` {
+		t.Errorf("expected the token from the environment, got %q", config.DDNS.APIKey)
+	}
+	if config.DDNS.Username != "user$1" {
+		t.Errorf("expected bare $ kept and unset placeholders emptied, got %q", config.DDNS.Username)
+	}
+	if config.Server.Port != 9100 {
+		t.Errorf("expected port 9100, got %d", config.Server.Port)
+	}
+}
+
+func TestLoadFromURL(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if r.URL.Path == "/slow.json" {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"ddns": {"domain": "vault.example.com", "api_key": "vault-key"}, "server": {"port": 9200}}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("CONFIG_URL_AUTH_HEADER", "Bearer config-token")
+	os.Setenv("SERVER_PORT", "9300")
+
+	config, err := LoadFromURL(context.Background(), server.URL+"/config.json", ConfigFormatJSON)
+	if err != nil {
+		t.Fatalf("LoadFromURL() error = %v", err)
+	}
+	if auth != "Bearer config-token" {
+		t.Errorf("expected the configured Authorization header, got %q", auth)
+	}
+	if config.DDNS.Domain != "vault.example.com" || config.DDNS.Provider != "duckdns" {
+		t.Errorf("expected the fetched config over the defaults, got %+v", config.DDNS)
+	}
+	if config.Server.Port != 9300 {
+		t.Errorf("expected the environment to override the fetched config, got port %d", config.Server.Port)
+	}
+
+	if _, err := LoadFromURL(context.Background(), server.URL+"/config.json", "yaml"); err == nil || !strings.Contains(err.Error(), "unsupported config format") {
+		t.Errorf("expected an unsupported format error, got %v", err)
+	}
+
+	os.Setenv("CONFIG_URL_TIMEOUT", "50ms")
+	start := time.Now()
+	if _, err := LoadFromURL(context.Background(), server.URL+"/slow.json", ""); err == nil || !strings.Contains(err.Error(), "failed to fetch config") {
+		t.Errorf("expected a fetch timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected CONFIG_URL_TIMEOUT to bound the fetch, took %s", elapsed)
+	}
+
+	os.Setenv("CONFIG_URL_TIMEOUT", "soon")
+	if _, err := LoadFromURL(context.Background(), server.URL+"/config.json", ""); err == nil || !strings.Contains(err.Error(), "CONFIG_URL_TIMEOUT") {
+		t.Errorf("expected an invalid timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultWatchDebounce is how long a Watcher waits for further changes before reloading
+const DefaultWatchDebounce = 200 * time.Millisecond
+
+// defaultWatchPollInterval is how often a Watcher checks the file for changes
+const defaultWatchPollInterval = time.Second
+
+// Watcher reloads a config file when it changes and swaps in the new configuration once it validates
+// Editors often save in several steps (truncate, write, chmod), so a reload only happens once the file
+// has been quiet for the debounce period plus a poll interval, so at least one more poll has seen it unchanged
+type Watcher struct {
+	path         string
+	debounce     time.Duration
+	pollInterval time.Duration
+	onChange     func(*Config)
+	onError      func(error)
+
+	current atomic.Pointer[Config]
+
+	mu    sync.Mutex
+	timer *time.Timer
+
+	reloadMu sync.Mutex // Serializes reloads, which run on timer goroutines
+}
+
+// WatcherOption configures a Watcher
+type WatcherOption func(*Watcher)
+
+// WithDebounce sets how long to wait after a change for further changes, DefaultWatchDebounce by default
+func WithDebounce(debounce time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.debounce = debounce
+	}
+}
+
+// WithPollInterval sets how often the file is checked for changes
+func WithPollInterval(interval time.Duration) WatcherOption {
+	return func(w *Watcher) {
+		w.pollInterval = interval
+	}
+}
+
+// WithErrorCallback sets a function called when a reload fails; the previous configuration stays active
+func WithErrorCallback(onError func(error)) WatcherOption {
+	return func(w *Watcher) {
+		w.onError = onError
+	}
+}
+
+// NewWatcher loads the config file at path and returns a watcher calling onChange with each valid reload
+func NewWatcher(path string, onChange func(*Config), options ...WatcherOption) (*Watcher, error) {
+	w := &Watcher{
+		path:         path,
+		debounce:     DefaultWatchDebounce,
+		pollInterval: defaultWatchPollInterval,
+		onChange:     onChange,
+	}
+	for _, option := range options {
+		option(w)
+	}
+
+	config, err := LoadFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(config)
+
+	return w, nil
+}
+
+// Current returns the configuration from the last successful load
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Run checks the file for changes every poll interval until ctx is done
+// The standard library has no file notification API, so changes are detected from the file's
+// modification time, size and mode; each difference counts as one event
+func (w *Watcher) Run(ctx context.Context) error {
+	last, err := w.stat()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+	defer w.stopTimer()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := w.stat()
+		if err != nil {
+			// The file may be briefly missing while an editor replaces it
+			continue
+		}
+		if info != last {
+			last = info
+			w.event()
+		}
+	}
+}
+
+// fileState is the part of a file's metadata that changes when it's written or its mode changes
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// stat returns the current state of the watched file
+func (w *Watcher) stat() (fileState, error) {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return fileState{}, fmt.Errorf("failed to stat config %s: %w", w.path, err)
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), mode: info.Mode()}, nil
+}
+
+// event records a change to the file, pushing the pending reload back by the quiet period
+func (w *Watcher) event() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer == nil {
+		w.timer = time.AfterFunc(w.quietPeriod(), w.reload)
+		return
+	}
+	w.timer.Reset(w.quietPeriod())
+}
+
+// quietPeriod is how long the file must stay unchanged before a reload
+// Changes are only seen on polls, so a debounce shorter than the poll interval alone would
+// reload before the next step of a save could be noticed
+func (w *Watcher) quietPeriod() time.Duration {
+	return w.debounce + w.pollInterval
+}
+
+// stopTimer cancels a pending reload
+func (w *Watcher) stopTimer() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+}
+
+// reload loads and validates the file, swapping it in and calling onChange only if both succeed
+func (w *Watcher) reload() {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	config, err := LoadFromFile(w.path)
+	if err == nil {
+		err = config.Validate()
+	}
+	if err != nil {
+		if w.onError != nil {
+			w.onError(fmt.Errorf("failed to reload config %s: %w", w.path, err))
+		}
+		return
+	}
+
+	w.current.Store(config)
+	if w.onChange != nil {
+		w.onChange(config)
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes data to the config file, failing the test on error
+func writeConfigFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+}
+
+func TestWatcherDebouncesMultiEventWrites(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "old.example.com", "api_key": "key"}}`)
+
+	var changes, failures atomic.Int32
+	changed := make(chan *Config, 10)
+	watcher, err := NewWatcher(configPath,
+		func(c *Config) {
+			changes.Add(1)
+			changed <- c
+		},
+		WithDebounce(100*time.Millisecond),
+		WithPollInterval(5*time.Millisecond),
+		WithErrorCallback(func(error) { failures.Add(1) }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	if watcher.Current().DDNS.Domain != "old.example.com" {
+		t.Fatalf("expected the initial config to be loaded, got domain %q", watcher.Current().DDNS.Domain)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+	time.Sleep(20 * time.Millisecond)
+
+	// An editor save: truncate, write in two parts, then chmod, each seen as a separate event
+	writeConfigFile(t, configPath, "")
+	time.Sleep(20 * time.Millisecond)
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "new.exa`)
+	time.Sleep(20 * time.Millisecond)
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "new.example.com", "api_key": "key"}}`)
+	time.Sleep(20 * time.Millisecond)
+	if err := os.Chmod(configPath, 0644); err != nil {
+		t.Fatalf("failed to chmod config file: %v", err)
+	}
+
+	select {
+	case c := <-changed:
+		if c.DDNS.Domain != "new.example.com" {
+			t.Errorf("expected the reloaded domain new.example.com, got %q", c.DDNS.Domain)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if n := changes.Load(); n != 1 {
+		t.Errorf("expected a single reload for the whole save, got %d", n)
+	}
+	if n := failures.Load(); n != 0 {
+		t.Errorf("expected the partial writes to be skipped, got %d reload errors", n)
+	}
+	if watcher.Current().DDNS.Domain != "new.example.com" {
+		t.Errorf("expected the new config to be active, got domain %q", watcher.Current().DDNS.Domain)
+	}
+}
+
+func TestWatcherDefaultsMergeWritesAcrossPolls(t *testing.T) {
+	if testing.Short() {
+		t.Skip("polls at the default interval")
+	}
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "old.example.com", "api_key": "key"}}`)
+
+	var changes, failures atomic.Int32
+	changed := make(chan *Config, 10)
+	watcher, err := NewWatcher(configPath,
+		func(c *Config) {
+			changes.Add(1)
+			changed <- c
+		},
+		WithErrorCallback(func(error) { failures.Add(1) }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+	time.Sleep(50 * time.Millisecond)
+
+	// Steps further apart than the default debounce, so each is seen by a different poll
+	writeConfigFile(t, configPath, "")
+	time.Sleep(700 * time.Millisecond)
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "new.exa`)
+	time.Sleep(700 * time.Millisecond)
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "new.example.com", "api_key": "key"}}`)
+
+	select {
+	case c := <-changed:
+		if c.DDNS.Domain != "new.example.com" {
+			t.Errorf("expected the reloaded domain new.example.com, got %q", c.DDNS.Domain)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the reload")
+	}
+
+	if n := changes.Load(); n != 1 {
+		t.Errorf("expected a single reload for the whole save, got %d", n)
+	}
+	if n := failures.Load(); n != 0 {
+		t.Errorf("expected the partial writes to be skipped, got %d reload errors", n)
+	}
+}
+
+func TestWatcherKeepsConfigWhenReloadIsInvalid(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "old.example.com", "api_key": "key"}}`)
+
+	failed := make(chan error, 1)
+	var changes atomic.Int32
+	watcher, err := NewWatcher(configPath,
+		func(*Config) { changes.Add(1) },
+		WithDebounce(10*time.Millisecond),
+		WithPollInterval(5*time.Millisecond),
+		WithErrorCallback(func(err error) { failed <- err }),
+	)
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+
+	writeConfigFile(t, configPath, `{"ddns": {"domain": "new.example.com", "api_key": "key"}, "server": {"port": 70000}}`)
+	watcher.event()
+
+	select {
+	case err := <-failed:
+		if !strings.Contains(err.Error(), "server.port") {
+			t.Errorf("expected a server.port validation error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the reload error")
+	}
+
+	if changes.Load() != 0 {
+		t.Error("expected the change callback not to be called for an invalid config")
+	}
+	if watcher.Current().DDNS.Domain != "old.example.com" {
+		t.Errorf("expected the old config to stay active, got domain %q", watcher.Current().DDNS.Domain)
+	}
+}
+
+func TestNewWatcherRequiresFile(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	if _, err := NewWatcher(filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("expected an error for a missing config file")
+	}
+}
@@ -0,0 +1,228 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/executor"
+)
+
+// DefaultShutdownTimeout bounds the wait for an in-flight update when RunConfig sets none
+const DefaultShutdownTimeout = 30 * time.Second
+
+// updateTimeout bounds a single update cycle
+const updateTimeout = 2 * time.Minute
+
+// Updater is implemented by both Service and FailoverService
+type Updater interface {
+	UpdateIP(ctx context.Context) (*UpdateResponse, error)
+	UpdateRecord(ctx context.Context, value string) (*UpdateResponse, error)
+	Validate(ctx context.Context) error
+}
+
+// cacheInvalidator is implemented by updaters that cache detected addresses or record values
+type cacheInvalidator interface {
+	InvalidateCaches()
+}
+
+// RunConfig controls the update loop started by Run
+type RunConfig struct {
+	UpdateInterval  time.Duration
+	JitterPercent   int           // Each interval varies randomly by up to this percentage, so fleets spread out; 0 disables
+	RecordValue     string        // Published instead of the detected IP when set
+	ShutdownTimeout time.Duration // How long an in-flight update may finish after ctx is done
+
+	// MaxConsecutiveFailures stops Run with an error after that many failed cycles in a row; 0 never stops
+	MaxConsecutiveFailures int
+
+	// Trigger runs an extra update as soon as it receives, without moving the next scheduled one; nil never triggers
+	Trigger <-chan struct{}
+
+	// OnShutdown runs after a graceful shutdown once in-flight updates are done, with a context that ends
+	// when what is left of ShutdownTimeout runs out, so cleanup shares the shutdown deadline
+	OnShutdown func(ctx context.Context)
+
+	// Logger is told about each update cycle and the shutdown; nil logs nothing
+	Logger Logger
+}
+
+// Run updates the record every interval until ctx is done, then waits for the in-flight update
+// Updates don't inherit ctx, so cancelling it doesn't interrupt a write mid-flight; they are only
+// cancelled once ShutdownTimeout passes. Run returns nil after a graceful shutdown
+func Run(ctx context.Context, updater Updater, cfg RunConfig) error {
+	if cfg.UpdateInterval <= 0 {
+		return fmt.Errorf("update interval must be positive, got %s", cfg.UpdateInterval)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = noopLogger{}
+	}
+
+	// Updates get their own context so a shutdown doesn't interrupt a write mid-flight
+	updateCtx, updateCancel := context.WithCancel(context.Background())
+	defer updateCancel()
+
+	var inFlight sync.WaitGroup
+	consecutiveFailures := 0
+	update := func() error {
+		done := make(chan bool, 1)
+		inFlight.Add(1)
+		go func() {
+			defer inFlight.Done()
+			done <- performUpdate(updateCtx, updater, cfg.RecordValue, cfg.Logger)
+		}()
+
+		// Keep listening for shutdown while the update runs
+		select {
+		case success := <-done:
+			if success {
+				consecutiveFailures = 0
+				return nil
+			}
+			consecutiveFailures++
+			if cfg.MaxConsecutiveFailures > 0 && consecutiveFailures >= cfg.MaxConsecutiveFailures {
+				return fmt.Errorf("%d consecutive update cycles failed", consecutiveFailures)
+			}
+		case <-ctx.Done():
+		}
+		return nil
+	}
+
+	// Each interval is jittered separately, so a timer is reset after every update instead of using a ticker
+	timer := time.NewTimer(jitteredInterval(cfg.UpdateInterval, cfg.JitterPercent, rand.Int64N))
+	defer timer.Stop()
+
+	// Perform initial update
+	cfg.Logger.Info("Performing initial IP update")
+	if err := update(); err != nil {
+		return err
+	}
+
+	// Start the update loop
+	for {
+		select {
+		case <-ctx.Done():
+			shutdown(&inFlight, cfg, updateCancel)
+			cfg.Logger.Info("DDNS client stopped")
+			return nil
+		case <-timer.C:
+			if err := update(); err != nil {
+				return err
+			}
+			timer.Reset(jitteredInterval(cfg.UpdateInterval, cfg.JitterPercent, rand.Int64N))
+		case <-cfg.Trigger:
+			cfg.Logger.Info("Performing triggered IP update")
+			// A triggered update must not republish what the last scheduled one cached
+			if invalidator, ok := updater.(cacheInvalidator); ok {
+				invalidator.InvalidateCaches()
+			}
+			if err := update(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// RunOnce performs a single update cycle, returning an error if it failed; a nil logger logs nothing
+func RunOnce(ctx context.Context, updater Updater, recordValue string, logger Logger) error {
+	if logger == nil {
+		logger = noopLogger{}
+	}
+	if !performUpdate(ctx, updater, recordValue, logger) {
+		return fmt.Errorf("update failed")
+	}
+	return nil
+}
+
+// jitteredInterval returns interval moved randomly by up to percent of it in either direction
+// int64N returns a random number in [0, n), as rand.Int64N does
+func jitteredInterval(interval time.Duration, percent int, int64N func(n int64) int64) time.Duration {
+	spread := int64(interval) * int64(percent) / 100
+	if spread <= 0 {
+		return interval
+	}
+
+	return interval - time.Duration(spread) + time.Duration(int64N(2*spread+1))
+}
+
+// performUpdate runs a single update cycle, reporting whether it succeeded
+func performUpdate(ctx context.Context, updater Updater, recordValue string, logger Logger) bool {
+	updateCtx, updateCancel := context.WithTimeout(ctx, updateTimeout)
+	defer updateCancel()
+
+	// Tag the cycle so every log line, including retries, can be correlated
+	requestID := executor.NewRequestID()
+	updateCtx = executor.WithRequestID(updateCtx, requestID)
+
+	var response *UpdateResponse
+	var err error
+	if recordValue != "" {
+		// An explicit value bypasses IP detection
+		logger.Info("Publishing configured record value", "request_id", requestID)
+		response, err = updater.UpdateRecord(updateCtx, recordValue)
+	} else {
+		logger.Info("Checking for IP changes", "request_id", requestID)
+		response, err = updater.UpdateIP(updateCtx)
+	}
+	if err != nil {
+		logger.Error("Failed to update IP", "request_id", requestID, "error", err)
+		return false
+	}
+
+	if response.Success {
+		logger.Info("DNS update successful", "request_id", requestID, "message", response.Message)
+	} else {
+		logger.Error("DNS update failed", "request_id", requestID, "message", response.Message)
+	}
+
+	if response.RecordID != "" {
+		logger.Debug("Updated record", "request_id", requestID, "record_id", response.RecordID)
+	}
+
+	if response.PropagationChecked {
+		if response.PropagationConfirmed {
+			logger.Info("Propagation confirmed", "request_id", requestID)
+		} else {
+			logger.Warn("Propagation not confirmed before the timeout", "request_id", requestID)
+		}
+	}
+
+	if failover, ok := updater.(*FailoverService); ok {
+		logger.Info("Active provider", "request_id", requestID, "provider", failover.ActiveProvider())
+	}
+
+	return response.Success
+}
+
+// shutdown waits for in-flight updates and then runs cfg.OnShutdown, all within one ShutdownTimeout
+func shutdown(inFlight *sync.WaitGroup, cfg RunConfig, cancelUpdates context.CancelFunc) {
+	timeout := cfg.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	waitForUpdates(ctx, inFlight, timeout, cancelUpdates, cfg.Logger)
+	if cfg.OnShutdown != nil {
+		cfg.OnShutdown(ctx)
+	}
+}
+
+// waitForUpdates waits for in-flight updates to finish, cancelling them once ctx is done
+func waitForUpdates(ctx context.Context, inFlight *sync.WaitGroup, timeout time.Duration, cancel context.CancelFunc, logger Logger) {
+	done := make(chan struct{})
+	go func() {
+		inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		logger.Warn("Update still running after the shutdown timeout, cancelling it", "timeout", timeout)
+		cancel()
+	}
+}
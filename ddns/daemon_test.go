@@ -0,0 +1,246 @@
+package ddns
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowProvider takes delay to update and counts completed and concurrent updates
+type slowProvider struct {
+	*mockProvider
+	delay     time.Duration
+	completed atomic.Int32
+	active    atomic.Int32
+	maxActive atomic.Int32
+}
+
+func (p *slowProvider) UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	active := p.active.Add(1)
+	defer p.active.Add(-1)
+	for {
+		peak := p.maxActive.Load()
+		if active <= peak || p.maxActive.CompareAndSwap(peak, active) {
+			break
+		}
+	}
+
+	select {
+	case <-time.After(p.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	p.completed.Add(1)
+	return p.mockProvider.UpdateRecord(ctx, req)
+}
+
+func TestRunWaitsForInFlightUpdateOnShutdown(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow"), delay: 100 * time.Millisecond}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	err := Run(ctx, service, RunConfig{UpdateInterval: time.Hour, ShutdownTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if provider.completed.Load() != 1 {
+		t.Errorf("Expected the in-flight update to complete, got %d", provider.completed.Load())
+	}
+}
+
+func TestRunCancelsUpdateAfterShutdownTimeout(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow"), delay: time.Minute}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	start := time.Now()
+	if err := Run(ctx, service, RunConfig{UpdateInterval: time.Hour, ShutdownTimeout: 50 * time.Millisecond}); err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to give up after the timeout, took %s", elapsed)
+	}
+}
+
+func TestRunOnShutdownSharesShutdownTimeout(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow"), delay: 200 * time.Millisecond}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	var remaining time.Duration
+	err := Run(ctx, service, RunConfig{
+		UpdateInterval:  time.Hour,
+		ShutdownTimeout: time.Second,
+		OnShutdown: func(ctx context.Context) {
+			deadline, _ := ctx.Deadline()
+			remaining = time.Until(deadline)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if provider.completed.Load() != 1 {
+		t.Errorf("Expected the in-flight update to complete first, got %d", provider.completed.Load())
+	}
+	if remaining <= 0 || remaining > 900*time.Millisecond {
+		t.Errorf("Expected OnShutdown to get what was left of the timeout after the update, got %s", remaining)
+	}
+}
+
+func TestRunDoesNotOverlapSlowUpdates(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow"), delay: 30 * time.Millisecond}
+	config := Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+
+	// The interval is far shorter than an update, so overlapping cycles would show up at once
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(150*time.Millisecond, cancel)
+
+	if err := Run(ctx, service, RunConfig{UpdateInterval: time.Millisecond, ShutdownTimeout: 5 * time.Second}); err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if provider.completed.Load() < 2 {
+		t.Errorf("Expected several updates, got %d", provider.completed.Load())
+	}
+	if peak := provider.maxActive.Load(); peak != 1 {
+		t.Errorf("Expected one update at a time, got %d concurrent updates", peak)
+	}
+}
+
+func TestRunStopsAfterConsecutiveFailures(t *testing.T) {
+	provider := newMockProvider("broken")
+	provider.shouldFail = true
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	err := Run(context.Background(), service, RunConfig{UpdateInterval: 10 * time.Millisecond, MaxConsecutiveFailures: 2})
+	if err == nil {
+		t.Fatal("Expected error after consecutive failures")
+	}
+}
+
+func TestRunRejectsNonPositiveInterval(t *testing.T) {
+	provider := newMockProvider("test")
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	for _, interval := range []time.Duration{0, -time.Second} {
+		if err := Run(context.Background(), service, RunConfig{UpdateInterval: interval}); err == nil {
+			t.Errorf("Expected an error for update interval %s", interval)
+		}
+	}
+	if len(provider.records) != 0 {
+		t.Errorf("Expected no update before the interval is validated, got %v", provider.records)
+	}
+}
+
+func TestJitteredInterval(t *testing.T) {
+	interval := 10 * time.Minute
+
+	if got := jitteredInterval(interval, 0, nil); got != interval {
+		t.Errorf("Expected no jitter at 0%%, got %s", got)
+	}
+
+	// 10% of 10m spreads ticks over [9m, 11m]
+	lowest := func(n int64) int64 { return 0 }
+	highest := func(n int64) int64 { return n - 1 }
+	if got := jitteredInterval(interval, 10, lowest); got != 9*time.Minute {
+		t.Errorf("Expected 9m at the low end, got %s", got)
+	}
+	if got := jitteredInterval(interval, 10, highest); got != 11*time.Minute {
+		t.Errorf("Expected 11m at the high end, got %s", got)
+	}
+}
+
+func TestRunOnce(t *testing.T) {
+	provider := newMockProvider("test")
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	logger := &recordingLogger{}
+	if err := RunOnce(context.Background(), service, "", logger); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.records["example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected record to be updated, got %v", provider.records)
+	}
+	if want := []string{"INFO Checking for IP changes", "INFO DNS update successful", "DEBUG Updated record"}; !slices.Equal(logger.lines, want) {
+		t.Errorf("Expected %q, got %q", want, logger.lines)
+	}
+
+	provider.shouldFail = true
+	if err := RunOnce(context.Background(), service, "", nil); err == nil {
+		t.Error("Expected error when the update fails")
+	}
+}
+
+func TestRunTriggerBypassesCaches(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow")}
+	detector := &mockIPDetector{ip: "203.0.113.1"}
+	config := Config{Domain: "example.com", RecordType: "A", RecordCacheTTL: time.Hour}
+	service := NewServiceWithIPDetector(provider, config, NewCachedIPDetector(detector, time.Hour))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	trigger := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, service, RunConfig{UpdateInterval: time.Hour, ShutdownTimeout: time.Second, Trigger: trigger})
+	}()
+
+	waitForCompleted := func(n int32) {
+		deadline := time.Now().Add(5 * time.Second)
+		for provider.completed.Load() < n && time.Now().Before(deadline) {
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	waitForCompleted(1)
+	detector.ip = "203.0.113.2"
+	trigger <- struct{}{}
+	waitForCompleted(2)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if got := provider.records["example.com:A"]; got != "203.0.113.2" {
+		t.Errorf("Expected the triggered update to publish the new IP, got %s", got)
+	}
+}
+
+func TestRunUpdatesWhenTriggered(t *testing.T) {
+	provider := &slowProvider{mockProvider: newMockProvider("slow")}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}, &mockIPDetector{ip: "203.0.113.1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	trigger := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, service, RunConfig{UpdateInterval: time.Hour, ShutdownTimeout: time.Second, Trigger: trigger})
+	}()
+
+	trigger <- struct{}{}
+	trigger <- struct{}{}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for provider.completed.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected graceful shutdown, got %v", err)
+	}
+	if got := provider.completed.Load(); got != 3 {
+		t.Errorf("Expected the initial and two triggered updates within the hour-long interval, got %d", got)
+	}
+}
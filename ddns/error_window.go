@@ -0,0 +1,67 @@
+package ddns
+
+import (
+	"sync"
+	"time"
+)
+
+// errorWindowSize is how many recent errors a Service keeps
+const errorWindowSize = 100
+
+// TimestampedError is an update error with when it occurred and the domain it affected
+type TimestampedError struct {
+	Err        error
+	OccurredAt time.Time
+	Domain     string
+}
+
+// errorWindow is a fixed-size circular buffer of the most recent errors, safe for concurrent use
+type errorWindow struct {
+	mu      sync.Mutex
+	entries []TimestampedError
+	next    int // Index the next error is written to once the buffer is full
+}
+
+// newErrorWindow creates a window holding up to size errors
+func newErrorWindow(size int) *errorWindow {
+	return &errorWindow{entries: make([]TimestampedError, 0, size)}
+}
+
+// add records an error, overwriting the oldest one once the window is full
+func (w *errorWindow) add(entry TimestampedError) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.entries) < cap(w.entries) {
+		w.entries = append(w.entries, entry)
+		return
+	}
+	w.entries[w.next] = entry
+	w.next = (w.next + 1) % len(w.entries)
+}
+
+// snapshot returns the recorded errors, oldest first
+func (w *errorWindow) snapshot() []TimestampedError {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	errs := make([]TimestampedError, 0, len(w.entries))
+	errs = append(errs, w.entries[w.next:]...)
+	return append(errs, w.entries[:w.next]...)
+}
+
+// rate returns the errors per minute recorded in the window before now
+func (w *errorWindow) rate(window time.Duration, now time.Time) float64 {
+	if window <= 0 {
+		return 0
+	}
+
+	since := now.Add(-window)
+	count := 0
+	for _, entry := range w.snapshot() {
+		if entry.OccurredAt.After(since) {
+			count++
+		}
+	}
+	return float64(count) / window.Minutes()
+}
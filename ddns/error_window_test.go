@@ -0,0 +1,65 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorWindowKeepsMostRecent(t *testing.T) {
+	window := newErrorWindow(3)
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		window.add(TimestampedError{Err: fmt.Errorf("error %d", i), OccurredAt: start.Add(time.Duration(i) * time.Second)})
+	}
+
+	errs := window.snapshot()
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %d", len(errs))
+	}
+	for i, entry := range errs {
+		if want := fmt.Sprintf("error %d", i+2); entry.Err.Error() != want {
+			t.Errorf("Expected %q at %d, got %q", want, i, entry.Err)
+		}
+	}
+}
+
+func TestErrorWindowRate(t *testing.T) {
+	window := newErrorWindow(errorWindowSize)
+	now := time.Now()
+	for _, age := range []time.Duration{10 * time.Second, 30 * time.Second, 90 * time.Second, 10 * time.Minute} {
+		window.add(TimestampedError{Err: errors.New("failed"), OccurredAt: now.Add(-age)})
+	}
+
+	if rate := window.rate(2*time.Minute, now); rate != 1.5 {
+		t.Errorf("Expected 3 errors in 2 minutes to be 1.5 per minute, got %v", rate)
+	}
+	if rate := window.rate(0, now); rate != 0 {
+		t.Errorf("Expected 0 for an empty window, got %v", rate)
+	}
+}
+
+func TestServiceRecordsUpdateErrors(t *testing.T) {
+	provider := newMockProvider("broken")
+	provider.shouldFail = true
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	service.UpdateIP(context.Background())
+	service.ipDetector = &mockIPDetector{shouldFail: true}
+	service.UpdateIP(context.Background())
+
+	errs := service.GetErrorWindow()
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 recorded errors, got %d", len(errs))
+	}
+	for _, entry := range errs {
+		if entry.Domain != "example.com" || entry.Err == nil || entry.OccurredAt.IsZero() {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+	}
+	if rate := service.GetErrorRate(time.Minute); rate != 2 {
+		t.Errorf("Expected 2 errors per minute, got %v", rate)
+	}
+}
@@ -0,0 +1,46 @@
+package ddns
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel causes for common provider failures, found with errors.Is through a ProviderError
+var (
+	ErrAuthFailed  = errors.New("authentication failed")
+	ErrNotFound    = errors.New("record not found")
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// ProviderError describes a failed provider call
+type ProviderError struct {
+	Provider   string // Provider name, e.g. "dynu"
+	Operation  string // Provider method that failed, e.g. "UpdateRecord"
+	StatusCode int    // HTTP status of the failed response, or zero if there was none
+	Retryable  bool   // Whether retrying the call may succeed
+	Cause      error
+}
+
+// Error implements the error interface
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s %s: %v", e.Provider, e.Operation, e.Cause)
+}
+
+// Unwrap returns the underlying cause
+func (e *ProviderError) Unwrap() error {
+	return e.Cause
+}
+
+// IsRetryable reports whether retrying the call may succeed, letting the executor skip hopeless retries
+func (e *ProviderError) IsRetryable() bool {
+	return e.Retryable
+}
+
+// IsProviderError returns the ProviderError in err's chain, if any
+func IsProviderError(err error) (*ProviderError, bool) {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr, true
+	}
+	return nil, false
+}
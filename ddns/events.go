@@ -0,0 +1,74 @@
+package ddns
+
+import "time"
+
+// defaultEventBufferSize is used when Config.EventBufferSize is not set
+const defaultEventBufferSize = 16
+
+// UpdateEvent describes a DNS record change performed by the service
+type UpdateEvent struct {
+	Domain     string
+	RecordType string
+	IP         string // The value the record was updated to
+	Response   *UpdateResponse
+	OccurredAt time.Time
+}
+
+// ServiceStats holds counters describing the service's event delivery
+type ServiceStats struct {
+	DroppedEvents int64 // Events not delivered because a subscriber's channel was full
+}
+
+// Subscribe returns a channel that receives an UpdateEvent after every update that changed the record
+func (s *Service) Subscribe() <-chan UpdateEvent {
+	size := s.config.EventBufferSize
+	if size <= 0 {
+		size = defaultEventBufferSize
+	}
+
+	ch := make(chan UpdateEvent, size)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, ch)
+
+	return ch
+}
+
+// Unsubscribe removes a subscription and closes its channel
+func (s *Service) Unsubscribe(ch <-chan UpdateEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, subscriber := range s.subscribers {
+		if subscriber == ch {
+			s.subscribers = append(s.subscribers[:i], s.subscribers[i+1:]...)
+			close(subscriber)
+			return
+		}
+	}
+}
+
+// Stats returns a snapshot of the service's counters
+func (s *Service) Stats() ServiceStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return ServiceStats{
+		DroppedEvents: s.droppedEvents,
+	}
+}
+
+// publish delivers an event to all subscribers without blocking, dropping it for full channels
+func (s *Service) publish(event UpdateEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, subscriber := range s.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			s.droppedEvents++
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// FailoverTarget pairs a provider with the configuration used to update it
+type FailoverTarget struct {
+	Provider Provider
+	Config   Config
+}
+
+// FailoverService updates the record on the first provider that succeeds, in priority order
+// Every update starts with the primary, so the service returns to it as soon as it recovers
+type FailoverService struct {
+	services   []*Service
+	ipDetector IPDetector
+
+	mu     sync.Mutex
+	active int // Index of the provider that handled the last successful update
+}
+
+// NewFailoverService creates a failover service; the first target is the primary
+func NewFailoverService(targets []FailoverTarget, ipDetector IPDetector) *FailoverService {
+	services := make([]*Service, 0, len(targets))
+	for _, target := range targets {
+		services = append(services, NewServiceWithIPDetector(target.Provider, target.Config, ipDetector))
+	}
+
+	return &FailoverService{
+		services:   services,
+		ipDetector: ipDetector,
+	}
+}
+
+// WithLogger sets the logger of the service updating each target
+func (f *FailoverService) WithLogger(logger Logger) *FailoverService {
+	for _, service := range f.services {
+		service.WithLogger(logger)
+	}
+	return f
+}
+
+// InvalidateCaches forgets the cached addresses and record values of the service updating each target
+func (f *FailoverService) InvalidateCaches() {
+	for _, service := range f.services {
+		service.InvalidateCaches()
+	}
+}
+
+// UpdateIP publishes the current public IP, failing over to backup providers on error
+func (f *FailoverService) UpdateIP(ctx context.Context) (*UpdateResponse, error) {
+	if len(f.services) == 0 {
+		return nil, fmt.Errorf("no providers configured")
+	}
+
+	// TXT and CNAME records don't publish the IP; let each service apply its own rules
+	switch f.services[0].config.RecordType {
+	case "TXT", "CNAME":
+		return f.failover(func(s *Service) (*UpdateResponse, error) {
+			return s.UpdateIP(ctx)
+		})
+	}
+
+	// Detect the IP once, since a detection failure isn't a reason to switch providers
+	currentIP, err := detectForRecordType(ctx, f.ipDetector, f.services[0].config.RecordType)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.UpdateRecord(ctx, currentIP)
+}
+
+// UpdateRecord publishes the given value, failing over to backup providers on error
+func (f *FailoverService) UpdateRecord(ctx context.Context, value string) (*UpdateResponse, error) {
+	return f.failover(func(s *Service) (*UpdateResponse, error) {
+		return s.UpdateRecord(ctx, value)
+	})
+}
+
+// failover runs update against each service in priority order until one succeeds
+func (f *FailoverService) failover(update func(s *Service) (*UpdateResponse, error)) (*UpdateResponse, error) {
+	if len(f.services) == 0 {
+		return nil, fmt.Errorf("no providers configured")
+	}
+
+	var errs []error
+	for i, service := range f.services {
+		resp, err := update(service)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", service.GetProvider().GetProviderName(), err))
+			continue
+		}
+
+		f.mu.Lock()
+		f.active = i
+		f.mu.Unlock()
+
+		return resp, nil
+	}
+
+	return nil, fmt.Errorf("all providers failed: %w", errors.Join(errs...))
+}
+
+// ActiveProvider returns the name of the provider that handled the last successful update
+// Before any update it reports the primary
+func (f *FailoverService) ActiveProvider() string {
+	if len(f.services) == 0 {
+		return ""
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.services[f.active].GetProvider().GetProviderName()
+}
+
+// Validate checks the credentials of every provider, so a broken backup is noticed before it's needed
+func (f *FailoverService) Validate(ctx context.Context) error {
+	var errs []error
+	for _, service := range f.services {
+		if err := service.Validate(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,90 @@
+package ddns
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFailoverServicePrimaryFailsSecondarySucceeds(t *testing.T) {
+	primary := newMockProvider("primary")
+	primary.shouldFail = true
+	secondary := newMockProvider("secondary")
+
+	config := Config{Domain: "example.com", RecordType: "A"}
+	service := NewFailoverService([]FailoverTarget{
+		{Provider: primary, Config: config},
+		{Provider: secondary, Config: config},
+	}, &mockIPDetector{ip: "192.168.1.1"})
+
+	if got := service.ActiveProvider(); got != "primary" {
+		t.Errorf("Expected primary to be active before any update, got %s", got)
+	}
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected failover to succeed, got %v", err)
+	}
+	if !resp.Success {
+		t.Error("Expected successful response")
+	}
+
+	if secondary.records["example.com:A"] != "192.168.1.1" {
+		t.Errorf("Expected secondary to hold the IP, got %q", secondary.records["example.com:A"])
+	}
+	if got := service.ActiveProvider(); got != "secondary" {
+		t.Errorf("Expected secondary to be active, got %s", got)
+	}
+
+	// Once the primary recovers, the next update goes back to it
+	primary.shouldFail = false
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+	if primary.records["example.com:A"] != "192.168.1.1" {
+		t.Errorf("Expected primary to hold the IP, got %q", primary.records["example.com:A"])
+	}
+	if got := service.ActiveProvider(); got != "primary" {
+		t.Errorf("Expected primary to be active again, got %s", got)
+	}
+}
+
+func TestFailoverServiceAllProvidersFail(t *testing.T) {
+	primary := newMockProvider("primary")
+	primary.shouldFail = true
+	secondary := newMockProvider("secondary")
+	secondary.shouldFail = true
+
+	config := Config{Domain: "example.com", RecordType: "A"}
+	service := NewFailoverService([]FailoverTarget{
+		{Provider: primary, Config: config},
+		{Provider: secondary, Config: config},
+	}, &mockIPDetector{ip: "192.168.1.1"})
+
+	_, err := service.UpdateIP(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when every provider fails")
+	}
+
+	if got := service.ActiveProvider(); got != "primary" {
+		t.Errorf("Expected active provider to be unchanged, got %s", got)
+	}
+}
+
+func TestFailoverServiceIPDetectionFails(t *testing.T) {
+	primary := newMockProvider("primary")
+	secondary := newMockProvider("secondary")
+
+	config := Config{Domain: "example.com", RecordType: "A"}
+	service := NewFailoverService([]FailoverTarget{
+		{Provider: primary, Config: config},
+		{Provider: secondary, Config: config},
+	}, &mockIPDetector{shouldFail: true})
+
+	if _, err := service.UpdateIP(context.Background()); err == nil {
+		t.Fatal("Expected error when IP detection fails")
+	}
+
+	if len(secondary.records) != 0 {
+		t.Error("Expected no failover when IP detection fails")
+	}
+}
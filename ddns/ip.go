@@ -3,31 +3,337 @@ package ddns
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/jq1836/DDNS/executor"
 )
 
+// IP detection methods selectable through Config.IPDetectionMethod
+const (
+	IPDetectionHTTP                 = "http"
+	IPDetectionSTUN                 = "stun"
+	IPDetectionSTUNWithHTTPFallback = "stun_with_http_fallback"
+	IPDetectionInterface            = "interface" // IPv6 from the host's interfaces, IPv4 and fallback over HTTP
+)
+
+// DefaultUserAgent is sent with requests made through clients that don't set their own
+const DefaultUserAgent = "ddns-client/1.0"
+
+// stunTimeout bounds a single STUN query
+const stunTimeout = 5 * time.Second
+
+// ErrPrivateIP is returned when the detected address is in private (RFC 1918 or unique local) space
+var ErrPrivateIP = errors.New("detected IP address is private")
+
+// IPv6Detector is implemented by IP detectors that can detect the public IPv6 address
+// GetPublicIP may return either family, so AAAA records rely on this instead
+type IPv6Detector interface {
+	GetPublicIPv6(ctx context.Context) (string, error)
+}
+
+// detectForRecordType asks detector for the address records of recordType hold
+// AAAA records use GetPublicIPv6 when the detector has it, since GetPublicIP may return either family
+func detectForRecordType(ctx context.Context, detector IPDetector, recordType string) (string, error) {
+	if recordType == "AAAA" {
+		if ipv6Detector, ok := detector.(IPv6Detector); ok {
+			return ipv6Detector.GetPublicIPv6(ctx)
+		}
+	}
+	return detector.GetPublicIP(ctx)
+}
+
+// NewIPDetector returns the detector for the given method, using httpDetector for HTTP-based detection
+// allowPrivateIP only applies to detectors created here; httpDetector keeps its own setting
+func NewIPDetector(method string, httpDetector IPDetector, allowPrivateIP bool) IPDetector {
+	switch method {
+	case IPDetectionSTUN:
+		return NewSTUNIPDetector(DefaultSTUNServer, stunTimeout).WithAllowPrivateIP(allowPrivateIP)
+	case IPDetectionSTUNWithHTTPFallback:
+		return NewSTUNIPDetector(DefaultSTUNServer, stunTimeout).WithAllowPrivateIP(allowPrivateIP).WithFallback(httpDetector)
+	case IPDetectionInterface:
+		return NewInterfaceIPDetector("").WithAllowPrivateIP(allowPrivateIP).WithFallback(httpDetector)
+	default:
+		return httpDetector
+	}
+}
+
+// NewConfiguredIPDetector is NewIPDetector with the method and detector settings taken from config
+func NewConfiguredIPDetector(config Config, httpDetector IPDetector) IPDetector {
+	if config.IPDetectionMethod == IPDetectionInterface {
+		return NewInterfaceIPDetector(config.IPv6Interface).
+			WithSkipTemporary(config.SkipTemporaryIPv6).
+			WithAllowPrivateIP(config.AllowPrivateIP).
+			WithFallback(httpDetector)
+	}
+	return NewIPDetector(config.IPDetectionMethod, httpDetector, config.AllowPrivateIP)
+}
+
+// CachedIPDetector wraps an IP detector and reuses its result for a TTL to avoid redundant lookups
+type CachedIPDetector struct {
+	inner IPDetector
+	ttl   time.Duration
+
+	mu      sync.RWMutex
+	entries map[IPFamily]cachedIP // Keyed by IPFamilyAny for GetPublicIP and IPFamilyV6 for GetPublicIPv6
+	now     func() time.Time
+}
+
+// cachedIP holds a detected address and when it was detected
+type cachedIP struct {
+	ip         string
+	detectedAt time.Time
+}
+
+// NewCachedIPDetector creates a detector that returns inner's last result until ttl passes
+// Failed detections are not cached
+func NewCachedIPDetector(inner IPDetector, ttl time.Duration) *CachedIPDetector {
+	return &CachedIPDetector{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[IPFamily]cachedIP),
+		now:     time.Now,
+	}
+}
+
+// GetPublicIP returns the cached address while fresh, otherwise asks the inner detector
+func (d *CachedIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	return d.get(IPFamilyAny, func() (string, error) {
+		return d.inner.GetPublicIP(ctx)
+	})
+}
+
+// GetPublicIPv6 returns the cached IPv6 address while fresh, otherwise asks the inner detector
+func (d *CachedIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	detector, ok := d.inner.(IPv6Detector)
+	if !ok {
+		return "", fmt.Errorf("IP detector cannot detect IPv6 addresses")
+	}
+
+	return d.get(IPFamilyV6, func() (string, error) {
+		return detector.GetPublicIPv6(ctx)
+	})
+}
+
+// Invalidate drops the cached addresses, so the next call detects afresh
+func (d *CachedIPDetector) Invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	clear(d.entries)
+}
+
+// get returns the cached address of the family, refreshing it with detect once the TTL has passed
+// A refreshed address always replaces the cached one, so a change is picked up as soon as it is detected
+func (d *CachedIPDetector) get(family IPFamily, detect func() (string, error)) (string, error) {
+	d.mu.RLock()
+	entry, ok := d.entries[family]
+	d.mu.RUnlock()
+
+	if ok && d.now().Sub(entry.detectedAt) < d.ttl {
+		return entry.ip, nil
+	}
+
+	ip, err := detect()
+	if err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	d.entries[family] = cachedIP{ip: ip, detectedAt: d.now()}
+	d.mu.Unlock()
+
+	return ip, nil
+}
+
+// ValidatePublicIP checks that ip is an address worth publishing to DNS
+// Private addresses yield ErrPrivateIP; loopback, link-local, multicast and unspecified addresses are also rejected
+func ValidatePublicIP(ip string) error {
+	return validateDetectedIP(ip, false)
+}
+
+// validateDetectedIP is ValidatePublicIP with an opt-in for private addresses
+func validateDetectedIP(ip string, allowPrivate bool) error {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Errorf("invalid IP address: %q", ip)
+	}
+
+	switch {
+	case parsed.IsUnspecified():
+		return fmt.Errorf("detected IP address %s is unspecified", ip)
+	case parsed.IsLoopback():
+		return fmt.Errorf("detected IP address %s is a loopback address", ip)
+	case parsed.IsLinkLocalUnicast():
+		return fmt.Errorf("detected IP address %s is link-local", ip)
+	case parsed.IsMulticast():
+		return fmt.Errorf("detected IP address %s is multicast", ip)
+	case parsed.IsPrivate() && !allowPrivate:
+		return fmt.Errorf("%w: %s", ErrPrivateIP, ip)
+	}
+
+	return nil
+}
+
+// ipv6LookupURL answers with the caller's address as plain text and is only reachable over IPv6
+const ipv6LookupURL = "https://api6.ipify.org"
+
+// IPFamily selects the address family an HTTPIPDetector connects over
+type IPFamily int
+
+const (
+	IPFamilyAny IPFamily = iota // Whichever family the connection happens to use
+	IPFamilyV4
+	IPFamilyV6
+)
+
+// IPFamilyForRecordType returns the family of the addresses records of the given type hold
+func IPFamilyForRecordType(recordType string) IPFamily {
+	switch strings.ToUpper(recordType) {
+	case "A":
+		return IPFamilyV4
+	case "AAAA":
+		return IPFamilyV6
+	default:
+		return IPFamilyAny
+	}
+}
+
+// network returns the dial network that only connects over the family
+func (f IPFamily) network() string {
+	switch f {
+	case IPFamilyV4:
+		return "tcp4"
+	case IPFamilyV6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// checkAddress returns an error unless ip is an address of the family
+func (f IPFamily) checkAddress(ip string) error {
+	parsed := net.ParseIP(ip)
+	switch {
+	case parsed == nil:
+		return fmt.Errorf("invalid IP address: %q", ip)
+	case f == IPFamilyV4 && parsed.To4() == nil:
+		return fmt.Errorf("detected address %q is not an IPv4 address", ip)
+	case f == IPFamilyV6 && parsed.To4() != nil:
+		return fmt.Errorf("detected address %q is not an IPv6 address", ip)
+	}
+	return nil
+}
+
+// familyClient returns a copy of client whose connections are made over the family only
+func familyClient(client *http.Client, family IPFamily) *http.Client {
+	if family == IPFamilyAny {
+		if client == nil {
+			return &http.Client{}
+		}
+		return client
+	}
+
+	clone := &http.Client{}
+	if client != nil {
+		*clone = *client
+	}
+	clone.Transport = familyTransport(clone.Transport, family)
+
+	return clone
+}
+
+// WrappingTransport is implemented by round trippers that pass requests on to another one, such as
+// the shared client's header transport, so the *http.Transport underneath can be reconfigured
+type WrappingTransport interface {
+	Unwrap() http.RoundTripper
+	Wrap(next http.RoundTripper) http.RoundTripper
+}
+
+// familyTransport returns a copy of transport that dials over the family only, keeping any wrappers
+// Proxies are bypassed: the family would only restrict the connection to the proxy, and the lookup
+// service would then see the proxy's address instead of this host's
+func familyTransport(transport http.RoundTripper, family IPFamily) http.RoundTripper {
+	if wrapper, ok := transport.(WrappingTransport); ok {
+		return wrapper.Wrap(familyTransport(wrapper.Unwrap(), family))
+	}
+
+	base, ok := transport.(*http.Transport)
+	if !ok || base == nil {
+		base = http.DefaultTransport.(*http.Transport)
+	}
+	base = base.Clone()
+	base.Proxy = nil
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	network := family.network()
+	base.DialContext = func(ctx context.Context, _, addr string) (net.Conn, error) {
+		return dialer.DialContext(ctx, network, addr)
+	}
+
+	return base
+}
+
+// getIPFromPlainText retrieves the public IP from a service that answers with the bare address
+func getIPFromPlainText(ctx context.Context, client *http.Client, exec *executor.Executor, lookupURL string) (string, error) {
+	ipTask := func(taskCtx context.Context) (string, error) {
+		req, err := http.NewRequestWithContext(taskCtx, "GET", lookupURL, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %w", err)
+		}
+
+		req.Header.Set("User-Agent", DefaultUserAgent)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+		}
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+		if err != nil {
+			return "", fmt.Errorf("failed to read response: %w", err)
+		}
+
+		ip := strings.TrimSpace(string(body))
+		if ip == "" {
+			return "", fmt.Errorf("no IP address in response")
+		}
+
+		return ip, nil
+	}
+
+	return executor.ExecuteSimple(exec, ctx, ipTask)
+}
+
 // IPResponse represents the response from httpbin.org/ip
 type IPResponse struct {
 	Origin string `json:"origin"`
 }
 
-// getIPFromHTTPBin retrieves the public IP from httpbin.org
-func getIPFromHTTPBin(ctx context.Context) (string, error) {
+// httpBinURL answers with the caller's address in an IPResponse
+const httpBinURL = "https://httpbin.org/ip"
+
+// getIPFromHTTPBin retrieves the public IP from httpbin.org, or a compatible service at lookupURL
+func getIPFromHTTPBin(ctx context.Context, client *http.Client, exec *executor.Executor, lookupURL string) (string, error) {
 	// Create a task for getting the IP
 	ipTask := func(taskCtx context.Context) (string, error) {
-		client := &http.Client{}
-
-		req, err := http.NewRequestWithContext(taskCtx, "GET", "https://httpbin.org/ip", nil)
+		req, err := http.NewRequestWithContext(taskCtx, "GET", lookupURL, nil)
 		if err != nil {
 			return "", fmt.Errorf("failed to create request: %w", err)
 		}
 
-		req.Header.Set("User-Agent", "ddns-client/1.0")
+		req.Header.Set("User-Agent", DefaultUserAgent)
 
 		resp, err := client.Do(req)
 		if err != nil {
@@ -57,10 +363,5 @@ func getIPFromHTTPBin(ctx context.Context) (string, error) {
 	}
 
 	// Use the executor for retry logic
-	exec := executor.NewExecutor(
-		executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)),
-		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(10*time.Second)),
-	)
-
 	return executor.ExecuteSimple(exec, ctx, ipTask)
 }
@@ -0,0 +1,179 @@
+package ddns
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ifInet6Path lists the host's IPv6 addresses with their kernel flags on Linux
+const ifInet6Path = "/proc/net/if_inet6"
+
+// IPv6 address flags reported in ifInet6Path, from linux/if_addr.h
+const (
+	ifaFlagTemporary  = 0x01 // RFC 4941 privacy address
+	ifaFlagDADFailed  = 0x08
+	ifaFlagDeprecated = 0x20
+	ifaFlagTentative  = 0x40
+)
+
+// interfaceAddress is an IPv6 address configured on a network interface
+type interfaceAddress struct {
+	iface string
+	ip    net.IP
+	flags int
+}
+
+// InterfaceIPDetector implements IPv6Detector by reading the global IPv6 address configured on the host's interfaces
+// It avoids external lookups picking a temporary address, and falls back to another detector when no address is usable
+type InterfaceIPDetector struct {
+	iface         string
+	skipTemporary bool
+	allowPrivate  bool
+	fallback      IPDetector
+	addresses     func() ([]interfaceAddress, error)
+}
+
+// NewInterfaceIPDetector creates a detector reading addresses of the named interface, or of every interface if empty
+func NewInterfaceIPDetector(iface string) *InterfaceIPDetector {
+	return &InterfaceIPDetector{
+		iface:     iface,
+		addresses: listInterfaceAddresses,
+	}
+}
+
+// WithSkipTemporary sets whether RFC 4941 temporary addresses are ignored in favour of stable ones
+// The flag is read from /proc/net/if_inet6, so elsewhere every address is considered stable
+func (d *InterfaceIPDetector) WithSkipTemporary(skip bool) *InterfaceIPDetector {
+	d.skipTemporary = skip
+	return d
+}
+
+// WithAllowPrivateIP sets whether unique local addresses are used instead of skipped
+func (d *InterfaceIPDetector) WithAllowPrivateIP(allow bool) *InterfaceIPDetector {
+	d.allowPrivate = allow
+	return d
+}
+
+// WithFallback sets a detector used when no interface address is usable, and for IPv4 detection
+func (d *InterfaceIPDetector) WithFallback(fallback IPDetector) *InterfaceIPDetector {
+	d.fallback = fallback
+	return d
+}
+
+// GetPublicIP delegates to the fallback detector, since interfaces behind NAT don't hold the public IPv4 address
+func (d *InterfaceIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	if d.fallback == nil {
+		return "", fmt.Errorf("interface detection cannot detect IPv4 addresses without a fallback")
+	}
+	return d.fallback.GetPublicIP(ctx)
+}
+
+// GetPublicIPv6 returns the first usable global IPv6 address on the interfaces, or asks the fallback if there is none
+func (d *InterfaceIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	ip, err := d.interfaceIPv6()
+	if err == nil {
+		return ip, nil
+	}
+
+	if detector, ok := d.fallback.(IPv6Detector); ok {
+		return detector.GetPublicIPv6(ctx)
+	}
+	return "", err
+}
+
+// interfaceIPv6 picks the address to publish from the interfaces' IPv6 addresses
+func (d *InterfaceIPDetector) interfaceIPv6() (string, error) {
+	addrs, err := d.addresses()
+	if err != nil {
+		return "", fmt.Errorf("failed to list interface addresses: %w", err)
+	}
+
+	for _, addr := range addrs {
+		if d.iface != "" && addr.iface != d.iface {
+			continue
+		}
+		if addr.ip.To4() != nil || addr.flags&(ifaFlagDADFailed|ifaFlagDeprecated|ifaFlagTentative) != 0 {
+			continue
+		}
+		if d.skipTemporary && addr.flags&ifaFlagTemporary != 0 {
+			continue
+		}
+		if validateDetectedIP(addr.ip.String(), d.allowPrivate) != nil {
+			continue
+		}
+		return addr.ip.String(), nil
+	}
+
+	if d.iface != "" {
+		return "", fmt.Errorf("no usable global IPv6 address on interface %s", d.iface)
+	}
+	return "", fmt.Errorf("no usable global IPv6 address on any interface")
+}
+
+// listInterfaceAddresses returns the host's IPv6 addresses, with kernel flags where the platform exposes them
+func listInterfaceAddresses() ([]interfaceAddress, error) {
+	file, err := os.Open(ifInet6Path)
+	if err == nil {
+		defer file.Close()
+		return parseIfInet6(file)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []interfaceAddress
+	for _, iface := range ifaces {
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("interface %s: %w", iface.Name, err)
+		}
+		for _, ifaceAddr := range ifaceAddrs {
+			if ipNet, ok := ifaceAddr.(*net.IPNet); ok && ipNet.IP.To4() == nil {
+				addrs = append(addrs, interfaceAddress{iface: iface.Name, ip: ipNet.IP})
+			}
+		}
+	}
+	return addrs, nil
+}
+
+// parseIfInet6 parses the /proc/net/if_inet6 format: address, index, prefix length, scope, flags and interface name
+func parseIfInet6(r io.Reader) ([]interfaceAddress, error) {
+	var addrs []interfaceAddress
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+
+		raw, err := hex.DecodeString(fields[0])
+		if err != nil || len(raw) != net.IPv6len {
+			return nil, fmt.Errorf("invalid address %q in %s", fields[0], ifInet6Path)
+		}
+		flags, err := strconv.ParseInt(fields[4], 16, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid flags %q in %s", fields[4], ifInet6Path)
+		}
+
+		addrs = append(addrs, interfaceAddress{iface: fields[5], ip: net.IP(raw), flags: int(flags)})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ifInet6Path, err)
+	}
+	return addrs, nil
+}
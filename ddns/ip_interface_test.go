@@ -0,0 +1,114 @@
+package ddns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ifInet6Sample lists a link-local, a temporary, a deprecated and a stable global address on eth0
+const ifInet6Sample = `fe80000000000000021122fffe334455 02 40 20 80     eth0
+20010db8000000001c2d3e4f5a6b7c8d 02 40 00 01     eth0
+20010db8000000000000000000000bad 02 40 00 20     eth0
+20010db800000000021122fffe334455 02 40 00 00     eth0
+00000000000000000000000000000001 01 80 10 80       lo
+`
+
+func TestParseIfInet6(t *testing.T) {
+	addrs, err := parseIfInet6(strings.NewReader(ifInet6Sample))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(addrs) != 5 {
+		t.Fatalf("expected 5 addresses, got %d", len(addrs))
+	}
+
+	temporary := addrs[1]
+	if temporary.iface != "eth0" || temporary.ip.String() != "2001:db8::1c2d:3e4f:5a6b:7c8d" || temporary.flags != ifaFlagTemporary {
+		t.Errorf("unexpected temporary address %+v", temporary)
+	}
+
+	if _, err := parseIfInet6(strings.NewReader("zz 02 40 00 00 eth0\n")); err == nil {
+		t.Error("expected an error for an invalid address")
+	}
+}
+
+func TestInterfaceIPDetector(t *testing.T) {
+	sample := func() ([]interfaceAddress, error) {
+		return parseIfInet6(strings.NewReader(ifInet6Sample))
+	}
+	fallback := &dualStackIPDetector{ipv4: "203.0.113.1", ipv6: "2001:db8::ffff"}
+
+	tests := []struct {
+		name          string
+		iface         string
+		skipTemporary bool
+		addresses     func() ([]interfaceAddress, error)
+		want          string
+	}{
+		{name: "first global address", skipTemporary: false, addresses: sample, want: "2001:db8::1c2d:3e4f:5a6b:7c8d"},
+		{name: "stable address when skipping temporary ones", skipTemporary: true, addresses: sample, want: "2001:db8::211:22ff:fe33:4455"},
+		{name: "fallback for another interface", iface: "wlan0", addresses: sample, want: "2001:db8::ffff"},
+		{
+			name: "fallback without a global address",
+			addresses: func() ([]interfaceAddress, error) {
+				return []interfaceAddress{{iface: "eth0", ip: net.ParseIP("fd00::1")}}, nil
+			},
+			want: "2001:db8::ffff",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := NewInterfaceIPDetector(tt.iface).WithSkipTemporary(tt.skipTemporary).WithFallback(fallback)
+			detector.addresses = tt.addresses
+
+			ip, err := detector.GetPublicIPv6(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ip != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, ip)
+			}
+		})
+	}
+
+	t.Run("IPv4 comes from the fallback", func(t *testing.T) {
+		ip, err := NewInterfaceIPDetector("").WithFallback(fallback).GetPublicIP(context.Background())
+		if err != nil || ip != "203.0.113.1" {
+			t.Errorf("expected 203.0.113.1, got %q (%v)", ip, err)
+		}
+	})
+
+	t.Run("error without a fallback", func(t *testing.T) {
+		detector := NewInterfaceIPDetector("wlan0")
+		detector.addresses = sample
+
+		if _, err := detector.GetPublicIPv6(context.Background()); err == nil || !strings.Contains(err.Error(), "wlan0") {
+			t.Errorf("expected an error naming wlan0, got %v", err)
+		}
+	})
+}
+
+func TestServiceUpdateIPUsesStableInterfaceAddress(t *testing.T) {
+	detector := NewInterfaceIPDetector("").
+		WithSkipTemporary(true).
+		WithFallback(&dualStackIPDetector{ipv4: "203.0.113.1", ipv6: "2001:db8::ffff"})
+	detector.addresses = func() ([]interfaceAddress, error) {
+		return parseIfInet6(strings.NewReader(ifInet6Sample))
+	}
+
+	provider := newMockProvider("test")
+	config := Config{Domain: "example.com", RecordType: "AAAA"}
+	service := NewServiceWithIPDetector(provider, config, NewCachedIPDetector(detector, time.Minute))
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := provider.records["example.com:AAAA"]; got != "2001:db8::211:22ff:fe33:4455" {
+		t.Errorf("expected the stable interface address to be published, got %q", got)
+	}
+}
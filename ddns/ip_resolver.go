@@ -0,0 +1,51 @@
+package ddns
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// ResolverIPDetector reports the value DNS currently serves for a record, rather than the public IP
+// Pointed at the zone's authoritative nameserver it shows what a provider actually published,
+// so comparing it with the detected public IP reveals drift
+type ResolverIPDetector struct {
+	domain     string
+	recordType string
+	resolver   Resolver
+}
+
+// NewResolverIPDetector creates a detector that resolves domain's recordType record through nameserver
+// The nameserver is a host or host:port; port 53 is used when none is given, and an empty nameserver
+// uses the system resolver
+func NewResolverIPDetector(domain, recordType, nameserver string) *ResolverIPDetector {
+	return &ResolverIPDetector{
+		domain:     domain,
+		recordType: recordType,
+		resolver:   newNameserverResolver(nameserver),
+	}
+}
+
+// GetPublicIP returns the record value currently published in DNS
+func (d *ResolverIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	return resolveRecord(ctx, d.resolver, d.domain, d.recordType)
+}
+
+// newNameserverResolver returns a resolver that sends every query to nameserver
+func newNameserverResolver(nameserver string) *net.Resolver {
+	if nameserver == "" {
+		return net.DefaultResolver
+	}
+
+	if _, _, err := net.SplitHostPort(nameserver); err != nil {
+		nameserver = net.JoinHostPort(nameserver, "53")
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: 5 * time.Second}
+			return dialer.DialContext(ctx, network, nameserver)
+		},
+	}
+}
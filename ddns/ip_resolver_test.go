@@ -0,0 +1,42 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResolverIPDetector(t *testing.T) {
+	detector := NewResolverIPDetector("home.example.com", "AAAA", "ns1.example.com")
+	detector.resolver = staticResolver{hosts: map[string][]string{"home.example.com": {"203.0.113.1", "2001:db8::1"}}}
+
+	ip, err := detector.GetPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ip != "2001:db8::1" {
+		t.Errorf("Expected the published AAAA value, got %s", ip)
+	}
+}
+
+func TestServiceUpdateIPWithVerification(t *testing.T) {
+	provider := newMockProvider("test")
+	service := NewServiceWithIPDetector(provider, Config{Domain: "home.example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	verifier := NewResolverIPDetector("home.example.com", "A", "")
+	verifier.resolver = staticResolver{hosts: map[string][]string{"home.example.com": {"203.0.113.1"}}}
+
+	if _, err := service.UpdateIPWithVerification(context.Background(), verifier); err != nil {
+		t.Fatalf("Expected verified update, got %v", err)
+	}
+
+	// DNS still serving an old address is reported as drift
+	verifier.resolver = staticResolver{hosts: map[string][]string{"home.example.com": {"198.51.100.1"}}}
+	resp, err := service.UpdateIPWithVerification(context.Background(), verifier)
+	if !errors.Is(err, ErrRecordMismatch) {
+		t.Fatalf("Expected ErrRecordMismatch, got %v", err)
+	}
+	if resp == nil {
+		t.Error("Expected the update response alongside the verification error")
+	}
+}
@@ -0,0 +1,231 @@
+package ddns
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DefaultSTUNServer is the STUN server used when none is configured
+const DefaultSTUNServer = "stun.l.google.com:19302"
+
+// STUN message constants from RFC 5389
+const (
+	stunBindingRequest       = 0x0001
+	stunBindingSuccess       = 0x0101
+	stunMagicCookie          = 0x2112A442
+	stunHeaderSize           = 20
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXORMappedAddress = 0x0020
+	stunFamilyIPv4           = 0x01
+	stunFamilyIPv6           = 0x02
+)
+
+// STUNIPDetector implements IPDetector by querying a STUN server for the mapped address
+type STUNIPDetector struct {
+	server       string
+	timeout      time.Duration
+	fallback     IPDetector
+	allowPrivate bool
+}
+
+// NewSTUNIPDetector creates a STUN-based IP detector
+func NewSTUNIPDetector(server string, timeout time.Duration) *STUNIPDetector {
+	if server == "" {
+		server = DefaultSTUNServer
+	}
+
+	return &STUNIPDetector{
+		server:  server,
+		timeout: timeout,
+	}
+}
+
+// WithFallback sets a detector used when the STUN query fails
+func (d *STUNIPDetector) WithFallback(fallback IPDetector) *STUNIPDetector {
+	d.fallback = fallback
+	return d
+}
+
+// WithAllowPrivateIP sets whether private addresses are returned instead of ErrPrivateIP
+func (d *STUNIPDetector) WithAllowPrivateIP(allow bool) *STUNIPDetector {
+	d.allowPrivate = allow
+	return d
+}
+
+// GetPublicIP retrieves the public IP address as seen by the STUN server
+// An unusable address, such as a private one, is treated like a failed query
+func (d *STUNIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	ip, err := d.query(ctx)
+	if err == nil {
+		err = validateDetectedIP(ip, d.allowPrivate)
+	}
+	if err != nil {
+		if d.fallback != nil {
+			return d.fallback.GetPublicIP(ctx)
+		}
+		return "", err
+	}
+
+	return ip, nil
+}
+
+// GetPublicIPv6 delegates to the fallback detector, since STUN reports whichever family the server is reached over
+func (d *STUNIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	if detector, ok := d.fallback.(IPv6Detector); ok {
+		return detector.GetPublicIPv6(ctx)
+	}
+	return "", fmt.Errorf("STUN detection cannot detect IPv6 addresses without an HTTP fallback")
+}
+
+// query sends a single STUN binding request and parses the mapped address from the response
+func (d *STUNIPDetector) query(ctx context.Context) (string, error) {
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", d.server)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to STUN server %s: %w", d.server, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	request, transactionID, err := newSTUNBindingRequest()
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := conn.Write(request); err != nil {
+		return "", fmt.Errorf("failed to send STUN request: %w", err)
+	}
+
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return "", fmt.Errorf("failed to read STUN response: %w", err)
+	}
+
+	ip, err := parseSTUNBindingResponse(buf[:n], transactionID)
+	if err != nil {
+		return "", err
+	}
+
+	return ip.String(), nil
+}
+
+// newSTUNBindingRequest builds a binding request with a random transaction ID
+func newSTUNBindingRequest() ([]byte, []byte, error) {
+	request := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(request[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint16(request[2:4], 0) // No attributes
+	binary.BigEndian.PutUint32(request[4:8], stunMagicCookie)
+
+	transactionID := request[8:20]
+	if _, err := rand.Read(transactionID); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate STUN transaction ID: %w", err)
+	}
+
+	return request, transactionID, nil
+}
+
+// parseSTUNBindingResponse extracts the (XOR-)MAPPED-ADDRESS from a binding success response
+func parseSTUNBindingResponse(msg, transactionID []byte) (net.IP, error) {
+	if len(msg) < stunHeaderSize {
+		return nil, fmt.Errorf("STUN response too short: %d bytes", len(msg))
+	}
+
+	if binary.BigEndian.Uint16(msg[0:2]) != stunBindingSuccess {
+		return nil, fmt.Errorf("unexpected STUN message type: 0x%04x", binary.BigEndian.Uint16(msg[0:2]))
+	}
+
+	if binary.BigEndian.Uint32(msg[4:8]) != stunMagicCookie {
+		return nil, fmt.Errorf("invalid STUN magic cookie")
+	}
+
+	if string(msg[8:20]) != string(transactionID) {
+		return nil, fmt.Errorf("STUN transaction ID mismatch")
+	}
+
+	length := int(binary.BigEndian.Uint16(msg[2:4]))
+	if stunHeaderSize+length > len(msg) {
+		return nil, fmt.Errorf("truncated STUN response")
+	}
+
+	var mapped net.IP
+	attrs := msg[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			return nil, fmt.Errorf("truncated STUN attribute")
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXORMappedAddress:
+			// XOR-MAPPED-ADDRESS is preferred since NATs can't rewrite it
+			return decodeSTUNAddress(value, msg[4:20])
+		case stunAttrMappedAddress:
+			ip, err := decodeSTUNAddress(value, nil)
+			if err == nil {
+				mapped = ip
+			}
+		}
+
+		// Attributes are padded to a multiple of 4 bytes
+		padded := (attrLen + 3) &^ 3
+		if 4+padded > len(attrs) {
+			break
+		}
+		attrs = attrs[4+padded:]
+	}
+
+	if mapped == nil {
+		return nil, fmt.Errorf("no mapped address in STUN response")
+	}
+
+	return mapped, nil
+}
+
+// decodeSTUNAddress decodes an address attribute, XOR-ing it with the key when given
+func decodeSTUNAddress(value, xorKey []byte) (net.IP, error) {
+	if len(value) < 4 {
+		return nil, fmt.Errorf("invalid STUN address attribute")
+	}
+
+	var size int
+	switch value[1] {
+	case stunFamilyIPv4:
+		size = net.IPv4len
+	case stunFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return nil, fmt.Errorf("unknown STUN address family: %d", value[1])
+	}
+
+	if len(value) < 4+size {
+		return nil, fmt.Errorf("invalid STUN address attribute")
+	}
+
+	ip := make(net.IP, size)
+	copy(ip, value[4:4+size])
+
+	// The key is the magic cookie followed by the transaction ID
+	if xorKey != nil {
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+
+	return ip, nil
+}
@@ -0,0 +1,118 @@
+package ddns
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+// startSTUNServer runs a minimal STUN server that reports the given address
+func startSTUNServer(t *testing.T, mapped net.IP) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to start STUN server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize {
+				continue
+			}
+
+			ip := mapped.To4()
+			value := make([]byte, 8)
+			value[1] = stunFamilyIPv4
+			binary.BigEndian.PutUint16(value[2:4], 54321^(stunMagicCookie>>16))
+			for i := range ip {
+				value[4+i] = ip[i] ^ buf[4+i]
+			}
+
+			response := make([]byte, stunHeaderSize+4+len(value))
+			binary.BigEndian.PutUint16(response[0:2], stunBindingSuccess)
+			binary.BigEndian.PutUint16(response[2:4], uint16(4+len(value)))
+			copy(response[4:20], buf[4:20])
+			binary.BigEndian.PutUint16(response[20:22], stunAttrXORMappedAddress)
+			binary.BigEndian.PutUint16(response[22:24], uint16(len(value)))
+			copy(response[24:], value)
+
+			conn.WriteTo(response, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestSTUNIPDetector(t *testing.T) {
+	server := startSTUNServer(t, net.ParseIP("203.0.113.7"))
+	detector := NewSTUNIPDetector(server, time.Second)
+
+	ip, err := detector.GetPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if ip != "203.0.113.7" {
+		t.Errorf("Expected 203.0.113.7, got %s", ip)
+	}
+}
+
+func TestSTUNIPDetectorFallback(t *testing.T) {
+	// Nothing answers on this socket, so the STUN query times out
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to reserve UDP port: %v", err)
+	}
+	defer conn.Close()
+
+	fallback := &mockIPDetector{ip: "198.51.100.1"}
+	detector := NewSTUNIPDetector(conn.LocalAddr().String(), 50*time.Millisecond).WithFallback(fallback)
+
+	ip, err := detector.GetPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+
+	if ip != "198.51.100.1" {
+		t.Errorf("Expected fallback IP 198.51.100.1, got %s", ip)
+	}
+}
+
+func TestParseSTUNBindingResponseRejectsWrongTransaction(t *testing.T) {
+	request, _, err := newSTUNBindingRequest()
+	if err != nil {
+		t.Fatalf("failed to build request: %v", err)
+	}
+
+	response := make([]byte, stunHeaderSize)
+	copy(response, request)
+	binary.BigEndian.PutUint16(response[0:2], stunBindingSuccess)
+
+	if _, err := parseSTUNBindingResponse(response, make([]byte, 12)); err == nil {
+		t.Error("Expected error for mismatched transaction ID")
+	}
+}
+
+func TestNewIPDetector(t *testing.T) {
+	httpDetector := &HTTPIPDetector{}
+
+	if NewIPDetector(IPDetectionHTTP, httpDetector, false) != httpDetector {
+		t.Error("Expected HTTP detection to use the HTTP detector")
+	}
+
+	if _, ok := NewIPDetector(IPDetectionSTUN, httpDetector, false).(*STUNIPDetector); !ok {
+		t.Error("Expected STUN detection to use a STUNIPDetector")
+	}
+
+	detector, ok := NewIPDetector(IPDetectionSTUNWithHTTPFallback, httpDetector, false).(*STUNIPDetector)
+	if !ok || detector.fallback != httpDetector {
+		t.Error("Expected STUN detection with the HTTP detector as fallback")
+	}
+}
@@ -0,0 +1,255 @@
+package ddns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestValidatePublicIP(t *testing.T) {
+	tests := []struct {
+		ip          string
+		wantErr     bool
+		wantPrivate bool
+	}{
+		{"203.0.113.10", false, false},
+		{"2001:db8::1", false, false},
+		{"10.0.0.1", true, true},
+		{"172.16.5.4", true, true},
+		{"172.32.0.1", false, false},
+		{"192.168.1.1", true, true},
+		{"fd00::1", true, true},
+		{"127.0.0.1", true, false},
+		{"::1", true, false},
+		{"169.254.1.1", true, false},
+		{"fe80::1", true, false},
+		{"224.0.0.1", true, false},
+		{"0.0.0.0", true, false},
+		{"::", true, false},
+		{"not-an-ip", true, false},
+	}
+
+	for _, tt := range tests {
+		err := ValidatePublicIP(tt.ip)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ValidatePublicIP(%q) error = %v, wantErr %v", tt.ip, err, tt.wantErr)
+		}
+		if errors.Is(err, ErrPrivateIP) != tt.wantPrivate {
+			t.Errorf("ValidatePublicIP(%q) = %v, want ErrPrivateIP: %v", tt.ip, err, tt.wantPrivate)
+		}
+	}
+}
+
+func TestValidateDetectedIPAllowPrivate(t *testing.T) {
+	if err := validateDetectedIP("192.168.1.1", true); err != nil {
+		t.Errorf("Expected private IP to be allowed, got %v", err)
+	}
+
+	// The escape hatch only covers private space
+	if err := validateDetectedIP("127.0.0.1", true); err == nil {
+		t.Error("Expected loopback to be rejected even when private IPs are allowed")
+	}
+}
+
+func TestHTTPIPDetectorGetPublicIPv6(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "2001:db8::1")
+	}))
+	server.Listener = listener
+	server.Start()
+	defer server.Close()
+
+	detector := NewHTTPIPDetector(server.Client(), executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())), IPFamilyAny)
+	detector.ipv6URL = server.URL
+
+	ip, err := detector.GetPublicIPv6(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ip != "2001:db8::1" {
+		t.Errorf("Expected 2001:db8::1, got %s", ip)
+	}
+
+	// An IPv6 detector's GetPublicIP uses the IPv6-only lookup rather than httpbin.org
+	detector = NewHTTPIPDetector(server.Client(), executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())), IPFamilyV6)
+	detector.ipv6URL = server.URL
+	if ip, err := detector.GetPublicIP(context.Background()); err != nil || ip != "2001:db8::1" {
+		t.Errorf("Expected 2001:db8::1 from GetPublicIP, got %q, %v", ip, err)
+	}
+}
+
+func TestHTTPIPDetectorEnforcesFamily(t *testing.T) {
+	origin := "203.0.113.1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"origin":%q}`, origin)
+	}))
+	defer server.Close()
+
+	exec := executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+	newDetector := func(family IPFamily) *HTTPIPDetector {
+		detector := NewHTTPIPDetector(server.Client(), exec, family)
+		detector.lookupURL = server.URL
+		detector.ipv6URL = server.URL
+		return detector
+	}
+
+	ip, err := newDetector(IPFamilyV4).GetPublicIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if ip != "203.0.113.1" {
+		t.Errorf("Expected 203.0.113.1, got %s", ip)
+	}
+
+	// The server only listens on IPv4, so an IPv6-only detector can't reach it
+	if _, err := newDetector(IPFamilyV6).GetPublicIP(context.Background()); err == nil {
+		t.Error("Expected IPv6-only detector to fail connecting over IPv4")
+	}
+
+	origin = "2001:db8::1"
+	if _, err := newDetector(IPFamilyV4).GetPublicIP(context.Background()); err == nil {
+		t.Error("Expected error for an IPv6 address from an IPv4 detector")
+	}
+	if ip, err := newDetector(IPFamilyAny).GetPublicIP(context.Background()); err != nil || ip != "2001:db8::1" {
+		t.Errorf("Expected any family to be accepted, got %q, %v", ip, err)
+	}
+}
+
+func TestIPFamilyForRecordType(t *testing.T) {
+	for recordType, want := range map[string]IPFamily{"A": IPFamilyV4, "aaaa": IPFamilyV6, "TXT": IPFamilyAny} {
+		if got := IPFamilyForRecordType(recordType); got != want {
+			t.Errorf("IPFamilyForRecordType(%q) = %d, want %d", recordType, got, want)
+		}
+	}
+}
+
+// sequenceIPDetector returns its addresses in turn and counts lookups
+type sequenceIPDetector struct {
+	ips   []string
+	calls int
+}
+
+func (d *sequenceIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	ip := d.ips[d.calls%len(d.ips)]
+	d.calls++
+	return ip, nil
+}
+
+func TestCachedIPDetector(t *testing.T) {
+	inner := &sequenceIPDetector{ips: []string{"203.0.113.1", "203.0.113.2"}}
+	detector := NewCachedIPDetector(inner, time.Minute)
+	now := time.Now()
+	detector.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ip, _ := detector.GetPublicIP(ctx); ip != "203.0.113.1" {
+			t.Errorf("Expected cached 203.0.113.1, got %s", ip)
+		}
+	}
+	if inner.calls != 1 {
+		t.Errorf("Expected a single lookup within the TTL, got %d", inner.calls)
+	}
+
+	// Once the TTL passes the new address replaces the cached one
+	now = now.Add(time.Minute)
+	if ip, _ := detector.GetPublicIP(ctx); ip != "203.0.113.2" || inner.calls != 2 {
+		t.Errorf("Expected fresh lookup of 203.0.113.2, got %s after %d lookups", ip, inner.calls)
+	}
+
+	detector.Invalidate()
+	if detector.GetPublicIP(ctx); inner.calls != 3 {
+		t.Errorf("Expected a lookup after Invalidate, got %d lookups", inner.calls)
+	}
+
+	if _, err := detector.GetPublicIPv6(ctx); err == nil {
+		t.Error("Expected error detecting IPv6 through an IPv4-only detector")
+	}
+}
+
+func TestServiceForceUpdateBypassesIPCache(t *testing.T) {
+	inner := &sequenceIPDetector{ips: []string{"203.0.113.1", "203.0.113.2"}}
+	provider := newMockProvider("test")
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}, NewCachedIPDetector(inner, time.Hour))
+
+	for _, want := range []string{"203.0.113.1", "203.0.113.2"} {
+		if _, err := service.UpdateIP(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got := provider.records["example.com:A"]; got != want {
+			t.Errorf("Expected forced update to publish %s, got %s", want, got)
+		}
+	}
+}
+
+// taggingTransport wraps another transport, marking the requests it passes on
+type taggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *taggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Tagged", "yes")
+	return t.next.RoundTrip(req)
+}
+
+func (t *taggingTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+func (t *taggingTransport) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &taggingTransport{next: next}
+}
+
+func TestFamilyClientKeepsWrappingTransports(t *testing.T) {
+	var tagged string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tagged = r.Header.Get("X-Tagged")
+	}))
+	defer server.Close()
+
+	client := familyClient(&http.Client{Transport: &taggingTransport{next: http.DefaultTransport}}, IPFamilyV4)
+	wrapper, ok := client.Transport.(*taggingTransport)
+	if !ok {
+		t.Fatalf("Expected the wrapping transport to be kept, got %T", client.Transport)
+	}
+	if wrapper.next == http.DefaultTransport {
+		t.Error("Expected the wrapped transport to be replaced by a family-restricted copy")
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+	if tagged != "yes" {
+		t.Error("Expected requests to pass through the wrapping transport")
+	}
+}
+
+func TestFamilyClientBypassesProxy(t *testing.T) {
+	proxied := http.DefaultTransport.(*http.Transport).Clone()
+	proxied.Proxy = http.ProxyURL(&url.URL{Scheme: "http", Host: "proxy.internal:3128"})
+
+	client := familyClient(&http.Client{Transport: &taggingTransport{next: proxied}}, IPFamilyV6)
+	base := client.Transport.(*taggingTransport).next.(*http.Transport)
+	if base.Proxy != nil {
+		t.Error("Expected family-restricted detection to connect directly")
+	}
+	if proxied.Proxy == nil {
+		t.Error("Expected the original transport to keep its proxy")
+	}
+}
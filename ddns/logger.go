@@ -0,0 +1,18 @@
+package ddns
+
+// Logger receives the service's log lines as a message followed by alternating keys and values
+// Its methods match those of *slog.Logger, which can be passed to Service.WithLogger as is
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// noopLogger discards everything; services log nothing until given a Logger
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, args ...interface{}) {}
+func (noopLogger) Info(msg string, args ...interface{})  {}
+func (noopLogger) Warn(msg string, args ...interface{})  {}
+func (noopLogger) Error(msg string, args ...interface{}) {}
@@ -0,0 +1,116 @@
+package ddns
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// metricsWindowSize is how many recent update latencies a Service keeps by default (see Service.WithMetricsWindow)
+const metricsWindowSize = 1000
+
+// ServiceMetrics summarizes the updates a Service has run, for status endpoints and log lines
+type ServiceMetrics struct {
+	UpdateCount    int64   `json:"update_count"`
+	SuccessCount   int64   `json:"success_count"`
+	FailureCount   int64   `json:"failure_count"`
+	SuccessRate    float64 `json:"success_rate"`     // Fraction of updates that succeeded, zero before the first
+	MeanLatencyMS  float64 `json:"mean_latency_ms"`  // Over the most recent updates
+	P99LatencyMS   float64 `json:"p99_latency_ms"`   // Over the most recent updates
+	TotalIPChanges int64   `json:"total_ip_changes"` // Records whose value an update actually changed
+}
+
+// updateMetrics counts updates and keeps their recent latencies in a circular buffer, safe for concurrent use
+type updateMetrics struct {
+	updates   atomic.Int64
+	successes atomic.Int64
+	failures  atomic.Int64
+	ipChanges atomic.Int64
+
+	mu        sync.Mutex
+	latencies []float64 // Milliseconds
+	next      int       // Index the next latency is written to once the buffer is full
+}
+
+// newUpdateMetrics creates metrics keeping the latencies of the last size updates
+func newUpdateMetrics(size int) *updateMetrics {
+	return &updateMetrics{latencies: make([]float64, 0, size)}
+}
+
+// observe records an update that took latency
+func (m *updateMetrics) observe(latency time.Duration, failed bool) {
+	m.updates.Add(1)
+	if failed {
+		m.failures.Add(1)
+	} else {
+		m.successes.Add(1)
+	}
+
+	ms := float64(latency) / float64(time.Millisecond)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.latencies) < cap(m.latencies) {
+		m.latencies = append(m.latencies, ms)
+		return
+	}
+	m.latencies[m.next] = ms
+	m.next = (m.next + 1) % len(m.latencies)
+}
+
+// snapshot returns the current metrics
+func (m *updateMetrics) snapshot() ServiceMetrics {
+	metrics := ServiceMetrics{
+		UpdateCount:    m.updates.Load(),
+		SuccessCount:   m.successes.Load(),
+		FailureCount:   m.failures.Load(),
+		TotalIPChanges: m.ipChanges.Load(),
+	}
+	if metrics.UpdateCount > 0 {
+		metrics.SuccessRate = float64(metrics.SuccessCount) / float64(metrics.UpdateCount)
+	}
+
+	m.mu.Lock()
+	latencies := append([]float64(nil), m.latencies...)
+	m.mu.Unlock()
+
+	if len(latencies) == 0 {
+		return metrics
+	}
+
+	sum := 0.0
+	for _, latency := range latencies {
+		sum += latency
+	}
+	metrics.MeanLatencyMS = sum / float64(len(latencies))
+
+	// Nearest-rank percentile: the smallest latency at least 99% of updates didn't exceed
+	sort.Float64s(latencies)
+	rank := int(math.Ceil(0.99 * float64(len(latencies))))
+	metrics.P99LatencyMS = latencies[rank-1]
+
+	return metrics
+}
+
+// Metrics returns the update counts and latencies of UpdateIP, UpdateRecord and UpdateAll calls
+func (s *Service) Metrics() ServiceMetrics {
+	return s.metrics.snapshot()
+}
+
+// WithMetricsWindow sets how many recent updates the latency metrics are computed over, resetting the metrics
+func (s *Service) WithMetricsWindow(size int) *Service {
+	if size <= 0 {
+		size = metricsWindowSize
+	}
+	s.metrics = newUpdateMetrics(size)
+	return s
+}
+
+// observeUpdate records an update started at started, deferred with pointers to its results
+// Partial failures return an unsuccessful response without an error, so both count as failures
+func (s *Service) observeUpdate(started time.Time, resp **UpdateResponse, err *error) {
+	s.metrics.observe(time.Since(started), *err != nil || *resp == nil || !(*resp).Success)
+}
@@ -0,0 +1,86 @@
+package ddns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestUpdateMetricsLatencies(t *testing.T) {
+	metrics := newUpdateMetrics(metricsWindowSize)
+	for i := 1; i <= 200; i++ {
+		metrics.observe(time.Duration(i)*time.Millisecond, i%4 == 0)
+	}
+
+	got := metrics.snapshot()
+	if got.UpdateCount != 200 || got.SuccessCount != 150 || got.FailureCount != 50 || got.SuccessRate != 0.75 {
+		t.Errorf("Unexpected counts %+v", got)
+	}
+	if got.MeanLatencyMS != 100.5 || got.P99LatencyMS != 198 {
+		t.Errorf("Expected mean 100.5ms and p99 198ms, got %vms and %vms", got.MeanLatencyMS, got.P99LatencyMS)
+	}
+}
+
+func TestUpdateMetricsKeepsRecentLatencies(t *testing.T) {
+	metrics := newUpdateMetrics(3)
+	for _, ms := range []time.Duration{500, 1, 2, 3} {
+		metrics.observe(ms*time.Millisecond, false)
+	}
+
+	got := metrics.snapshot()
+	if got.UpdateCount != 4 || got.MeanLatencyMS != 2 || got.P99LatencyMS != 3 {
+		t.Errorf("Expected the oldest latency to be dropped, got %+v", got)
+	}
+	if empty := newUpdateMetrics(3).snapshot(); empty != (ServiceMetrics{}) {
+		t.Errorf("Expected zero metrics before any update, got %+v", empty)
+	}
+}
+
+func TestServiceMetrics(t *testing.T) {
+	provider := newMockProvider("test")
+	detector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, detector)
+	ctx := context.Background()
+
+	service.UpdateIP(ctx) // Changes the record
+	service.UpdateIP(ctx) // Already up to date
+	detector.shouldFail = true
+	service.UpdateIP(ctx)
+	service.UpdateRecord(ctx, "203.0.113.2")
+
+	got := service.Metrics()
+	if got.UpdateCount != 4 || got.SuccessCount != 3 || got.FailureCount != 1 || got.TotalIPChanges != 2 {
+		t.Errorf("Unexpected metrics %+v", got)
+	}
+}
+
+func TestServiceMetricsCountPartialFailures(t *testing.T) {
+	broken := newMockProvider("broken")
+	broken.shouldFail = true
+	service := NewMultiProviderService([]Provider{broken, newMockProvider("healthy")}, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	// A mirror failing on every cycle is reported without an error but must not look healthy
+	if resp, err := service.UpdateIP(context.Background()); err != nil || resp.Success {
+		t.Fatalf("Expected a partial failure, got %+v and %v", resp, err)
+	}
+
+	got := service.Metrics()
+	if got.UpdateCount != 1 || got.FailureCount != 1 || got.SuccessRate != 0 {
+		t.Errorf("Expected the partial failure to count as failed, got %+v", got)
+	}
+}
+
+func TestServiceWithMetricsWindow(t *testing.T) {
+	service := NewServiceWithIPDetector(newMockProvider("test"), Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"}).
+		WithMetricsWindow(2)
+	for i := 0; i < 3; i++ {
+		service.UpdateIP(context.Background())
+	}
+
+	if size := len(service.metrics.latencies); size != 2 {
+		t.Errorf("Expected latencies of the last 2 updates, got %d", size)
+	}
+	if got := service.Metrics(); got.UpdateCount != 3 {
+		t.Errorf("Expected every update to be counted, got %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package ddns
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// recordCache remembers the record values a Service last read from its providers, safe for concurrent use
+// It saves a provider round-trip on most update checks; entries expire after ttl or when the record is pushed
+type recordCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[recordCacheKey]recordCacheEntry
+}
+
+// recordCacheKey identifies a record of one provider
+type recordCacheKey struct {
+	provider   Provider
+	domain     string
+	recordType string
+}
+
+// recordCacheEntry is a cached record value and when it was read
+type recordCacheEntry struct {
+	value  string
+	readAt time.Time
+}
+
+// newRecordCache creates a cache keeping values for ttl; a non-positive ttl disables it
+func newRecordCache(ttl time.Duration) *recordCache {
+	return &recordCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[recordCacheKey]recordCacheEntry),
+	}
+}
+
+// key returns the cache key of a record, or false if the provider can't be used as a map key
+func (c *recordCache) key(provider Provider, domain, recordType string) (recordCacheKey, bool) {
+	if c.ttl <= 0 || !reflect.TypeOf(provider).Comparable() {
+		return recordCacheKey{}, false
+	}
+	return recordCacheKey{provider: provider, domain: domain, recordType: recordType}, true
+}
+
+// get returns the cached value of a record while it's fresh
+func (c *recordCache) get(provider Provider, domain, recordType string) (string, bool) {
+	key, ok := c.key(provider, domain, recordType)
+	if !ok {
+		return "", false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || c.now().Sub(entry.readAt) >= c.ttl {
+		return "", false
+	}
+	return entry.value, true
+}
+
+// store caches the value just read for a record
+func (c *recordCache) store(provider Provider, domain, recordType, value string) {
+	key, ok := c.key(provider, domain, recordType)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = recordCacheEntry{value: value, readAt: c.now()}
+}
+
+// invalidate forgets a record, e.g. because it was just pushed
+func (c *recordCache) invalidate(provider Provider, domain, recordType string) {
+	key, ok := c.key(provider, domain, recordType)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// clear forgets every record
+func (c *recordCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	clear(c.entries)
+}
+
+// InvalidateCaches forgets cached detected addresses and record values, so the next update reads both afresh
+func (s *Service) InvalidateCaches() {
+	if cached, ok := s.ipDetector.(*CachedIPDetector); ok {
+		cached.Invalidate()
+	}
+	s.recordCache.clear()
+}
+
+// cachedRecord returns the provider's current record, from the record cache while it's fresh
+func (s *Service) cachedRecord(ctx context.Context, provider Provider, domain, recordType string) (string, error) {
+	if value, ok := s.recordCache.get(provider, domain, recordType); ok {
+		return value, nil
+	}
+
+	value, err := s.currentRecord(ctx, provider, domain, recordType)
+	if err == nil && value != "" {
+		s.recordCache.store(provider, domain, recordType, value)
+	}
+	return value, err
+}
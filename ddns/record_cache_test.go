@@ -0,0 +1,77 @@
+package ddns
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// readCountingProvider counts GetCurrentRecord calls
+type readCountingProvider struct {
+	*mockProvider
+	reads int
+}
+
+func (p *readCountingProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error) {
+	p.reads++
+	return p.mockProvider.GetCurrentRecord(ctx, domain, recordType)
+}
+
+func TestServiceCachesCurrentRecord(t *testing.T) {
+	provider := &readCountingProvider{mockProvider: newMockProvider("counting")}
+	provider.records["example.com:A"] = "203.0.113.1"
+	detector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A", RecordCacheTTL: time.Minute}, detector)
+
+	now := time.Now()
+	service.recordCache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range 3 {
+		if _, err := service.UpdateIP(ctx); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if provider.reads != 1 {
+		t.Errorf("Expected the record to be read once while cached, got %d reads", provider.reads)
+	}
+
+	// The cached value shows the record is stale, and the push forgets it so the next check reads the new record
+	detector.ip = "203.0.113.2"
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.records["example.com:A"] != "203.0.113.2" {
+		t.Fatalf("Expected the record to be updated, got %s", provider.records["example.com:A"])
+	}
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.reads != 2 {
+		t.Errorf("Expected a read after the push, got %d reads", provider.reads)
+	}
+
+	// Entries expire after the TTL
+	now = now.Add(time.Minute)
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.reads != 3 {
+		t.Errorf("Expected an expired entry to be read again, got %d reads", provider.reads)
+	}
+}
+
+func TestServiceRecordCacheDisabledByDefault(t *testing.T) {
+	provider := &readCountingProvider{mockProvider: newMockProvider("counting")}
+	provider.records["example.com:A"] = "203.0.113.1"
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	for range 2 {
+		if _, err := service.UpdateIP(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	if provider.reads != 2 {
+		t.Errorf("Expected every check to read the record, got %d reads", provider.reads)
+	}
+}
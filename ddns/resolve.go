@@ -0,0 +1,49 @@
+package ddns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Resolver looks records up through DNS; *net.Resolver implements it
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+}
+
+// resolveRecord reads the published value of a record through DNS
+// Resolvers may serve cached answers, so a stale value only causes a redundant update
+func resolveRecord(ctx context.Context, resolver Resolver, domain, recordType string) (string, error) {
+	switch recordType {
+	case "TXT":
+		values, err := resolver.LookupTXT(ctx, domain)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", domain, err)
+		}
+		if len(values) > 0 {
+			return values[0], nil
+		}
+	case "CNAME":
+		target, err := resolver.LookupCNAME(ctx, domain)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", domain, err)
+		}
+		return strings.TrimSuffix(target, "."), nil
+	default:
+		addresses, err := resolver.LookupHost(ctx, domain)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve %s: %w", domain, err)
+		}
+		for _, address := range addresses {
+			ip := net.ParseIP(address)
+			if ip != nil && (recordType == "AAAA") == (ip.To4() == nil) {
+				return address, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no %s record found for %s", recordType, domain)
+}
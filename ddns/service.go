@@ -2,7 +2,16 @@ package ddns
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/jq1836/DDNS/executor"
 )
 
 // UpdateRequest represents a DDNS update request
@@ -11,6 +20,40 @@ type UpdateRequest struct {
 	RecordType string // A, AAAA, CNAME, etc.
 	Value      string // IP address or target value
 	TTL        int    // Time to live in seconds
+	IPv6       string // Optional IPv6 address for dual-stack updates alongside an A record
+
+	// SRVValue holds the fields of an SRV record; providers use it instead of Value when set
+	SRVValue *SRVValue
+}
+
+// SRVValue holds the data of an SRV record
+type SRVValue struct {
+	Priority int
+	Weight   int
+	Port     int
+	Target   string
+}
+
+// FormatSRV formats the record data as "priority weight port target", as in RFC 2782
+func (v SRVValue) FormatSRV() string {
+	return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+}
+
+// Validate checks that the fields fit their 16-bit wire format and the target is set
+func (v SRVValue) Validate() error {
+	if v.Port < 1 || v.Port > 65535 {
+		return fmt.Errorf("SRV port must be between 1 and 65535, got %d", v.Port)
+	}
+	if v.Priority < 0 || v.Priority > 65535 {
+		return fmt.Errorf("SRV priority must be between 0 and 65535, got %d", v.Priority)
+	}
+	if v.Weight < 0 || v.Weight > 65535 {
+		return fmt.Errorf("SRV weight must be between 0 and 65535, got %d", v.Weight)
+	}
+	if v.Target == "" {
+		return fmt.Errorf("SRV target is required")
+	}
+	return nil
 }
 
 // UpdateResponse represents the response from a DDNS update
@@ -19,110 +62,1144 @@ type UpdateResponse struct {
 	Message   string
 	RecordID  string // Provider-specific record identifier
 	UpdatedAt time.Time
+
+	// Results holds per-provider outcomes when the service updates several providers
+	Results []ProviderResult
+
+	// PropagationChecked is set when Config.VerifyPropagation polled DNS after the update, and
+	// PropagationConfirmed when DNS served every changed record before Config.PropagationTimeout
+	PropagationChecked   bool
+	PropagationConfirmed bool
+
+	// Unchanged is set by providers that report the record already held the value, and PreviousIP
+	// by providers that report the address the record held before the update
+	Unchanged  bool
+	PreviousIP string
+}
+
+// ProviderResult is the outcome of updating a single provider
+type ProviderResult struct {
+	Provider   string
+	Domain     string
+	RecordType string
+	Response   *UpdateResponse
+	Err        error
+	Changed    bool // Whether the provider's record was actually updated
+	RolledBack bool // Whether UpdateAll restored the previous value after another record failed
 }
 
 // Provider defines the interface that all DDNS providers must implement
 type Provider interface {
 	// UpdateRecord updates a DNS record for the given domain
 	UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error)
-	
+
 	// GetCurrentRecord retrieves the current DNS record value
 	GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error)
-	
+
 	// ValidateCredentials checks if the provider credentials are valid
 	ValidateCredentials(ctx context.Context) error
-	
+
 	// GetProviderName returns the name of the DDNS provider
 	GetProviderName() string
+
+	// Capabilities reports what the provider supports
+	Capabilities() Capabilities
+}
+
+// Capabilities describes what a provider supports, so the service can adapt to it
+type Capabilities struct {
+	SupportsRead  bool `json:"supports_read"` // Whether GetCurrentRecord can read records; DNS resolution is used instead when false
+	SupportsAAAA  bool `json:"supports_aaaa"`
+	SupportsTXT   bool `json:"supports_txt"`
+	SupportsCNAME bool `json:"supports_cname"`
+	SupportsSRV   bool `json:"supports_srv"`
+}
+
+// String formats the capabilities as key=value pairs for logging
+func (c Capabilities) String() string {
+	return fmt.Sprintf("read=%t aaaa=%t txt=%t cname=%t srv=%t", c.SupportsRead, c.SupportsAAAA, c.SupportsTXT, c.SupportsCNAME, c.SupportsSRV)
+}
+
+// RecordClearer is implemented by providers that can remove a record's value, e.g. an AAAA record
+// of a host going from dual-stack to IPv4-only; see Service.ClearRecords
+type RecordClearer interface {
+	Provider
+
+	// ClearRecord removes the value of the domain's record of the given type
+	ClearRecord(ctx context.Context, domain, recordType string) error
+}
+
+// BatchUpdateProvider is implemented by providers that can update several records in one call
+// The service uses it instead of one UpdateRecord call per domain when it is available
+type BatchUpdateProvider interface {
+	Provider
+
+	// UpdateRecords updates every record, returning one response per request in the same order
+	UpdateRecords(ctx context.Context, reqs []UpdateRequest) ([]*UpdateResponse, error)
 }
 
 // IPDetector defines the interface for detecting public IP addresses
 type IPDetector interface {
 	GetPublicIP(ctx context.Context) (string, error)
-}// Config holds configuration for DDNS providers
+}
+
+// Config holds configuration for DDNS providers
 type Config struct {
 	Provider string
+	Username string // Account name for providers that authenticate with a username and token
 	APIKey   string // This will be the token for DuckDNS
 	Domain   string
+	Domains  []string     // Every domain to update; Domain alone is updated when empty
+	Records  []RecordSpec // Fully specified records UpdateIP updates instead of Domains and RecordType
+	ZoneID   string       // Provider-specific zone or domain identifier, if required
+	FilePath string       // Path of the hosts-style file written by the file provider
+	Target   string       // Target hostname published for CNAME records instead of the detected IP
 	TTL      int
 
 	// Additional settings
-	RecordType     string
-	UpdateInterval time.Duration
+	RecordType        string
+	UpdateInterval    time.Duration
+	EventBufferSize   int    // Buffer size of channels returned by Subscribe
+	IPDetectionMethod string // One of the IPDetection* methods, defaults to HTTP
+	AllowPrivateIP    bool   // Publish detected RFC 1918 addresses instead of rejecting them
+	IPv6Interface     string // Interface read by interface detection, or every interface if empty
+	SkipTemporaryIPv6 bool   // Ignore RFC 4941 temporary addresses in interface detection
+	ForceUpdate       bool   // Push every update without checking whether the record already matches
+	ConfirmIPChange   bool   // Re-detect a changed IP with the confirmation detector before publishing it
+
+	// RecordCacheTTL is how long a record value read from a provider is trusted when checking whether
+	// an update is needed; pushing the record forgets it early, and zero disables the cache
+	RecordCacheTTL time.Duration
+
+	// VerifyPropagation polls DNS after an update until it serves the new values or PropagationTimeout
+	// (DefaultPropagationTimeout when zero) passes, reporting the outcome in the UpdateResponse
+	VerifyPropagation  bool
+	PropagationTimeout time.Duration
+
+	// Nameserver is a host or host:port the service's resolver sends every query to; the system resolver when empty
+	Nameserver string
+
+	// Headers are sent with every request to the provider, in addition to User-Agent
+	Headers map[string]string
+
+	// Proxy routes the provider's requests through this http, https or socks5 URL instead of the shared client's proxy
+	Proxy string
+}
+
+// RecordSpec describes one record updated with the detected address of its family
+type RecordSpec struct {
+	Domain     string
+	RecordType string // A or AAAA
+	TTL        int    // Defaults to Config.TTL when zero
 }
 
-// Service manages DDNS updates using the configured provider
+// Service manages DDNS updates using the configured providers
 type Service struct {
-	provider   Provider
+	providers  []Provider
 	config     Config
 	ipDetector IPDetector
+	resolver   Resolver // Reads records of providers that can't read them themselves and verifies propagation
+
+	confirmDetector IPDetector // Independent detector consulted when Config.ConfirmIPChange is set
+
+	propagationDelay time.Duration // First delay between propagation checks, defaults to propagationPollDelay
+
+	logger Logger
+
+	// Event subscriptions
+	mu            sync.Mutex
+	subscribers   []chan UpdateEvent
+	droppedEvents int64
+
+	// Recent update errors, for status reporting
+	errorWindow *errorWindow
+
+	// Record values last read from the providers, see Config.RecordCacheTTL
+	recordCache *recordCache
+
+	// Update counts and latencies, see Metrics
+	metrics *updateMetrics
+
+	// Last successful IP update, for status reporting
+	lastMu         sync.RWMutex
+	lastIP         string
+	lastUpdateTime time.Time
 }
 
 // NewService creates a new DDNS service with the specified provider
+// Detected addresses are cached for half the update interval, when one is set
 func NewService(provider Provider, config Config) *Service {
-	return NewServiceWithIPDetector(provider, config, &HTTPIPDetector{})
+	httpDetector := &HTTPIPDetector{allowPrivate: config.AllowPrivateIP, family: IPFamilyForRecordType(config.RecordType)}
+	ipDetector := NewConfiguredIPDetector(config, httpDetector)
+	if config.UpdateInterval > 0 {
+		ipDetector = NewCachedIPDetector(ipDetector, config.UpdateInterval/2)
+	}
+	return NewServiceWithIPDetector(provider, config, ipDetector)
 }
 
 // NewServiceWithIPDetector creates a new DDNS service with a custom IP detector
 func NewServiceWithIPDetector(provider Provider, config Config, ipDetector IPDetector) *Service {
+	return NewMultiProviderService([]Provider{provider}, config, ipDetector)
+}
+
+// NewMultiProviderService creates a DDNS service that mirrors the record to every given provider
+func NewMultiProviderService(providers []Provider, config Config, ipDetector IPDetector) *Service {
 	return &Service{
-		provider:   provider,
+		providers:  providers,
 		config:     config,
 		ipDetector: ipDetector,
+		resolver:   newNameserverResolver(config.Nameserver),
+		logger:     noopLogger{},
+
+		errorWindow: newErrorWindow(errorWindowSize),
+		recordCache: newRecordCache(config.RecordCacheTTL),
+		metrics:     newUpdateMetrics(metricsWindowSize),
+	}
+}
+
+// WithResolver sets the resolver used to read records of providers without read support
+func (s *Service) WithResolver(resolver Resolver) *Service {
+	s.resolver = resolver
+	return s
+}
+
+// WithLogger sets the logger told about detected addresses and the updates pushed to providers
+func (s *Service) WithLogger(logger Logger) *Service {
+	if logger == nil {
+		logger = noopLogger{}
 	}
+	s.logger = logger
+	return s
+}
+
+// WithConfirmationDetector sets the detector that must agree with a changed IP when Config.ConfirmIPChange is set
+// It should be independent of the service's IP detector, e.g. STUN when detection uses HTTP
+func (s *Service) WithConfirmationDetector(detector IPDetector) *Service {
+	s.confirmDetector = detector
+	return s
 }
 
 // UpdateIP updates the DNS record with the current public IP
-func (s *Service) UpdateIP(ctx context.Context) (*UpdateResponse, error) {
+// Each call is tagged with a request ID (see executor.RequestIDFromContext) unless ctx already has one
+func (s *Service) UpdateIP(ctx context.Context) (resp *UpdateResponse, err error) {
+	defer s.observeUpdate(time.Now(), &resp, &err)
+
+	if executor.RequestIDFromContext(ctx) == "" {
+		ctx = executor.WithRequestID(ctx, executor.NewRequestID())
+	}
+
+	// Forced updates publish a freshly detected address
+	if cached, ok := s.ipDetector.(*CachedIPDetector); ok && s.config.ForceUpdate {
+		cached.Invalidate()
+	}
+
+	if len(s.config.Records) > 0 {
+		return s.updateRecordSpecs(ctx)
+	}
+
+	if resp, ok, err := s.updateNonAddressRecord(ctx); ok {
+		return resp, err
+	}
+
 	// Get current public IP
-	currentIP, err := s.ipDetector.GetPublicIP(ctx)
+	currentIP, err := detectForRecordType(ctx, s.ipDetector, s.config.RecordType)
+	if err == nil {
+		err = s.confirmIPChange(ctx, currentIP)
+	}
+	if err != nil {
+		s.logger.Error("IP detection failed", "error", err)
+		for _, domain := range s.domains() {
+			s.recordError(domain, err)
+		}
+		return nil, err
+	}
+	s.logger.Debug("Detected public IP", "ip", currentIP)
+
+	resp, err = s.updateRecord(ctx, currentIP)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordLastUpdate(currentIP)
+
+	return resp, nil
+}
+
+// updateNonAddressRecord publishes the configured record type when it doesn't hold the detected IP,
+// reporting false for A and AAAA records
+func (s *Service) updateNonAddressRecord(ctx context.Context) (*UpdateResponse, bool, error) {
+	switch s.config.RecordType {
+	case "TXT":
+		// TXT records hold arbitrary values, so there is no IP to detect
+		return nil, true, fmt.Errorf("TXT records require an explicit value, use UpdateRecord instead")
+	case "CNAME":
+		// CNAME records point at the configured target hostname rather than an IP
+		if s.config.Target == "" {
+			return nil, true, fmt.Errorf("CNAME records require a target hostname")
+		}
+		resp, err := s.updateRecord(ctx, s.config.Target)
+		return resp, true, err
+	}
+	return nil, false, nil
+}
+
+// ErrIPChangeUnconfirmed is returned when the confirmation detector disagrees with a changed IP
+var ErrIPChangeUnconfirmed = errors.New("IP change not confirmed")
+
+// confirmIPChange checks ip against the confirmation detector when it differs from the last published IP
+// so a single bad read (e.g. a CGNAT address) doesn't flap the record
+func (s *Service) confirmIPChange(ctx context.Context, ip string) error {
+	lastIP := s.GetCurrentCachedIP()
+	if !s.config.ConfirmIPChange || lastIP == "" || ip == lastIP {
+		return nil
+	}
+	if s.confirmDetector == nil {
+		return fmt.Errorf("confirming IP changes requires a confirmation detector")
+	}
+
+	confirmed, err := detectForRecordType(ctx, s.confirmDetector, s.config.RecordType)
+	if err != nil {
+		return fmt.Errorf("failed to confirm IP change from %s to %s: %w", lastIP, ip, err)
+	}
+	if confirmed != ip {
+		return fmt.Errorf("%w: detected %s but confirmation detector reports %s", ErrIPChangeUnconfirmed, ip, confirmed)
+	}
+
+	return nil
+}
+
+// updateRecordSpecs updates every configured record with the detected address of its family
+// Each family is detected once; a failed detection only fails the records of that family
+func (s *Service) updateRecordSpecs(ctx context.Context) (*UpdateResponse, error) {
+	reqs, reqErrs, addresses := s.recordSpecRequests(ctx, s.config.Records)
+
+	resp, err := s.updateRequests(ctx, reqs, reqErrs)
+	if err != nil {
+		return nil, err
+	}
+
+	s.recordDetectedAddress(addresses)
+
+	return resp, nil
+}
+
+// recordSpecRequests builds one request per record, detecting each address family once
+// The detection error of a record's family is returned at the record's index
+func (s *Service) recordSpecRequests(ctx context.Context, records []RecordSpec) ([]UpdateRequest, []error, map[string]string) {
+	addresses := make(map[string]string)
+	detectErrs := make(map[string]error)
+
+	reqs := make([]UpdateRequest, len(records))
+	reqErrs := make([]error, len(records))
+	for i, record := range records {
+		if _, detected := addresses[record.RecordType]; !detected {
+			addresses[record.RecordType], detectErrs[record.RecordType] = s.detectAddress(ctx, record.RecordType)
+		}
+
+		ttl := record.TTL
+		if ttl == 0 {
+			ttl = s.config.TTL
+		}
+
+		reqs[i] = UpdateRequest{
+			Domain:     record.Domain,
+			RecordType: record.RecordType,
+			Value:      addresses[record.RecordType],
+			TTL:        ttl,
+		}
+		reqErrs[i] = detectErrs[record.RecordType]
+	}
+
+	return reqs, reqErrs, addresses
+}
+
+// recordDetectedAddress remembers the published IPv4 address, or the IPv6 one if there's none
+func (s *Service) recordDetectedAddress(addresses map[string]string) {
+	if ip := addresses["A"]; ip != "" {
+		s.recordLastUpdate(ip)
+	} else if ip := addresses["AAAA"]; ip != "" {
+		s.recordLastUpdate(ip)
+	}
+}
+
+// UpdateAll updates every configured record of each domain together, so a domain's A and AAAA stay in sync
+// Records come from Config.Records, or are the configured record type of every domain
+// If a record of a domain fails on a provider, the domain's records already updated there are rolled back
+// to their previous values; records whose previous value couldn't be read stay updated
+func (s *Service) UpdateAll(ctx context.Context) (resp *UpdateResponse, err error) {
+	defer s.observeUpdate(time.Now(), &resp, &err)
+
+	if executor.RequestIDFromContext(ctx) == "" {
+		ctx = executor.WithRequestID(ctx, executor.NewRequestID())
+	}
+
+	records := s.config.Records
+	if len(records) == 0 {
+		if resp, ok, err := s.updateNonAddressRecord(ctx); ok {
+			return resp, err
+		}
+		for _, domain := range s.domains() {
+			records = append(records, RecordSpec{Domain: domain, RecordType: s.config.RecordType})
+		}
+	}
+
+	reqs, reqErrs, addresses := s.recordSpecRequests(ctx, records)
+
+	// Group the records by domain, keeping the configured order
+	var domains []string
+	groups := make(map[string][]int)
+	for i, req := range reqs {
+		if _, seen := groups[req.Domain]; !seen {
+			domains = append(domains, req.Domain)
+		}
+		groups[req.Domain] = append(groups[req.Domain], i)
+	}
+
+	var results []ProviderResult
+	changed := make([]bool, len(reqs))
+	for _, provider := range s.providers {
+		for _, domain := range domains {
+			group := make([]UpdateRequest, len(groups[domain]))
+			groupErrs := make([]error, len(groups[domain]))
+			for j, i := range groups[domain] {
+				group[j], groupErrs[j] = reqs[i], reqErrs[i]
+			}
+
+			groupResults := s.updateDomainRecords(ctx, provider, group, groupErrs)
+			for j, i := range groups[domain] {
+				changed[i] = changed[i] || groupResults[j].Changed
+			}
+			results = append(results, groupResults...)
+		}
+	}
+
+	s.recordResultErrors(results)
+
+	resp, err = aggregateResults(results)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if update is needed
-	existingRecord, err := s.provider.GetCurrentRecord(ctx, s.config.Domain, s.config.RecordType)
-	if err == nil && existingRecord == currentIP {
-		// No update needed
-		return &UpdateResponse{
-			Success:   true,
-			Message:   "Record already up to date",
-			UpdatedAt: time.Now(),
-		}, nil
+	s.checkPropagation(ctx, resp, reqs, changed)
+	s.publishChanged(resp, reqs, changed)
+
+	s.recordDetectedAddress(addresses)
+
+	return resp, nil
+}
+
+// ErrRolledBack is reported for records UpdateAll restored after another record of their domain failed
+var ErrRolledBack = errors.New("update rolled back")
+
+// updateDomainRecords pushes the records of one domain to a provider, all or nothing
+// Nothing is pushed if any record has an error in reqErrs; after a failed push the records
+// already updated are restored and the remaining ones are skipped
+func (s *Service) updateDomainRecords(ctx context.Context, provider Provider, reqs []UpdateRequest, reqErrs []error) []ProviderResult {
+	results := make([]ProviderResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = ProviderResult{Provider: provider.GetProviderName(), Domain: req.Domain, RecordType: req.RecordType}
+	}
+
+	// skipRemaining fails every record from start on because of the record at failed
+	skipRemaining := func(start, failed int) {
+		for i := start; i < len(reqs); i++ {
+			if i != failed && results[i].Err == nil {
+				results[i].Err = fmt.Errorf("not updated because the %s record failed", reqs[failed].RecordType)
+			}
+		}
 	}
 
-	// Update the record
-	req := UpdateRequest{
-		Domain:     s.config.Domain,
-		RecordType: s.config.RecordType,
-		Value:      currentIP,
-		TTL:        s.config.TTL,
+	for i, err := range reqErrs {
+		if err != nil {
+			results[i].Err = err
+			skipRemaining(0, i)
+			return results
+		}
 	}
 
-	return s.provider.UpdateRecord(ctx, req)
+	previous := make([]string, len(reqs))
+	readable := make([]bool, len(reqs))
+	for i, req := range reqs {
+		existing, err := s.cachedRecord(ctx, provider, req.Domain, req.RecordType)
+		previous[i], readable[i] = existing, err == nil && existing != ""
+
+		if readable[i] && existing == req.Value && !s.config.ForceUpdate {
+			results[i].Response = &UpdateResponse{
+				Success:   true,
+				Message:   "Record already up to date",
+				UpdatedAt: time.Now(),
+			}
+			continue
+		}
+
+		resp, err := provider.UpdateRecord(ctx, req)
+		s.recordCache.invalidate(provider, req.Domain, req.RecordType)
+		if err == nil && !resp.Success {
+			err = fmt.Errorf("update failed: %s", resp.Message)
+		}
+		if err != nil {
+			results[i].Err = err
+			skipRemaining(i+1, i)
+			s.rollBack(ctx, provider, reqs[:i], previous, readable, results, i)
+			return results
+		}
+
+		results[i].Response = resp
+		results[i].Changed = true
+	}
+
+	return results
+}
+
+// rollBack restores the previous values of the changed records after the record at failed failed
+// Records that can't be restored stay changed, and why is added to the failed record's error
+func (s *Service) rollBack(ctx context.Context, provider Provider, reqs []UpdateRequest, previous []string, readable []bool, results []ProviderResult, failed int) {
+	for i, req := range reqs {
+		if !results[i].Changed {
+			continue
+		}
+
+		if !readable[i] {
+			results[failed].Err = errors.Join(results[failed].Err, fmt.Errorf("cannot roll back %s record: previous value unknown", req.RecordType))
+			continue
+		}
+
+		restore := req
+		restore.Value = previous[i]
+		_, err := provider.UpdateRecord(ctx, restore)
+		s.recordCache.invalidate(provider, req.Domain, req.RecordType)
+		if err != nil {
+			results[failed].Err = errors.Join(results[failed].Err, fmt.Errorf("failed to roll back %s record: %w", req.RecordType, err))
+			continue
+		}
+
+		results[i].Changed = false
+		results[i].RolledBack = true
+		results[i].Err = fmt.Errorf("%w after the %s record failed", ErrRolledBack, results[failed].RecordType)
+	}
+}
+
+// detectAddress detects the public address of the family used by recordType
+func (s *Service) detectAddress(ctx context.Context, recordType string) (string, error) {
+	if recordType != "A" && recordType != "AAAA" {
+		return "", fmt.Errorf("%s records don't hold an IP address", recordType)
+	}
+
+	var ip string
+	var err error
+	if recordType == "AAAA" {
+		detector, ok := s.ipDetector.(IPv6Detector)
+		if !ok {
+			return "", fmt.Errorf("IP detector cannot detect IPv6 addresses")
+		}
+		ip, err = detector.GetPublicIPv6(ctx)
+	} else {
+		ip, err = s.ipDetector.GetPublicIP(ctx)
+	}
+	if err != nil {
+		s.logger.Error("IP detection failed", "record_type", recordType, "error", err)
+		return "", err
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil || (parsed.To4() == nil) != (recordType == "AAAA") {
+		return "", fmt.Errorf("detected address %s does not match %s records", ip, recordType)
+	}
+
+	s.logger.Debug("Detected public IP", "record_type", recordType, "ip", ip)
+	return ip, nil
+}
+
+// recordLastUpdate remembers the IP published by a successful update, for status reporting
+func (s *Service) recordLastUpdate(ip string) {
+	s.lastMu.Lock()
+	s.lastIP = ip
+	s.lastUpdateTime = time.Now()
+	s.lastMu.Unlock()
+}
+
+// ErrRecordMismatch is returned when DNS doesn't serve the value an update published
+var ErrRecordMismatch = errors.New("published record does not match")
+
+// UpdateIPWithVerification runs UpdateIP, then checks once that verifier (typically a ResolverIPDetector
+// pointed at the authoritative nameserver) reports the published value, returning ErrRecordMismatch if not
+// The update's response is returned even when verification fails
+func (s *Service) UpdateIPWithVerification(ctx context.Context, verifier IPDetector) (*UpdateResponse, error) {
+	resp, err := s.UpdateIP(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := s.GetCurrentCachedIP()
+	if s.config.RecordType == "CNAME" {
+		expected = strings.TrimSuffix(s.config.Target, ".")
+	}
+
+	published, err := verifier.GetPublicIP(ctx)
+	if err != nil {
+		return resp, fmt.Errorf("failed to verify update: %w", err)
+	}
+	if published != expected {
+		return resp, fmt.Errorf("%w: DNS serves %s, expected %s", ErrRecordMismatch, published, expected)
+	}
+
+	return resp, nil
+}
+
+// GetLastUpdateInfo returns the IP and time of the last successful UpdateIP call
+// ok is false if no update has completed yet
+func (s *Service) GetLastUpdateInfo() (ip string, t time.Time, ok bool) {
+	s.lastMu.RLock()
+	defer s.lastMu.RUnlock()
+
+	return s.lastIP, s.lastUpdateTime, !s.lastUpdateTime.IsZero()
+}
+
+// recordError adds an update error for domain to the error window
+func (s *Service) recordError(domain string, err error) {
+	s.errorWindow.add(TimestampedError{Err: err, OccurredAt: time.Now(), Domain: domain})
+}
+
+// recordResultErrors adds the error of every failed provider result to the error window
+func (s *Service) recordResultErrors(results []ProviderResult) {
+	for _, result := range results {
+		if result.Err != nil {
+			s.recordError(result.Domain, result.Err)
+		}
+	}
+}
+
+// GetErrorWindow returns the most recent update errors, up to 100, oldest first
+func (s *Service) GetErrorWindow() []TimestampedError {
+	return s.errorWindow.snapshot()
+}
+
+// GetErrorRate returns the update errors per minute over the last window
+// Only the errors still in the window returned by GetErrorWindow are counted
+func (s *Service) GetErrorRate(window time.Duration) float64 {
+	return s.errorWindow.rate(window, time.Now())
+}
+
+// GetCurrentCachedIP returns the last published IP without any network call, or "" if none yet
+func (s *Service) GetCurrentCachedIP() string {
+	s.lastMu.RLock()
+	defer s.lastMu.RUnlock()
+
+	return s.lastIP
+}
+
+// UpdateRecord updates the configured DNS record with the given value, for any record type
+func (s *Service) UpdateRecord(ctx context.Context, value string) (resp *UpdateResponse, err error) {
+	defer s.observeUpdate(time.Now(), &resp, &err)
+
+	return s.updateRecord(ctx, value)
+}
+
+// updateRecord implements UpdateRecord, and UpdateIP once it has a value
+func (s *Service) updateRecord(ctx context.Context, value string) (*UpdateResponse, error) {
+	if value == "" {
+		return nil, fmt.Errorf("record value is required")
+	}
+
+	domains := s.domains()
+	reqs := make([]UpdateRequest, len(domains))
+	for i, domain := range domains {
+		reqs[i] = UpdateRequest{
+			Domain:     domain,
+			RecordType: s.config.RecordType,
+			Value:      value,
+			TTL:        s.config.TTL,
+		}
+	}
+
+	return s.updateRequests(ctx, reqs, nil)
+}
+
+// updateRequests pushes every request to every provider and combines the results
+// A request with a non-nil entry in reqErrs is not sent and fails with that error on every provider
+func (s *Service) updateRequests(ctx context.Context, reqs []UpdateRequest, reqErrs []error) (*UpdateResponse, error) {
+	// A failure on one provider or domain must not stop the others from being updated
+	// Results are ordered by request, then provider
+	results := make([]ProviderResult, len(s.providers)*len(reqs))
+
+	var pending []UpdateRequest
+	var pendingIndex []int
+	for i, req := range reqs {
+		if reqErrs != nil && reqErrs[i] != nil {
+			for p, provider := range s.providers {
+				results[i*len(s.providers)+p] = ProviderResult{Provider: provider.GetProviderName(), Domain: req.Domain, RecordType: req.RecordType, Err: reqErrs[i]}
+			}
+			continue
+		}
+		pending = append(pending, req)
+		pendingIndex = append(pendingIndex, i)
+	}
+
+	if len(pending) > 0 {
+		for p, provider := range s.providers {
+			for j, result := range s.updateProviderRecords(ctx, provider, pending) {
+				results[pendingIndex[j]*len(s.providers)+p] = result
+			}
+		}
+	}
+
+	s.recordResultErrors(results)
+
+	resp, err := aggregateResults(results)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]bool, len(reqs))
+	for i := range reqs {
+		for _, result := range results[i*len(s.providers) : (i+1)*len(s.providers)] {
+			changed[i] = changed[i] || result.Changed
+		}
+	}
+
+	s.checkPropagation(ctx, resp, reqs, changed)
+	s.publishChanged(resp, reqs, changed)
+
+	return resp, nil
+}
+
+// publishChanged publishes one event per record that changed on at least one provider
+func (s *Service) publishChanged(resp *UpdateResponse, reqs []UpdateRequest, changed []bool) {
+	for i, req := range reqs {
+		if changed[i] {
+			s.metrics.ipChanges.Add(1)
+			s.publish(UpdateEvent{
+				Domain:     req.Domain,
+				RecordType: req.RecordType,
+				IP:         req.Value,
+				Response:   resp,
+				OccurredAt: time.Now(),
+			})
+		}
+	}
+}
+
+// DefaultPropagationTimeout bounds propagation verification when Config.PropagationTimeout is zero
+const DefaultPropagationTimeout = 2 * time.Minute
+
+// propagationPollDelay is the first delay between propagation checks; later ones back off exponentially
+const propagationPollDelay = 2 * time.Second
+
+// checkPropagation polls DNS until it serves every changed record, if Config.VerifyPropagation is set
+func (s *Service) checkPropagation(ctx context.Context, resp *UpdateResponse, reqs []UpdateRequest, changed []bool) {
+	if !s.config.VerifyPropagation {
+		return
+	}
+
+	timeout := s.config.PropagationTimeout
+	if timeout <= 0 {
+		timeout = DefaultPropagationTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := s.propagationDelay
+	if delay <= 0 {
+		delay = propagationPollDelay
+	}
+
+	// Polling only ends when DNS serves the value or the timeout passes
+	exec := executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(math.MaxInt32, delay, 2.0)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(10*time.Second)),
+	)
+
+	resp.PropagationChecked = true
+	resp.PropagationConfirmed = true
+	for i, req := range reqs {
+		if !changed[i] {
+			continue
+		}
+
+		poll := func(taskCtx context.Context) (string, error) {
+			value, err := resolveRecord(taskCtx, s.resolver, req.Domain, req.RecordType)
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSuffix(value, ".") != strings.TrimSuffix(req.Value, ".") {
+				return "", fmt.Errorf("%s %s record resolves to %s, expected %s", req.Domain, req.RecordType, value, req.Value)
+			}
+			return value, nil
+		}
+
+		if _, err := executor.ExecuteSimple(exec, ctx, poll); err != nil {
+			s.logger.Warn("Propagation not confirmed", "domain", req.Domain, "record_type", req.RecordType, "error", err)
+			resp.PropagationConfirmed = false
+			return
+		}
+	}
+}
+
+// ClearRecords removes every record the service updates from each provider that implements RecordClearer
+// Providers that can't clear records are reported in the returned error alongside failed clears
+func (s *Service) ClearRecords(ctx context.Context) error {
+	records := s.config.Records
+	if len(records) == 0 {
+		recordType := s.config.RecordType
+		if recordType == "" {
+			recordType = "A"
+		}
+		for _, domain := range s.domains() {
+			records = append(records, RecordSpec{Domain: domain, RecordType: recordType})
+		}
+	}
+
+	var errs []error
+	for _, provider := range s.providers {
+		clearer, ok := provider.(RecordClearer)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: clearing records is not supported", provider.GetProviderName()))
+			continue
+		}
+
+		for _, record := range records {
+			err := clearer.ClearRecord(ctx, record.Domain, record.RecordType)
+			s.recordCache.invalidate(provider, record.Domain, record.RecordType)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: failed to clear %s %s record: %w", provider.GetProviderName(), record.Domain, record.RecordType, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// domains returns every domain the service updates
+func (s *Service) domains() []string {
+	if len(s.config.Domains) > 0 {
+		return s.config.Domains
+	}
+	return []string{s.config.Domain}
+}
+
+// updateProviderRecords pushes every request to a single provider, batching them when it supports it
+func (s *Service) updateProviderRecords(ctx context.Context, provider Provider, reqs []UpdateRequest) []ProviderResult {
+	results := make([]ProviderResult, len(reqs))
+
+	batch, ok := provider.(BatchUpdateProvider)
+	if !ok || len(reqs) == 1 {
+		for i, req := range reqs {
+			results[i] = s.updateProvider(ctx, provider, req)
+		}
+		return results
+	}
+
+	// Only records that don't already match are sent in the batch
+	var pending []int
+	for i, req := range reqs {
+		results[i] = ProviderResult{Provider: provider.GetProviderName(), Domain: req.Domain, RecordType: req.RecordType}
+		if s.config.ForceUpdate {
+			pending = append(pending, i)
+			continue
+		}
+
+		if s.recordMatches(ctx, provider, req) {
+			results[i].Response = &UpdateResponse{
+				Success:   true,
+				Message:   "Record already up to date",
+				UpdatedAt: time.Now(),
+			}
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	if len(pending) == 0 {
+		return results
+	}
+
+	batchReqs := make([]UpdateRequest, len(pending))
+	for j, i := range pending {
+		batchReqs[j] = reqs[i]
+		s.logger.Info("Pushing update", "provider", provider.GetProviderName(), "domain", reqs[i].Domain, "record_type", reqs[i].RecordType, "value", reqs[i].Value)
+	}
+
+	responses, err := batch.UpdateRecords(ctx, batchReqs)
+	for _, req := range batchReqs {
+		s.recordCache.invalidate(provider, req.Domain, req.RecordType)
+	}
+	if err == nil && len(responses) != len(batchReqs) {
+		err = fmt.Errorf("batch update returned %d responses for %d records", len(responses), len(batchReqs))
+	}
+	if err != nil {
+		s.logger.Error("Provider update failed", "provider", provider.GetProviderName(), "records", len(batchReqs), "error", err)
+	}
+	for j, i := range pending {
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Response = responses[j]
+		results[i].Changed = responses[j].Success && !responses[j].Unchanged
+	}
+
+	return results
+}
+
+// currentRecord reads the provider's current record, resolving it through DNS when the provider can't read it
+func (s *Service) currentRecord(ctx context.Context, provider Provider, domain, recordType string) (string, error) {
+	if provider.Capabilities().SupportsRead {
+		return provider.GetCurrentRecord(ctx, domain, recordType)
+	}
+	return resolveRecord(ctx, s.resolver, domain, recordType)
+}
+
+// updateProvider pushes the request to a single provider unless its record already matches
+func (s *Service) updateProvider(ctx context.Context, provider Provider, req UpdateRequest) ProviderResult {
+	result := ProviderResult{Provider: provider.GetProviderName(), Domain: req.Domain, RecordType: req.RecordType}
+
+	// Check if update is needed; forced updates push regardless
+	if !s.config.ForceUpdate {
+		if s.recordMatches(ctx, provider, req) {
+			result.Response = &UpdateResponse{
+				Success:   true,
+				Message:   "Record already up to date",
+				UpdatedAt: time.Now(),
+			}
+			return result
+		}
+	}
+
+	s.logger.Info("Pushing update", "provider", result.Provider, "domain", req.Domain, "record_type", req.RecordType, "value", req.Value)
+	resp, err := provider.UpdateRecord(ctx, req)
+	s.recordCache.invalidate(provider, req.Domain, req.RecordType)
+	if err != nil {
+		s.logger.Error("Provider update failed", "provider", result.Provider, "domain", req.Domain, "record_type", req.RecordType, "error", err)
+		result.Err = err
+		return result
+	}
+
+	result.Response = resp
+	result.Changed = resp.Success && !resp.Unchanged
+	return result
+}
+
+// recordMatches reports whether the provider's record already holds the request's value
+// A record that can't be read doesn't match, so the update is pushed anyway
+func (s *Service) recordMatches(ctx context.Context, provider Provider, req UpdateRequest) bool {
+	existingRecord, err := s.cachedRecord(ctx, provider, req.Domain, req.RecordType)
+	if errors.Is(err, ErrNotFound) {
+		s.logger.Debug("Record not found", "provider", provider.GetProviderName(), "domain", req.Domain, "record_type", req.RecordType)
+		return false
+	}
+	if err != nil {
+		s.logger.Warn("Failed to read current record", "provider", provider.GetProviderName(), "domain", req.Domain, "record_type", req.RecordType, "error", err)
+		return false
+	}
+	if existingRecord != req.Value {
+		return false
+	}
+
+	s.logger.Debug("Record already up to date", "provider", provider.GetProviderName(), "domain", req.Domain, "record_type", req.RecordType, "value", req.Value)
+	return true
+}
+
+// aggregateResults combines per-provider results into a single response
+// A single provider's response is returned as-is; with several providers the update
+// only fails outright when every provider failed, otherwise partial success is reported
+func aggregateResults(results []ProviderResult) (*UpdateResponse, error) {
+	if len(results) == 1 {
+		return results[0].Response, results[0].Err
+	}
+
+	// Name the domain and record type too when several are updated
+	multiDomain, multiType := false, false
+	for _, result := range results {
+		multiDomain = multiDomain || result.Domain != results[0].Domain
+		multiType = multiType || result.RecordType != results[0].RecordType
+	}
+
+	var errs []error
+	var failures []string
+	succeeded := 0
+	for _, result := range results {
+		if result.Err != nil {
+			label := result.Provider
+			switch {
+			case multiDomain && multiType:
+				label = fmt.Sprintf("%s (%s %s)", result.Provider, result.Domain, result.RecordType)
+			case multiDomain:
+				label = fmt.Sprintf("%s (%s)", result.Provider, result.Domain)
+			case multiType:
+				label = fmt.Sprintf("%s (%s)", result.Provider, result.RecordType)
+			}
+			errs = append(errs, fmt.Errorf("%s: %w", label, result.Err))
+			failures = append(failures, fmt.Sprintf("%s: %v", label, result.Err))
+			continue
+		}
+		if result.Response.Success {
+			succeeded++
+		}
+	}
+
+	if succeeded == 0 && len(errs) == len(results) {
+		return nil, errors.Join(errs...)
+	}
+
+	unit := "providers"
+	if multiDomain || multiType {
+		unit = "records"
+	}
+	message := fmt.Sprintf("Updated %d of %d %s", succeeded, len(results), unit)
+	if len(failures) > 0 {
+		message += "; failed: " + strings.Join(failures, "; ")
+	}
+
+	return &UpdateResponse{
+		Success:   succeeded == len(results),
+		Message:   message,
+		UpdatedAt: time.Now(),
+		Results:   results,
+	}, nil
 }
 
 // HTTPIPDetector implements IPDetector using HTTP services
-type HTTPIPDetector struct{}
+type HTTPIPDetector struct {
+	client       *http.Client
+	executor     *executor.Executor
+	allowPrivate bool
+	family       IPFamily // Family GetPublicIP connects over and returns
+	lookupURL    string   // Defaults to httpBinURL
+	ipv6URL      string   // Defaults to ipv6LookupURL
+
+	mu      sync.Mutex
+	clients map[IPFamily]*http.Client // Family-restricted copies of client, built on first use
+}
+
+// NewHTTPIPDetector creates an HTTP IP detector using the given client and executor
+// GetPublicIP only connects over the given family, so it returns an address of that family
+func NewHTTPIPDetector(client *http.Client, exec *executor.Executor, family IPFamily) *HTTPIPDetector {
+	return &HTTPIPDetector{
+		client:   client,
+		executor: exec,
+		family:   family,
+	}
+}
+
+// WithAllowPrivateIP sets whether private addresses are returned instead of ErrPrivateIP
+func (d *HTTPIPDetector) WithAllowPrivateIP(allow bool) *HTTPIPDetector {
+	d.allowPrivate = allow
+	return d
+}
 
 // GetPublicIP retrieves the current public IP address using HTTP services
+// The IPv6 family uses the IPv6-only lookup of GetPublicIPv6, since httpbin.org can't be reached over IPv6
 func (d *HTTPIPDetector) GetPublicIP(ctx context.Context) (string, error) {
-	return getCurrentPublicIPFromService(ctx)
+	if d.family == IPFamilyV6 {
+		return d.GetPublicIPv6(ctx)
+	}
+
+	client := d.familyClient(d.family)
+
+	exec := d.executor
+	if exec == nil {
+		exec = executor.NewExecutor(
+			executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)),
+			executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(10*time.Second)),
+		)
+	}
+
+	lookupURL := d.lookupURL
+	if lookupURL == "" {
+		lookupURL = httpBinURL
+	}
+
+	ip, err := getCurrentPublicIPFromService(ctx, client, exec, lookupURL)
+	if err != nil {
+		return "", err
+	}
+
+	if err := d.family.checkAddress(ip); err != nil {
+		return "", err
+	}
+
+	if err := validateDetectedIP(ip, d.allowPrivate); err != nil {
+		return "", err
+	}
+
+	return ip, nil
+}
+
+// GetPublicIPv6 retrieves the current public IPv6 address, connecting over IPv6 only
+func (d *HTTPIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	client := d.familyClient(IPFamilyV6)
+
+	exec := d.executor
+	if exec == nil {
+		exec = executor.NewExecutor(
+			executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)),
+			executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(10*time.Second)),
+		)
+	}
+
+	lookupURL := d.ipv6URL
+	if lookupURL == "" {
+		lookupURL = ipv6LookupURL
+	}
+
+	ip, err := getIPFromPlainText(ctx, client, exec, lookupURL)
+	if err != nil {
+		return "", err
+	}
+
+	if err := IPFamilyV6.checkAddress(ip); err != nil {
+		return "", err
+	}
+
+	if err := validateDetectedIP(ip, d.allowPrivate); err != nil {
+		return "", err
+	}
+
+	return ip, nil
+}
+
+// familyClient returns the detector's client restricted to the family, building it once per family
+func (d *HTTPIPDetector) familyClient(family IPFamily) *http.Client {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if client, ok := d.clients[family]; ok {
+		return client
+	}
+	if d.clients == nil {
+		d.clients = make(map[IPFamily]*http.Client)
+	}
+	client := familyClient(d.client, family)
+	d.clients[family] = client
+	return client
 }
 
-// Validate checks if the service configuration and credentials are valid
+// Validate checks if the service configuration and credentials are valid for every provider
 func (s *Service) Validate(ctx context.Context) error {
-	return s.provider.ValidateCredentials(ctx)
+	var errs []error
+	for _, provider := range s.providers {
+		if err := provider.ValidateCredentials(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-// GetProvider returns the underlying provider
+// GetProvider returns the underlying provider, or the first one when several are configured
 func (s *Service) GetProvider() Provider {
-	return s.provider
+	if len(s.providers) == 0 {
+		return nil
+	}
+	return s.providers[0]
+}
+
+// GetProviders returns all providers the service updates
+func (s *Service) GetProviders() []Provider {
+	return s.providers
 }
 
 // getCurrentPublicIPFromService gets the public IP from an external service
-func getCurrentPublicIPFromService(ctx context.Context) (string, error) {
+func getCurrentPublicIPFromService(ctx context.Context, client *http.Client, exec *executor.Executor, lookupURL string) (string, error) {
 	// Simple implementation - in practice you might want to try multiple services
-	// and use the executor for retry logic
-	return getIPFromHTTPBin(ctx)
+	return getIPFromHTTPBin(ctx, client, exec, lookupURL)
 }
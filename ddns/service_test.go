@@ -2,8 +2,17 @@ package ddns
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/jq1836/DDNS/executor"
 )
 
 // mockProvider for testing
@@ -70,6 +79,103 @@ func (m *mockProvider) GetProviderName() string {
 	return m.name
 }
 
+func (m *mockProvider) Capabilities() Capabilities {
+	return Capabilities{SupportsRead: true, SupportsAAAA: true, SupportsTXT: true, SupportsCNAME: true}
+}
+
+// dualStackIPDetector detects both address families
+type dualStackIPDetector struct {
+	ipv4, ipv6 string
+	ipv6Err    error
+}
+
+func (d *dualStackIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	return d.ipv4, nil
+}
+
+func (d *dualStackIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	return d.ipv6, d.ipv6Err
+}
+
+// batchMockProvider counts requests so batched and individual updates can be compared
+type batchMockProvider struct {
+	*mockProvider
+	updateCalls int
+	batchCalls  int
+}
+
+func (m *batchMockProvider) UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	m.updateCalls++
+	return m.mockProvider.UpdateRecord(ctx, req)
+}
+
+func (m *batchMockProvider) UpdateRecords(ctx context.Context, reqs []UpdateRequest) ([]*UpdateResponse, error) {
+	m.batchCalls++
+	resps := make([]*UpdateResponse, 0, len(reqs))
+	for _, req := range reqs {
+		resp, err := m.mockProvider.UpdateRecord(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// writeOnlyProvider can't read its records back, like DuckDNS
+type writeOnlyProvider struct {
+	*mockProvider
+}
+
+func (m *writeOnlyProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error) {
+	return "", &mockError{"reading records is not supported"}
+}
+
+func (m *writeOnlyProvider) Capabilities() Capabilities {
+	return Capabilities{SupportsAAAA: true}
+}
+
+// typeFailingProvider fails updates of one record type
+type typeFailingProvider struct {
+	*mockProvider
+	failType string
+}
+
+func (m *typeFailingProvider) UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	if req.RecordType == m.failType {
+		return nil, &mockError{"update failed"}
+	}
+	return m.mockProvider.UpdateRecord(ctx, req)
+}
+
+// staticResolver answers lookups from fixed maps
+type staticResolver struct {
+	hosts map[string][]string
+	txt   map[string][]string
+	cname map[string]string
+}
+
+func (r staticResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addresses, ok := r.hosts[host]; ok {
+		return addresses, nil
+	}
+	return nil, &mockError{"no such host"}
+}
+
+func (r staticResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if values, ok := r.txt[name]; ok {
+		return values, nil
+	}
+	return nil, &mockError{"no such host"}
+}
+
+func (r staticResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if target, ok := r.cname[host]; ok {
+		return target, nil
+	}
+	return "", &mockError{"no such host"}
+}
+
 type mockError struct {
 	msg string
 }
@@ -164,6 +270,59 @@ func TestServiceUpdateIPDetectionFails(t *testing.T) {
 	}
 }
 
+// recordingLogger keeps each log line as "LEVEL message"
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) {
+	l.lines = append(l.lines, "DEBUG "+msg)
+}
+func (l *recordingLogger) Info(msg string, args ...interface{}) {
+	l.lines = append(l.lines, "INFO "+msg)
+}
+func (l *recordingLogger) Warn(msg string, args ...interface{}) {
+	l.lines = append(l.lines, "WARN "+msg)
+}
+func (l *recordingLogger) Error(msg string, args ...interface{}) {
+	l.lines = append(l.lines, "ERROR "+msg)
+}
+
+// *slog.Logger can be used as a Logger directly
+var _ Logger = slog.Default()
+
+func TestServiceLogsDecisions(t *testing.T) {
+	provider := newMockProvider("test")
+	logger := &recordingLogger{}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"}).
+		WithLogger(logger)
+	ctx := context.Background()
+
+	service.UpdateIP(ctx)
+	service.UpdateIP(ctx)
+	want := []string{
+		"DEBUG Detected public IP", "WARN Failed to read current record", "INFO Pushing update",
+		"DEBUG Detected public IP", "DEBUG Record already up to date",
+	}
+	if !slices.Equal(logger.lines, want) {
+		t.Errorf("Expected %q, got %q", want, logger.lines)
+	}
+
+	logger.lines = nil
+	provider.shouldFail = true
+	service.UpdateIP(ctx)
+	if !slices.Contains(logger.lines, "ERROR Provider update failed") {
+		t.Errorf("Expected the provider error to be logged, got %q", logger.lines)
+	}
+
+	logger.lines = nil
+	service.ipDetector = &mockIPDetector{shouldFail: true}
+	service.UpdateIP(ctx)
+	if !slices.Equal(logger.lines, []string{"ERROR IP detection failed"}) {
+		t.Errorf("Expected the detection error to be logged, got %q", logger.lines)
+	}
+}
+
 func TestServiceValidate(t *testing.T) {
 	provider := newMockProvider("test")
 	config := Config{}
@@ -224,6 +383,28 @@ func TestUpdateRequest(t *testing.T) {
 	}
 }
 
+func TestSRVValue(t *testing.T) {
+	value := SRVValue{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com."}
+	if got := value.FormatSRV(); got != "10 5 5060 sip.example.com." {
+		t.Errorf("Expected RFC 2782 format, got %q", got)
+	}
+	if err := value.Validate(); err != nil {
+		t.Errorf("Expected valid SRV value, got %v", err)
+	}
+
+	for _, invalid := range []SRVValue{
+		{Port: 0, Target: "sip.example.com."},
+		{Port: 65536, Target: "sip.example.com."},
+		{Priority: -1, Port: 5060, Target: "sip.example.com."},
+		{Weight: 70000, Port: 5060, Target: "sip.example.com."},
+		{Port: 5060},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("Expected error for %+v", invalid)
+		}
+	}
+}
+
 func TestUpdateResponse(t *testing.T) {
 	now := time.Now()
 	resp := UpdateResponse{
@@ -280,3 +461,865 @@ func TestConfig(t *testing.T) {
 		t.Error("UpdateInterval not set correctly")
 	}
 }
+
+func TestServiceSubscribe(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Domain:     "example.com",
+		RecordType: "A",
+		TTL:        300,
+	}
+
+	ipDetector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(provider, config, ipDetector)
+
+	const subscriberCount = 5
+	var wg sync.WaitGroup
+	received := make(chan UpdateEvent, subscriberCount)
+
+	for i := 0; i < subscriberCount; i++ {
+		ch := service.Subscribe()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			received <- <-ch
+		}()
+	}
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	wg.Wait()
+	close(received)
+
+	count := 0
+	for event := range received {
+		count++
+		if event.IP != "203.0.113.1" {
+			t.Errorf("Expected event IP 203.0.113.1, got %s", event.IP)
+		}
+	}
+
+	if count != subscriberCount {
+		t.Errorf("Expected %d events, got %d", subscriberCount, count)
+	}
+
+	// No event should be sent when the record is already up to date
+	ch := service.Subscribe()
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case event := <-ch:
+		t.Errorf("Expected no event for unchanged record, got %+v", event)
+	default:
+	}
+}
+
+func TestServiceUnsubscribe(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Domain:     "example.com",
+		RecordType: "A",
+	}
+
+	ipDetector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(provider, config, ipDetector)
+
+	kept := service.Subscribe()
+	removed := service.Subscribe()
+	service.Unsubscribe(removed)
+
+	if _, ok := <-removed; ok {
+		t.Error("Expected unsubscribed channel to be closed")
+	}
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if event := <-kept; event.Domain != "example.com" {
+		t.Errorf("Expected event for example.com, got %s", event.Domain)
+	}
+}
+
+func TestServiceSubscribeDropsEventsForFullChannel(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Domain:          "example.com",
+		RecordType:      "A",
+		EventBufferSize: 1,
+	}
+
+	ipDetector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(provider, config, ipDetector)
+	service.Subscribe()
+
+	// Change the IP between updates so each one publishes an event
+	for _, ip := range []string{"203.0.113.1", "203.0.113.2", "203.0.113.3"} {
+		ipDetector.ip = ip
+		if _, err := service.UpdateIP(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if dropped := service.Stats().DroppedEvents; dropped != 2 {
+		t.Errorf("Expected 2 dropped events, got %d", dropped)
+	}
+}
+
+func TestServiceUpdateRecordTXT(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Domain:     "_acme-challenge.example.com",
+		RecordType: "TXT",
+		TTL:        60,
+	}
+
+	// A failing IP detector proves TXT updates never detect the IP
+	ipDetector := &mockIPDetector{shouldFail: true}
+	service := NewServiceWithIPDetector(provider, config, ipDetector)
+
+	resp, err := service.UpdateRecord(context.Background(), "challenge-token")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !resp.Success {
+		t.Error("Expected successful update")
+	}
+
+	key := config.Domain + ":" + config.RecordType
+	if provider.records[key] != "challenge-token" {
+		t.Errorf("Expected TXT record 'challenge-token', got %s", provider.records[key])
+	}
+
+	// UpdateIP has no IP to publish for TXT records
+	if _, err := service.UpdateIP(context.Background()); err == nil {
+		t.Error("Expected error calling UpdateIP for a TXT record")
+	}
+}
+
+func TestServiceUpdateIPCNAMEUsesTarget(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Domain:     "www.example.com",
+		RecordType: "CNAME",
+		Target:     "home.dyndns.example.net",
+	}
+
+	// A failing IP detector proves CNAME updates never detect the IP
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{shouldFail: true})
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if got := provider.records["www.example.com:CNAME"]; got != config.Target {
+		t.Errorf("Expected CNAME record %q, got %q", config.Target, got)
+	}
+
+	config.Target = ""
+	service = NewServiceWithIPDetector(provider, config, &mockIPDetector{})
+	if _, err := service.UpdateIP(context.Background()); err == nil {
+		t.Error("Expected error for a CNAME record without a target")
+	}
+}
+
+func TestServiceUpdateAllNonAddressRecords(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{Domain: "www.example.com", RecordType: "CNAME", Target: "home.dyndns.example.net"}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+
+	if _, err := service.UpdateAll(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if got := provider.records["www.example.com:CNAME"]; got != config.Target {
+		t.Errorf("Expected CNAME record %q, got %q", config.Target, got)
+	}
+
+	service = NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "TXT"}, &mockIPDetector{ip: "203.0.113.1"})
+	if _, err := service.UpdateAll(context.Background()); err == nil {
+		t.Error("Expected error for a TXT record without a value")
+	}
+	if _, ok := provider.records["example.com:TXT"]; ok {
+		t.Error("Expected no TXT record to be published")
+	}
+}
+
+func TestServiceGetLastUpdateInfo(t *testing.T) {
+	ipDetector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewServiceWithIPDetector(newMockProvider("test"), Config{Domain: "example.com", RecordType: "A"}, ipDetector)
+
+	if _, _, ok := service.GetLastUpdateInfo(); ok {
+		t.Error("Expected no update info before the first update")
+	}
+	if ip := service.GetCurrentCachedIP(); ip != "" {
+		t.Errorf("Expected empty cached IP, got %s", ip)
+	}
+
+	before := time.Now()
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	ip, updatedAt, ok := service.GetLastUpdateInfo()
+	if !ok || ip != "203.0.113.1" || updatedAt.Before(before) {
+		t.Errorf("Unexpected update info: ip=%s time=%s ok=%v", ip, updatedAt, ok)
+	}
+
+	// A failed update keeps the previous information
+	ipDetector.ip = "203.0.113.2"
+	ipDetector.shouldFail = true
+	service.UpdateIP(context.Background())
+
+	if ip := service.GetCurrentCachedIP(); ip != "203.0.113.1" {
+		t.Errorf("Expected cached IP to stay 203.0.113.1 after a failure, got %s", ip)
+	}
+}
+
+// requestIDProvider records the request ID seen by UpdateRecord
+type requestIDProvider struct {
+	*mockProvider
+	requestID string
+}
+
+func (p *requestIDProvider) UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	p.requestID = executor.RequestIDFromContext(ctx)
+	return p.mockProvider.UpdateRecord(ctx, req)
+}
+
+func TestServiceUpdateIPAttachesRequestID(t *testing.T) {
+	provider := &requestIDProvider{mockProvider: newMockProvider("test")}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{ip: "203.0.113.1"})
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.requestID == "" {
+		t.Error("Expected UpdateIP to attach a generated request ID")
+	}
+
+	// A caller-supplied ID is kept
+	ctx := executor.WithRequestID(context.Background(), "cycle-42")
+	provider.records = map[string]string{}
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.requestID != "cycle-42" {
+		t.Errorf("Expected request ID cycle-42, got %q", provider.requestID)
+	}
+}
+
+func TestServiceUpdateRecordRequiresValue(t *testing.T) {
+	service := NewServiceWithIPDetector(newMockProvider("test"), Config{Domain: "example.com", RecordType: "A"}, &mockIPDetector{})
+
+	if _, err := service.UpdateRecord(context.Background(), ""); err == nil {
+		t.Error("Expected error for empty record value")
+	}
+}
+
+func TestMultiProviderServicePartialFailure(t *testing.T) {
+	healthy := newMockProvider("healthy")
+	broken := newMockProvider("broken")
+	broken.shouldFail = true
+
+	config := Config{
+		Domain:     "example.com",
+		RecordType: "A",
+		TTL:        300,
+	}
+
+	ipDetector := &mockIPDetector{ip: "203.0.113.1"}
+	service := NewMultiProviderService([]Provider{broken, healthy}, config, ipDetector)
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial success without error, got %v", err)
+	}
+
+	if resp.Success {
+		t.Error("Expected Success to be false when one provider failed")
+	}
+
+	if len(resp.Results) != 2 {
+		t.Fatalf("Expected 2 provider results, got %d", len(resp.Results))
+	}
+
+	// The failing provider must not prevent the healthy one from being updated
+	if healthy.records["example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected healthy provider to be updated, got %s", healthy.records["example.com:A"])
+	}
+
+	if resp.Results[0].Err == nil || resp.Results[1].Err != nil {
+		t.Errorf("Expected only the broken provider to report an error, got %+v", resp.Results)
+	}
+}
+
+func TestServiceUpdateIPMultipleDomains(t *testing.T) {
+	provider := newMockProvider("test")
+	provider.records["vpn.example.com:A"] = "203.0.113.1"
+
+	config := Config{
+		Domain:     "home.example.com",
+		Domains:    []string{"home.example.com", "vpn.example.com"},
+		RecordType: "A",
+	}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+	events := service.Subscribe()
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resp.Results) != 2 || resp.Results[0].Domain != "home.example.com" || resp.Results[1].Domain != "vpn.example.com" {
+		t.Fatalf("Expected one result per domain, got %+v", resp.Results)
+	}
+	if provider.records["home.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected home.example.com to be updated, got %s", provider.records["home.example.com:A"])
+	}
+
+	// Only the domain whose record changed is announced
+	select {
+	case event := <-events:
+		if event.Domain != "home.example.com" {
+			t.Errorf("Expected event for home.example.com, got %s", event.Domain)
+		}
+	default:
+		t.Fatal("Expected an update event")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected a single event, got another for %s", event.Domain)
+	default:
+	}
+}
+
+func TestServiceUpdateIPBatchesDomains(t *testing.T) {
+	provider := &batchMockProvider{mockProvider: newMockProvider("batch")}
+	provider.records["b.example.com:A"] = "203.0.113.1"
+
+	config := Config{
+		Domains:    []string{"a.example.com", "b.example.com", "c.example.com"},
+		RecordType: "A",
+	}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Success || len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 successful results, got %+v", resp)
+	}
+
+	if provider.batchCalls != 1 || provider.updateCalls != 0 {
+		t.Errorf("Expected a single batch call, got %d batch and %d individual calls", provider.batchCalls, provider.updateCalls)
+	}
+	if resp.Results[1].Changed || !resp.Results[0].Changed || !resp.Results[2].Changed {
+		t.Errorf("Expected only out-of-date records to change, got %+v", resp.Results)
+	}
+	if provider.records["c.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected c.example.com to be updated, got %s", provider.records["c.example.com:A"])
+	}
+}
+
+func BenchmarkServiceUpdateDomains(b *testing.B) {
+	domains := make([]string, 50)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("host%d.example.com", i)
+	}
+	config := Config{Domains: domains, RecordType: "A"}
+
+	individual := func() Provider { return newMockProvider("individual") }
+	batch := func() Provider { return &batchMockProvider{mockProvider: newMockProvider("batch")} }
+
+	for _, bench := range []struct {
+		name        string
+		newProvider func() Provider
+	}{
+		{"individual", individual},
+		{"batch", batch},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			requests := 0
+			for i := 0; i < b.N; i++ {
+				provider := bench.newProvider()
+				service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+				if _, err := service.UpdateIP(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+
+				// Each UpdateRecord or UpdateRecords call stands for one API request
+				if p, ok := provider.(*batchMockProvider); ok {
+					requests += p.batchCalls + p.updateCalls
+				} else {
+					requests += len(domains)
+				}
+			}
+			b.ReportMetric(float64(requests)/float64(b.N), "requests/op")
+		})
+	}
+}
+
+func TestServiceResolvesRecordsForWriteOnlyProviders(t *testing.T) {
+	provider := &writeOnlyProvider{newMockProvider("write-only")}
+	resolver := staticResolver{hosts: map[string][]string{"example.com": {"2001:db8::1", "203.0.113.1"}}}
+
+	config := Config{Domain: "example.com", RecordType: "A"}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"}).WithResolver(resolver)
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.Message != "Record already up to date" {
+		t.Errorf("Expected the resolved record to skip the update, got %q", resp.Message)
+	}
+	if len(provider.records) != 0 {
+		t.Errorf("Expected no update, got %v", provider.records)
+	}
+
+	// A differing DNS answer triggers the update
+	provider.mockProvider.records = make(map[string]string)
+	service.WithResolver(staticResolver{hosts: map[string][]string{"example.com": {"198.51.100.1"}}})
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.records["example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected record to be updated, got %v", provider.records)
+	}
+}
+
+func TestResolveRecord(t *testing.T) {
+	resolver := staticResolver{
+		hosts: map[string][]string{"example.com": {"203.0.113.1", "2001:db8::1"}},
+		txt:   map[string][]string{"example.com": {"v=spf1 -all"}},
+		cname: map[string]string{"www.example.com": "example.com."},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		domain, recordType, want string
+	}{
+		{"example.com", "A", "203.0.113.1"},
+		{"example.com", "AAAA", "2001:db8::1"},
+		{"example.com", "TXT", "v=spf1 -all"},
+		{"www.example.com", "CNAME", "example.com"},
+	}
+	for _, tt := range tests {
+		got, err := resolveRecord(ctx, resolver, tt.domain, tt.recordType)
+		if err != nil || got != tt.want {
+			t.Errorf("resolveRecord(%s, %s) = %q, %v; want %q", tt.domain, tt.recordType, got, err, tt.want)
+		}
+	}
+
+	if _, err := resolveRecord(ctx, resolver, "missing.example.com", "A"); err == nil {
+		t.Error("Expected error for an unresolvable domain")
+	}
+}
+
+func TestServiceUpdateIPRecordSpecs(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		TTL: 300,
+		Records: []RecordSpec{
+			{Domain: "home.example.com", RecordType: "A"},
+			{Domain: "ipv6.example.com", RecordType: "AAAA", TTL: 60},
+		},
+	}
+	service := NewServiceWithIPDetector(provider, config, &dualStackIPDetector{ipv4: "203.0.113.1", ipv6: "2001:db8::1"})
+	events := service.Subscribe()
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Success {
+		t.Errorf("Expected success, got %s", resp.Message)
+	}
+
+	if provider.records["home.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected A record to get the IPv4 address, got %v", provider.records)
+	}
+	if provider.records["ipv6.example.com:AAAA"] != "2001:db8::1" {
+		t.Errorf("Expected AAAA record to get the IPv6 address, got %v", provider.records)
+	}
+
+	for _, want := range []string{"A", "AAAA"} {
+		select {
+		case event := <-events:
+			if event.RecordType != want {
+				t.Errorf("Expected %s event, got %s", want, event.RecordType)
+			}
+		default:
+			t.Fatalf("Expected an event for the %s record", want)
+		}
+	}
+}
+
+func TestServiceUpdateIPRecordSpecsPartialDetectionFailure(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Records: []RecordSpec{
+			{Domain: "home.example.com", RecordType: "A"},
+			{Domain: "ipv6.example.com", RecordType: "AAAA"},
+		},
+	}
+	detector := &dualStackIPDetector{ipv4: "203.0.113.1", ipv6Err: &mockError{"no IPv6 connectivity"}}
+	service := NewServiceWithIPDetector(provider, config, detector)
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial success without error, got %v", err)
+	}
+	if resp.Success || resp.Results[1].Err == nil {
+		t.Errorf("Expected the AAAA record to fail, got %+v", resp.Results)
+	}
+	if provider.records["home.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected the A record to still be updated, got %v", provider.records)
+	}
+
+	// Detectors without IPv6 support fail AAAA records instead of publishing an IPv4 address
+	ipv4Only := NewServiceWithIPDetector(newMockProvider("test"), Config{Records: config.Records[1:]}, &mockIPDetector{ip: "203.0.113.1"})
+	if _, err := ipv4Only.UpdateIP(context.Background()); err == nil {
+		t.Error("Expected error for an AAAA record without IPv6 detection")
+	}
+}
+
+func TestServiceForceUpdateSkipsChangeDetection(t *testing.T) {
+	provider := &batchMockProvider{mockProvider: newMockProvider("test")}
+	provider.records["example.com:A"] = "203.0.113.1"
+
+	config := Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}
+	service := NewServiceWithIPDetector(provider, config, &mockIPDetector{ip: "203.0.113.1"})
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.updateCalls != 1 {
+		t.Errorf("Expected the provider to be updated although the record matches, got %d calls", provider.updateCalls)
+	}
+	if resp.Message == "Record already up to date" {
+		t.Error("Expected a forced update, got the up-to-date response")
+	}
+
+	// Batched updates are forced too
+	service.config.Domains = []string{"example.com", "www.example.com"}
+	provider.records["www.example.com:A"] = "203.0.113.1"
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if provider.batchCalls != 1 {
+		t.Errorf("Expected one forced batch update, got %d", provider.batchCalls)
+	}
+}
+
+func TestMultiProviderServiceAllFail(t *testing.T) {
+	first := newMockProvider("first")
+	first.shouldFail = true
+	second := newMockProvider("second")
+	second.shouldFail = true
+
+	config := Config{Domain: "example.com", RecordType: "A"}
+	service := NewMultiProviderService([]Provider{first, second}, config, &mockIPDetector{ip: "203.0.113.1"})
+
+	resp, err := service.UpdateIP(context.Background())
+	if err == nil {
+		t.Fatal("Expected error when every provider fails")
+	}
+
+	if resp != nil {
+		t.Error("Expected nil response when every provider fails")
+	}
+}
+
+func TestServiceUpdateAllRollsBackDomain(t *testing.T) {
+	provider := &typeFailingProvider{mockProvider: newMockProvider("test"), failType: "AAAA"}
+	provider.records["home.example.com:A"] = "198.51.100.1"
+	provider.records["home.example.com:AAAA"] = "2001:db8::9"
+
+	config := Config{
+		Records: []RecordSpec{
+			{Domain: "home.example.com", RecordType: "A"},
+			{Domain: "home.example.com", RecordType: "AAAA"},
+			{Domain: "v4.example.com", RecordType: "A"},
+		},
+	}
+	service := NewServiceWithIPDetector(provider, config, &dualStackIPDetector{ipv4: "203.0.113.1", ipv6: "2001:db8::1"})
+	events := service.Subscribe()
+
+	resp, err := service.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial success without error, got %v", err)
+	}
+	if resp.Success || len(resp.Results) != 3 {
+		t.Fatalf("Expected 3 results and no overall success, got %+v", resp)
+	}
+
+	if provider.records["home.example.com:A"] != "198.51.100.1" {
+		t.Errorf("Expected the A record to be rolled back, got %s", provider.records["home.example.com:A"])
+	}
+	if a := resp.Results[0]; !a.RolledBack || !errors.Is(a.Err, ErrRolledBack) || a.Changed {
+		t.Errorf("Expected the A result to report the rollback, got %+v", a)
+	}
+	if aaaa := resp.Results[1]; aaaa.Err == nil || aaaa.RecordType != "AAAA" {
+		t.Errorf("Expected the AAAA result to fail, got %+v", aaaa)
+	}
+	if other := resp.Results[2]; other.Err != nil || !other.Changed || provider.records["v4.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected the other domain to be updated, got %+v", other)
+	}
+	if !strings.Contains(resp.Message, "test (home.example.com AAAA)") {
+		t.Errorf("Expected the message to name the failed record, got %s", resp.Message)
+	}
+
+	select {
+	case event := <-events:
+		if event.Domain != "v4.example.com" {
+			t.Errorf("Expected an event only for v4.example.com, got %s", event.Domain)
+		}
+	default:
+		t.Fatal("Expected an event for v4.example.com")
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected no event for the rolled back domain, got %+v", event)
+	default:
+	}
+}
+
+func TestServiceUpdateAllWithoutPreviousValue(t *testing.T) {
+	provider := &typeFailingProvider{mockProvider: newMockProvider("test"), failType: "AAAA"}
+	config := Config{
+		Records: []RecordSpec{
+			{Domain: "home.example.com", RecordType: "A"},
+			{Domain: "home.example.com", RecordType: "AAAA"},
+		},
+	}
+	service := NewServiceWithIPDetector(provider, config, &dualStackIPDetector{ipv4: "203.0.113.1", ipv6: "2001:db8::1"})
+
+	resp, err := service.UpdateAll(context.Background())
+	if err != nil {
+		t.Fatalf("Expected partial success without error, got %v", err)
+	}
+
+	// A new record has no previous value to restore, so it stays and the failure says so
+	if !resp.Results[0].Changed || provider.records["home.example.com:A"] != "203.0.113.1" {
+		t.Errorf("Expected the new A record to stay updated, got %+v", resp.Results[0])
+	}
+	if err := resp.Results[1].Err; err == nil || !strings.Contains(err.Error(), "previous value unknown") {
+		t.Errorf("Expected the AAAA error to explain the missing rollback, got %v", err)
+	}
+}
+
+func TestServiceUpdateAllSkipsDomainOnDetectionFailure(t *testing.T) {
+	provider := newMockProvider("test")
+	config := Config{
+		Records: []RecordSpec{
+			{Domain: "home.example.com", RecordType: "A"},
+			{Domain: "home.example.com", RecordType: "AAAA"},
+		},
+	}
+	detector := &dualStackIPDetector{ipv4: "203.0.113.1", ipv6Err: &mockError{"no IPv6 connectivity"}}
+	service := NewServiceWithIPDetector(provider, config, detector)
+
+	if _, err := service.UpdateAll(context.Background()); err == nil {
+		t.Fatal("Expected error when no record of the domain can be updated")
+	}
+	if len(provider.records) != 0 {
+		t.Errorf("Expected nothing to be pushed, got %v", provider.records)
+	}
+}
+
+// countingResolver serves stale answers for a number of lookups before the current ones
+type countingResolver struct {
+	staticResolver
+	stale        map[string][]string
+	staleLookups int // How many lookups are answered from stale
+	lookups      int
+}
+
+func (r *countingResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.lookups++
+	if r.lookups <= r.staleLookups {
+		return r.stale[host], nil
+	}
+	return r.staticResolver.LookupHost(ctx, host)
+}
+
+func TestServiceVerifyPropagation(t *testing.T) {
+	config := Config{Domain: "home.example.com", RecordType: "A", VerifyPropagation: true, PropagationTimeout: 5 * time.Second}
+	resolver := &countingResolver{
+		staticResolver: staticResolver{hosts: map[string][]string{"home.example.com": {"203.0.113.1"}}},
+		stale:          map[string][]string{"home.example.com": {"198.51.100.1"}},
+		staleLookups:   2,
+	}
+	service := NewServiceWithIPDetector(newMockProvider("test"), config, &mockIPDetector{ip: "203.0.113.1"}).WithResolver(resolver)
+	service.propagationDelay = time.Millisecond
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.PropagationChecked || !resp.PropagationConfirmed {
+		t.Errorf("Expected propagation to be confirmed, got %+v", resp)
+	}
+	if resolver.lookups != 3 {
+		t.Errorf("Expected polling until the new value was served, got %d lookups", resolver.lookups)
+	}
+}
+
+func TestServiceVerifyPropagationTimeout(t *testing.T) {
+	config := Config{Domain: "home.example.com", RecordType: "A", VerifyPropagation: true, PropagationTimeout: 50 * time.Millisecond}
+	resolver := staticResolver{hosts: map[string][]string{"home.example.com": {"198.51.100.1"}}}
+	service := NewServiceWithIPDetector(newMockProvider("test"), config, &mockIPDetector{ip: "203.0.113.1"}).WithResolver(resolver)
+	service.propagationDelay = time.Millisecond
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the update itself to succeed, got %v", err)
+	}
+	if !resp.Success || !resp.PropagationChecked || resp.PropagationConfirmed {
+		t.Errorf("Expected a successful but unconfirmed update, got %+v", resp)
+	}
+
+	// Without VerifyPropagation nothing is checked
+	service.config.VerifyPropagation = false
+	service.config.ForceUpdate = true
+	if resp, err := service.UpdateIP(context.Background()); err != nil || resp.PropagationChecked {
+		t.Errorf("Expected no propagation check, got %+v, %v", resp, err)
+	}
+}
+
+func TestServiceVerifyPropagationAsksConfiguredNameserver(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen for DNS queries: %v", err)
+	}
+	defer conn.Close()
+
+	// The fake nameserver never answers; it only records that it was asked
+	queried := make(chan struct{}, 1)
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			if _, _, err := conn.ReadFrom(buf); err != nil {
+				return
+			}
+			select {
+			case queried <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	config := Config{Domain: "home.example.com", RecordType: "A", VerifyPropagation: true, PropagationTimeout: 100 * time.Millisecond, Nameserver: conn.LocalAddr().String()}
+	service := NewServiceWithIPDetector(newMockProvider("test"), config, &mockIPDetector{ip: "203.0.113.1"})
+	service.propagationDelay = time.Millisecond
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected the update itself to succeed, got %v", err)
+	}
+
+	select {
+	case <-queried:
+	case <-time.After(time.Second):
+		t.Error("Expected propagation to be checked through the configured nameserver")
+	}
+}
+
+func TestUpdateIPConfirmsChangedIP(t *testing.T) {
+	provider := newMockProvider("test")
+	detector := &mockIPDetector{ip: "203.0.113.1"}
+	confirmer := &mockIPDetector{ip: "198.51.100.1"}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A", ConfirmIPChange: true}, detector).
+		WithConfirmationDetector(confirmer)
+	ctx := context.Background()
+
+	// The first update has nothing to confirm against
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected first update to succeed, got %v", err)
+	}
+
+	// A single bad read is not published
+	detector.ip = "100.64.0.1"
+	if _, err := service.UpdateIP(ctx); !errors.Is(err, ErrIPChangeUnconfirmed) {
+		t.Fatalf("Expected ErrIPChangeUnconfirmed, got %v", err)
+	}
+	if got := provider.records["example.com:A"]; got != "203.0.113.1" {
+		t.Errorf("Expected record to keep 203.0.113.1, got %s", got)
+	}
+
+	// An unchanged IP isn't re-checked
+	detector.ip = "203.0.113.1"
+	confirmer.shouldFail = true
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected unchanged IP to skip confirmation, got %v", err)
+	}
+
+	// Both detectors agree on the change
+	detector.ip, confirmer.ip, confirmer.shouldFail = "198.51.100.1", "198.51.100.1", false
+	if _, err := service.UpdateIP(ctx); err != nil {
+		t.Fatalf("Expected confirmed change to be published, got %v", err)
+	}
+	if got := provider.records["example.com:A"]; got != "198.51.100.1" {
+		t.Errorf("Expected record to be updated to 198.51.100.1, got %s", got)
+	}
+}
+
+// unchangedProvider reports every update as a no-op, like providers that detect it themselves
+type unchangedProvider struct {
+	*mockProvider
+}
+
+func (p *unchangedProvider) UpdateRecord(ctx context.Context, req UpdateRequest) (*UpdateResponse, error) {
+	return &UpdateResponse{Success: true, Unchanged: true, PreviousIP: req.Value, UpdatedAt: time.Now()}, nil
+}
+
+func TestUpdateRecordHonorsProviderReportedNoChange(t *testing.T) {
+	provider := &unchangedProvider{newMockProvider("unchanged")}
+	service := NewServiceWithIPDetector(provider, Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}, &mockIPDetector{ip: "203.0.113.1"})
+	events := service.Subscribe()
+
+	resp, err := service.UpdateIP(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("Expected no update event for a provider-reported no-op, got %+v", event)
+	default:
+	}
+	if !resp.Unchanged || resp.PreviousIP != "203.0.113.1" {
+		t.Errorf("Expected unchanged response with previous IP, got %+v", resp)
+	}
+}
+
+// clearingProvider records ClearRecord calls
+type clearingProvider struct {
+	*mockProvider
+	cleared []string
+}
+
+func (p *clearingProvider) ClearRecord(ctx context.Context, domain, recordType string) error {
+	p.cleared = append(p.cleared, domain+":"+recordType)
+	return nil
+}
+
+func TestServiceClearRecords(t *testing.T) {
+	clearing := &clearingProvider{mockProvider: newMockProvider("clearing")}
+	plain := newMockProvider("plain")
+	service := NewMultiProviderService([]Provider{clearing, plain}, Config{Domains: []string{"a.example.com", "b.example.com"}, RecordType: "AAAA"}, &mockIPDetector{})
+
+	err := service.ClearRecords(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "plain: clearing records is not supported") {
+		t.Errorf("Expected the provider without ClearRecord to be reported, got %v", err)
+	}
+	if want := []string{"a.example.com:AAAA", "b.example.com:AAAA"}; !slices.Equal(clearing.cleared, want) {
+		t.Errorf("Expected %v to be cleared, got %v", want, clearing.cleared)
+	}
+}
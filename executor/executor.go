@@ -2,6 +2,10 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
 	"time"
 )
 
@@ -11,8 +15,43 @@ type Task[T any] func(ctx context.Context) (T, error)
 // Result represents the result of a task execution
 type Result[T any] struct {
 	Value   T
-	Error   error
+	Error   error // Error from the last attempt
 	Attempt int
+
+	// AllErrors holds the error from every failed attempt, in order
+	AllErrors []error
+
+	// AttemptRecords holds the duration and outcome of every attempt that ran, in order
+	AttemptRecords []AttemptRecord
+
+	// TraceID and SpanID identify the OpenTelemetry span active in the context passed to Execute
+	// They're only filled in by the function set with WithSpanIDs, and empty without one
+	TraceID string
+	SpanID  string
+}
+
+// IsSuccess reports whether the last attempt succeeded
+func (r *Result[T]) IsSuccess() bool {
+	return r.Error == nil
+}
+
+// Get returns the value together with the last attempt's error
+func (r *Result[T]) Get() (T, error) {
+	return r.Value, r.Error
+}
+
+// Unwrap returns the last attempt's error, following the errors.Unwrap convention
+// Result can't implement error itself since its Error field would clash with an Error method
+func (r *Result[T]) Unwrap() error {
+	return r.Error
+}
+
+// AttemptRecord describes a single attempt that ran
+type AttemptRecord struct {
+	Attempt  int
+	Duration time.Duration
+	Timeout  time.Duration // Timeout the attempt ran with
+	Err      error
 }
 
 // RetryStrategy defines the interface for retry strategies
@@ -25,18 +64,70 @@ type RetryStrategy interface {
 	GetMaxAttempts() int
 }
 
+// ResettableStrategy is implemented by retry strategies that keep state across the attempts of one execution
+// Execute calls Reset at the start of every call, so a strategy shared through an executor starts each call afresh;
+// such strategies must not be used by concurrent executions
+type ResettableStrategy interface {
+	Reset()
+}
+
 // TimeoutStrategy defines the interface for timeout strategies
 type TimeoutStrategy interface {
 	// GetTimeout returns the timeout for a task based on the attempt number
 	GetTimeout(attempt int) time.Duration
 }
 
+// AttemptObserver is implemented by timeout strategies that adapt to how long attempts take
+// Execute reports every attempt that ran to its timeout strategy when it implements this
+type AttemptObserver interface {
+	ObserveAttempt(record AttemptRecord)
+}
+
 // Executor executes tasks with retry and timeout strategies
 type Executor struct {
 	retryStrategy   RetryStrategy
 	timeoutStrategy TimeoutStrategy
 	onRetry         func(attempt int, err error, delay time.Duration) // Optional callback for retry events
 	onTimeout       func(attempt int, timeout time.Duration)          // Optional callback for timeout events
+	onEvent         func(ctx context.Context, event Event)            // Optional callback receiving the task's context
+	semaphore       chan struct{}                                     // Limits concurrent attempts; nil means no limit
+	rateLimiter     *RateLimiter                                      // Limits how often attempts start; nil means no limit
+	clock           Clock                                             // Times the waits between attempts
+	minTimeout      time.Duration                                     // Attempts with less time left than this aren't started
+
+	// Optional hooks run before and after every attempt
+	beforeTask func(ctx context.Context, attempt int)
+	afterTask  func(ctx context.Context, attempt int, duration time.Duration, err error)
+
+	spanIDs func(ctx context.Context) (traceID, spanID string) // Fills in Result.TraceID and SpanID
+}
+
+// ErrInsufficientTime is returned when the context deadline leaves less than the minimum attempt timeout
+var ErrInsufficientTime = errors.New("not enough time left before the context deadline")
+
+// EventType identifies what happened during an execution
+type EventType string
+
+// Event types reported to the callback set with WithEventCallback
+const (
+	EventTimeout EventType = "timeout" // An attempt is starting with the given Timeout
+	EventRetry   EventType = "retry"   // An attempt failed with Err and will be retried after Delay
+	EventSuccess EventType = "success" // An attempt succeeded
+
+	// EventTimeoutClipped reports that the context deadline cut the attempt's Timeout
+	// short of the StrategyTimeout the timeout strategy asked for
+	EventTimeoutClipped EventType = "timeout_clipped"
+)
+
+// Event describes a step of an execution
+type Event struct {
+	Type    EventType
+	Attempt int
+	Err     error
+	Delay   time.Duration
+	Timeout time.Duration
+
+	StrategyTimeout time.Duration // Set for EventTimeoutClipped
 }
 
 // ExecutorOption defines a function type for configuring the executor
@@ -47,6 +138,7 @@ func NewExecutor(options ...ExecutorOption) *Executor {
 	executor := &Executor{
 		retryStrategy:   NewExponentialBackoffStrategy(3, time.Second, 2.0),
 		timeoutStrategy: NewFixedTimeoutStrategy(30 * time.Second),
+		clock:           realClock{},
 	}
 
 	for _, option := range options {
@@ -84,33 +176,196 @@ func WithTimeoutCallback(callback func(attempt int, timeout time.Duration)) Exec
 	}
 }
 
+// WithEventCallback sets a callback for timeout, retry and success events
+// It receives the caller's context, so values such as the request ID are available
+func WithEventCallback(callback func(ctx context.Context, event Event)) ExecutorOption {
+	return func(e *Executor) {
+		e.onEvent = callback
+	}
+}
+
+// WithBeforeTask sets a hook run right before every attempt, with the attempt's context
+// Go has no generic methods, so hooks stand in for typed task middleware for logging, tracing and metrics
+func WithBeforeTask(hook func(ctx context.Context, attempt int)) ExecutorOption {
+	return func(e *Executor) {
+		e.beforeTask = hook
+	}
+}
+
+// WithAfterTask sets a hook run after every attempt, whatever its outcome
+func WithAfterTask(hook func(ctx context.Context, attempt int, duration time.Duration, err error)) ExecutorOption {
+	return func(e *Executor) {
+		e.afterTask = hook
+	}
+}
+
+// WithClock sets the clock used to wait between attempts, e.g. a MockClock in tests
+func WithClock(clock Clock) ExecutorOption {
+	return func(e *Executor) {
+		e.clock = clock
+	}
+}
+
+// WithMinTimeout refuses to start an attempt with less than d left before the context deadline
+// Execute then fails fast with ErrInsufficientTime instead of running an attempt doomed to time out
+func WithMinTimeout(d time.Duration) ExecutorOption {
+	return func(e *Executor) {
+		e.minTimeout = d
+	}
+}
+
+// notify reports an event to the event callback, if set
+func (e *Executor) notify(ctx context.Context, event Event) {
+	if e.onEvent != nil {
+		e.onEvent(ctx, event)
+	}
+}
+
+// WithConcurrencyLimit limits how many attempts can run at the same time (0 means no limit)
+func WithConcurrencyLimit(n int) ExecutorOption {
+	return func(e *Executor) {
+		if n > 0 {
+			e.semaphore = make(chan struct{}, n)
+		} else {
+			e.semaphore = nil
+		}
+	}
+}
+
+// WithRateLimiter limits attempts to rps per second with bursts of up to burst (rps <= 0 means no limit)
+func WithRateLimiter(rps float64, burst int) ExecutorOption {
+	return func(e *Executor) {
+		if rps > 0 {
+			e.rateLimiter = NewRateLimiter(rps, burst)
+		} else {
+			e.rateLimiter = nil
+		}
+	}
+}
+
+// acquire waits for a concurrency slot, returning early if the context is cancelled
+func (e *Executor) acquire(ctx context.Context) error {
+	// Wait for a rate limit token first so a throttled attempt doesn't hold a concurrency slot
+	if e.rateLimiter != nil {
+		if err := e.rateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if e.semaphore == nil {
+		return nil
+	}
+
+	select {
+	case e.semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a concurrency slot taken by acquire
+func (e *Executor) release() {
+	if e.semaphore != nil {
+		<-e.semaphore
+	}
+}
+
 // Execute executes a task with retry and timeout logic
+// When several attempts fail, the returned error joins all of their errors
 func Execute[T any](executor *Executor, ctx context.Context, task Task[T]) (*Result[T], error) {
+	result, err := execute(executor, ctx, task)
+	result.TraceID, result.SpanID = executor.extractSpanIDs(ctx)
+	return result, err
+}
+
+// execute runs the attempts of Execute
+func execute[T any](executor *Executor, ctx context.Context, task Task[T]) (*Result[T], error) {
 	var lastResult Result[T]
+	var allErrors []error
+	var records []AttemptRecord
+
+	// Per-call strategy state must not leak in from a previous execution
+	resetStrategy(executor.retryStrategy)
 	maxAttempts := executor.retryStrategy.GetMaxAttempts()
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Create a context with timeout for this attempt
+		// Wait for a rate limit token and concurrency slot before starting the attempt
+		if err := executor.acquire(ctx); err != nil {
+			lastResult = Result[T]{
+				Error:          err,
+				Attempt:        attempt,
+				AllErrors:      allErrors,
+				AttemptRecords: records,
+			}
+			return &lastResult, err
+		}
+
+		// Create a context with timeout for this attempt, noting when the parent deadline is sooner
 		timeout := executor.timeoutStrategy.GetTimeout(attempt)
-		taskCtx, cancel := context.WithTimeout(ctx, timeout)
+		attemptTimeout := timeout
+		if deadline, ok := ctx.Deadline(); ok {
+			if remaining := time.Until(deadline); remaining < timeout {
+				if remaining < executor.minTimeout {
+					executor.release()
+					err := fmt.Errorf("%w: %s left, need %s: %w", ErrInsufficientTime, remaining, executor.minTimeout, context.DeadlineExceeded)
+					allErrors = append(allErrors, err)
+					lastResult = Result[T]{
+						Error:          err,
+						Attempt:        attempt,
+						AllErrors:      allErrors,
+						AttemptRecords: records,
+					}
+					return &lastResult, joinAttemptErrors(allErrors)
+				}
+
+				executor.notify(ctx, Event{Type: EventTimeoutClipped, Attempt: attempt, Timeout: remaining, StrategyTimeout: timeout})
+				attemptTimeout = remaining
+			}
+		}
+		// The attempt context derives from ctx, so values such as request IDs and trace spans reach the task
+		taskCtx, cancel := context.WithDeadline(ctx, time.Now().Add(timeout))
 
 		// Notify about timeout if callback is set
 		if executor.onTimeout != nil {
 			executor.onTimeout(attempt, timeout)
 		}
+		executor.notify(ctx, Event{Type: EventTimeout, Attempt: attempt, Timeout: timeout})
 
 		// Execute the task
+		if executor.beforeTask != nil {
+			executor.beforeTask(taskCtx, attempt)
+		}
+		started := executor.clock.Now()
 		value, err := task(taskCtx)
+		duration := executor.clock.Now().Sub(started)
+		if executor.afterTask != nil {
+			executor.afterTask(taskCtx, attempt, duration, err)
+		}
 		cancel() // Clean up the context
+		executor.release()
+
+		record := AttemptRecord{Attempt: attempt, Duration: duration, Timeout: attemptTimeout, Err: err}
+		records = append(records, record)
+		if observer, ok := executor.timeoutStrategy.(AttemptObserver); ok {
+			observer.ObserveAttempt(record)
+		}
+
+		if err != nil {
+			allErrors = append(allErrors, err)
+		}
 
 		lastResult = Result[T]{
-			Value:   value,
-			Error:   err,
-			Attempt: attempt,
+			Value:          value,
+			Error:          err,
+			Attempt:        attempt,
+			AllErrors:      allErrors,
+			AttemptRecords: records,
 		}
 
 		// If successful, return immediately
 		if err == nil {
+			executor.notify(ctx, Event{Type: EventSuccess, Attempt: attempt})
 			return &lastResult, nil
 		}
 
@@ -121,29 +376,82 @@ func Execute[T any](executor *Executor, ctx context.Context, task Task[T]) (*Res
 
 		// If this isn't the last attempt, wait before retrying
 		if attempt < maxAttempts {
-			delay := executor.retryStrategy.GetDelay(attempt)
+			delay := retryDelay(executor.retryStrategy, attempt, err)
 
 			// Notify about retry if callback is set
 			if executor.onRetry != nil {
 				executor.onRetry(attempt, err, delay)
 			}
+			executor.notify(ctx, Event{Type: EventRetry, Attempt: attempt, Err: err, Delay: delay})
 
-			// Wait with context cancellation support
-			select {
-			case <-ctx.Done():
-				lastResult.Error = ctx.Err()
-				return &lastResult, ctx.Err()
-			case <-time.After(delay):
-				// Continue to next attempt
+			// Wait with context cancellation support, keeping the attempt errors that led to the wait
+			if err := contextAwareSleep(ctx, executor.clock, delay); err != nil {
+				err = fmt.Errorf("%w: %w", err, joinAttemptErrors(allErrors))
+				lastResult.Error = err
+				return &lastResult, err
 			}
 		}
 	}
 
-	// Return the last result
-	return &lastResult, lastResult.Error
+	// Return the last result along with every attempt's error
+	return &lastResult, joinAttemptErrors(allErrors)
+}
+
+// contextAwareSleep waits for delay on the clock, returning early if the context is done
+// If the context's deadline comes before the delay ends it returns DeadlineExceeded
+// immediately, since the next attempt could never start in time
+func contextAwareSleep(ctx context.Context, clock Clock, delay time.Duration) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+		return context.DeadlineExceeded
+	}
+
+	var wait <-chan time.Time
+	if _, ok := clock.(realClock); ok || clock == nil {
+		// A stoppable timer avoids keeping time.After's timer alive after cancellation
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		wait = timer.C
+	} else {
+		wait = clock.After(delay)
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-wait:
+		return nil
+	}
+}
+
+// joinAttemptErrors combines attempt errors, returning a lone error unchanged
+func joinAttemptErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+	return errors.Join(errs...)
+}
+
+// ExecuteAll executes tasks concurrently, returning their results in the same order
+// The returned error joins the errors of all failed tasks
+func ExecuteAll[T any](executor *Executor, ctx context.Context, tasks []Task[T]) ([]*Result[T], error) {
+	results := make([]*Result[T], len(tasks))
+	errs := make([]error, len(tasks))
+
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func(i int, task Task[T]) {
+			defer wg.Done()
+			results[i], errs[i] = Execute(executor, ctx, task)
+		}(i, task)
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
 }
 
 // ExecuteSimple is a convenience function that returns just the value and error
+// The error joins the errors of all failed attempts, as returned by Execute
 func ExecuteSimple[T any](executor *Executor, ctx context.Context, task Task[T]) (T, error) {
 	result, err := Execute(executor, ctx, task)
 	if err != nil {
@@ -170,3 +478,32 @@ func ExecuteWithRetries[T any](ctx context.Context, maxRetries int, delay time.D
 	)
 	return ExecuteSimple(executor, ctx, task)
 }
+
+// ExecuteUntil retries a task with a fixed delay until it succeeds or deadline passes
+// Attempts and delays never run past the deadline, and errors that aren't retryable stop it early
+func ExecuteUntil[T any](ctx context.Context, deadline time.Time, delay time.Duration, task Task[T]) (T, error) {
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(math.MaxInt, delay)),
+		WithTimeoutStrategy(NewFixedTimeoutStrategy(time.Until(deadline))),
+		WithMinTimeout(time.Millisecond),
+	)
+	result, err := Execute(executor, ctx, task)
+	if err == nil {
+		return result.Value, result.Error
+	}
+
+	var zero T
+	stopped := ctx.Err()
+	if stopped == nil && errors.Is(result.Error, context.DeadlineExceeded) {
+		// The delay before the next attempt would have run past the deadline
+		stopped = context.DeadlineExceeded
+	}
+	if n := len(result.AttemptRecords); n > 0 && stopped != nil {
+		// Report the last attempt rather than joining them all, which could be a great many
+		return zero, fmt.Errorf("gave up after %d attempts: %w: %w", n, stopped, result.AttemptRecords[n-1].Err)
+	}
+	return zero, err
+}
@@ -3,6 +3,11 @@ package executor
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -75,6 +80,46 @@ func TestExecutorMaxRetriesExceeded(t *testing.T) {
 	}
 }
 
+func TestExecutorCollectsAllAttemptErrors(t *testing.T) {
+	errNetwork := errors.New("connection reset")
+	errServer := errors.New("server error")
+	errAuth := errors.New("unauthorized")
+	attemptErrors := []error{errNetwork, errServer, errAuth}
+
+	attempts := 0
+	task := func(ctx context.Context) (string, error) {
+		err := attemptErrors[attempts]
+		attempts++
+		return "", err
+	}
+
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(3, time.Millisecond)),
+	)
+
+	result, err := Execute(executor, context.Background(), task)
+	if err == nil {
+		t.Fatal("Expected error when all attempts fail")
+	}
+
+	if len(result.AllErrors) != 3 {
+		t.Fatalf("Expected 3 attempt errors, got %d", len(result.AllErrors))
+	}
+
+	for i, expected := range attemptErrors {
+		if result.AllErrors[i] != expected {
+			t.Errorf("Expected attempt %d error %v, got %v", i+1, expected, result.AllErrors[i])
+		}
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected combined error to include %v", expected)
+		}
+	}
+
+	if result.Error != errAuth {
+		t.Errorf("Expected Result.Error to be the last attempt's error, got %v", result.Error)
+	}
+}
+
 func TestExecutorWithTimeout(t *testing.T) {
 	task := func(ctx context.Context) (string, error) {
 		// Simulate a long-running task
@@ -136,6 +181,78 @@ func TestExecuteWithRetries(t *testing.T) {
 	}
 }
 
+func TestExecuteUntil(t *testing.T) {
+	t.Run("succeeds before the deadline", func(t *testing.T) {
+		attempts := 0
+		result, err := ExecuteUntil(context.Background(), time.Now().Add(time.Second), 5*time.Millisecond, func(ctx context.Context) (int, error) {
+			attempts++
+			if attempts < 3 {
+				return 0, errors.New("link down")
+			}
+			return attempts, nil
+		})
+		if err != nil || result != 3 {
+			t.Errorf("Expected success on attempt 3, got %d and %v", result, err)
+		}
+	})
+
+	t.Run("retries until the deadline", func(t *testing.T) {
+		failure := errors.New("link down")
+		attempts := 0
+		deadline := time.Now().Add(60 * time.Millisecond)
+		_, err := ExecuteUntil(context.Background(), deadline, 10*time.Millisecond, func(ctx context.Context) (int, error) {
+			attempts++
+			return 0, failure
+		})
+		if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, failure) {
+			t.Errorf("Expected deadline and last attempt errors, got %v", err)
+		}
+		if attempts < 3 {
+			t.Errorf("Expected several attempts before the deadline, got %d", attempts)
+		}
+		if late := time.Since(deadline); late > 20*time.Millisecond {
+			t.Errorf("Expected to give up at the deadline, returned %s after it", late)
+		}
+	})
+
+	t.Run("stops before a delay that would pass the deadline", func(t *testing.T) {
+		attempts := 0
+		started := time.Now()
+		_, err := ExecuteUntil(context.Background(), started.Add(50*time.Millisecond), time.Second, func(ctx context.Context) (int, error) {
+			attempts++
+			return 0, errors.New("link down")
+		})
+		if attempts != 1 || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected one attempt and a deadline error, got %d and %v", attempts, err)
+		}
+		if elapsed := time.Since(started); elapsed > 40*time.Millisecond {
+			t.Errorf("Expected to give up without waiting for the delay, took %s", elapsed)
+		}
+	})
+
+	t.Run("deadline already passed", func(t *testing.T) {
+		ran := false
+		_, err := ExecuteUntil(context.Background(), time.Now().Add(-time.Second), time.Millisecond, func(ctx context.Context) (int, error) {
+			ran = true
+			return 0, nil
+		})
+		if ran || !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected no attempt and a deadline error, got ran=%v and %v", ran, err)
+		}
+	})
+
+	t.Run("non-retryable errors stop early", func(t *testing.T) {
+		attempts := 0
+		_, err := ExecuteUntil(context.Background(), time.Now().Add(time.Second), time.Millisecond, func(ctx context.Context) (int, error) {
+			attempts++
+			return 0, classifiedError{retryable: false}
+		})
+		if attempts != 1 || err == nil || errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a single failed attempt, got %d and %v", attempts, err)
+		}
+	})
+}
+
 func TestExecutorWithCallbacks(t *testing.T) {
 	var retryCallbacks []int
 	var timeoutCallbacks []int
@@ -183,6 +300,248 @@ func TestExecutorWithCallbacks(t *testing.T) {
 	}
 }
 
+func TestExecutorEventCallbackReceivesRequestID(t *testing.T) {
+	var events []Event
+	var requestIDs []string
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(2, time.Millisecond)),
+		WithEventCallback(func(ctx context.Context, event Event) {
+			events = append(events, event)
+			requestIDs = append(requestIDs, RequestIDFromContext(ctx))
+		}),
+	)
+
+	attempts := 0
+	task := func(ctx context.Context) (string, error) {
+		attempts++
+		if RequestIDFromContext(ctx) != "cycle-1" {
+			t.Errorf("Expected request ID in task context, got %q", RequestIDFromContext(ctx))
+		}
+		if attempts == 1 {
+			return "", errors.New("temporary failure")
+		}
+		return "ok", nil
+	}
+
+	ctx := WithRequestID(context.Background(), "cycle-1")
+	if _, err := ExecuteSimple(executor, ctx, task); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []EventType{EventTimeout, EventRetry, EventTimeout, EventSuccess}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, eventType := range expected {
+		if events[i].Type != eventType {
+			t.Errorf("Event %d: expected %s, got %s", i, eventType, events[i].Type)
+		}
+		if requestIDs[i] != "cycle-1" {
+			t.Errorf("Event %d: expected request ID cycle-1, got %q", i, requestIDs[i])
+		}
+	}
+}
+
+func TestExecuteAllWithConcurrencyLimit(t *testing.T) {
+	var mu sync.Mutex
+	running := 0
+	maxRunning := 0
+
+	task := func(ctx context.Context) (int, error) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return 1, nil
+	}
+
+	tasks := make([]Task[int], 10)
+	for i := range tasks {
+		tasks[i] = task
+	}
+
+	executor := NewExecutor(WithConcurrencyLimit(3))
+
+	results, err := ExecuteAll(executor, context.Background(), tasks)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 10 {
+		t.Fatalf("Expected 10 results, got %d", len(results))
+	}
+
+	if maxRunning > 3 {
+		t.Errorf("Expected at most 3 concurrent tasks, got %d", maxRunning)
+	}
+}
+
+func TestConcurrencyLimitReleasedOnError(t *testing.T) {
+	executor := NewExecutor(
+		WithConcurrencyLimit(1),
+		WithRetryStrategy(NewFixedDelayStrategy(2, time.Millisecond)),
+	)
+
+	failing := func(ctx context.Context) (string, error) {
+		return "", errors.New("failure")
+	}
+
+	if _, err := Execute(executor, context.Background(), failing); err == nil {
+		t.Fatal("Expected error from failing task")
+	}
+
+	// A leaked slot would block this call until the context expires
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	succeeding := func(ctx context.Context) (string, error) {
+		return "ok", nil
+	}
+
+	if _, err := ExecuteSimple(executor, ctx, succeeding); err != nil {
+		t.Errorf("Expected slot to be released after failures, got %v", err)
+	}
+}
+
+func TestExecutorWithRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	executor := NewExecutor(
+		WithRetryStrategy(NewNoRetryStrategy()),
+		WithRateLimiter(20, 1), // One attempt every 50ms
+	)
+
+	var starts []time.Time
+	task := func(ctx context.Context) (int, error) {
+		starts = append(starts, time.Now())
+		return 0, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err := ExecuteSimple(executor, ctx, task); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	for i := 1; i < len(starts); i++ {
+		if gap := starts[i].Sub(starts[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("Expected executions to be spaced ~50ms apart, gap %d was %s", i, gap)
+		}
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(1, 1)
+
+	// The first token is available immediately
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected first token without waiting, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected DeadlineExceeded while waiting for a token, got %v", err)
+	}
+}
+
+func TestExponentialBackoffDegenerateInputs(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		baseDelay   time.Duration
+		multiplier  float64
+	}{
+		{"zero multiplier", 3, time.Second, 0},
+		{"fractional multiplier", 3, time.Second, 0.5},
+		{"NaN multiplier", 3, time.Second, math.NaN()},
+		{"negative base delay", 3, -time.Second, 2},
+		{"zero base delay", 3, 0, 2},
+		{"zero attempts", 0, time.Second, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewExponentialBackoffStrategyE(tt.maxAttempts, tt.baseDelay, tt.multiplier); err == nil {
+				t.Error("Expected NewExponentialBackoffStrategyE to reject the input")
+			}
+
+			// The non-erroring constructor clamps to safe values instead
+			strategy := NewExponentialBackoffStrategy(tt.maxAttempts, tt.baseDelay, tt.multiplier)
+			if strategy.GetMaxAttempts() < 1 {
+				t.Errorf("Expected at least 1 attempt, got %d", strategy.GetMaxAttempts())
+			}
+			for attempt := 1; attempt <= 3; attempt++ {
+				if delay := strategy.GetDelay(attempt); delay <= 0 {
+					t.Errorf("Expected positive delay for attempt %d, got %s", attempt, delay)
+				}
+			}
+		})
+	}
+
+	if _, err := NewExponentialBackoffStrategyE(3, time.Second, 2); err != nil {
+		t.Errorf("Expected valid input to be accepted, got %v", err)
+	}
+}
+
+func TestExponentialBackoffLargeAttemptDoesNotOverflow(t *testing.T) {
+	strategy := NewExponentialBackoffStrategy(1000, time.Second, 10).WithMaxDelay(time.Minute)
+
+	if delay := strategy.GetDelay(500); delay != time.Minute {
+		t.Errorf("Expected delay capped at 1m, got %s", delay)
+	}
+}
+
+func TestRandomizedDelayStrategyDistribution(t *testing.T) {
+	const (
+		samples = 10000
+		buckets = 10
+	)
+	minDelay, maxDelay := 100*time.Millisecond, 200*time.Millisecond
+
+	strategy := NewRandomizedDelayStrategy(3, minDelay, maxDelay).WithRand(rand.New(rand.NewPCG(1, 2)))
+
+	counts := make([]int, buckets)
+	bucketWidth := (maxDelay - minDelay) / buckets
+	for i := 0; i < samples; i++ {
+		delay := strategy.GetDelay(i%3 + 1)
+		if delay < minDelay || delay > maxDelay {
+			t.Fatalf("Delay %s outside [%s, %s]", delay, minDelay, maxDelay)
+		}
+
+		bucket := int((delay - minDelay) / bucketWidth)
+		if bucket == buckets {
+			bucket-- // maxDelay itself falls in the last bucket
+		}
+		counts[bucket]++
+	}
+
+	// Chi-square goodness of fit against a uniform distribution
+	expected := float64(samples) / buckets
+	chiSquare := 0.0
+	for _, count := range counts {
+		diff := float64(count) - expected
+		chiSquare += diff * diff / expected
+	}
+
+	// Critical value for 9 degrees of freedom at p = 0.01
+	if chiSquare > 21.67 {
+		t.Errorf("Delays are not uniformly distributed: chi-square %.2f, buckets %v", chiSquare, counts)
+	}
+
+	if !strategy.ShouldRetry(2, errors.New("fail")) || strategy.ShouldRetry(3, errors.New("fail")) {
+		t.Error("Expected retries to stop after 3 attempts")
+	}
+}
+
 func TestConditionalRetryStrategy(t *testing.T) {
 	shouldRetry := func(attempt int, err error) bool {
 		// Only retry on specific error
@@ -202,6 +561,67 @@ func TestConditionalRetryStrategy(t *testing.T) {
 	}
 }
 
+func TestChainRetryStrategy(t *testing.T) {
+	strategy := NewChainRetryStrategy(
+		NewFixedDelayStrategy(2, time.Millisecond),
+		NewExponentialBackoffStrategy(3, 10*time.Millisecond, 2.0),
+	)
+
+	if strategy.GetMaxAttempts() != 5 {
+		t.Errorf("Expected 5 max attempts, got %d", strategy.GetMaxAttempts())
+	}
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, time.Millisecond},
+		{2, time.Millisecond}, // Last attempt of the fixed strategy
+		{3, 10 * time.Millisecond},
+		{4, 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		result := strategy.GetDelay(tt.attempt)
+		if result != tt.expected {
+			t.Errorf("GetDelay(%d) = %v, want %v", tt.attempt, result, tt.expected)
+		}
+	}
+
+	// Should move on to the exponential strategy after the fixed strategy is exhausted
+	if !strategy.ShouldRetry(2, errors.New("fail")) {
+		t.Error("Should retry at the transition boundary")
+	}
+
+	if strategy.ShouldRetry(5, errors.New("fail")) {
+		t.Error("Should not retry after all strategies are exhausted")
+	}
+}
+
+func TestExecutorWithChainRetryStrategy(t *testing.T) {
+	attempts := 0
+	task := func(ctx context.Context) (string, error) {
+		attempts++
+		return "", errors.New("persistent failure")
+	}
+
+	executor := NewExecutor(
+		WithRetryStrategy(NewChainRetryStrategy(
+			NewFixedDelayStrategy(2, time.Millisecond),
+			NewFixedDelayStrategy(3, 2*time.Millisecond),
+		)),
+	)
+
+	result, err := Execute(executor, context.Background(), task)
+	if err == nil {
+		t.Fatal("Expected error when all chained strategies are exhausted")
+	}
+
+	if result.Attempt != 5 || attempts != 5 {
+		t.Errorf("Expected 5 attempts, got %d (task ran %d times)", result.Attempt, attempts)
+	}
+}
+
 func TestProgressiveTimeoutStrategy(t *testing.T) {
 	strategy := NewProgressiveTimeoutStrategy(time.Second, 2.0, 10*time.Second)
 
@@ -260,3 +680,526 @@ func TestExecutorDifferentTaskTypes(t *testing.T) {
 		t.Errorf("Struct task failed: %v, result: %+v", err, structResult)
 	}
 }
+
+func TestExecutorHonorsRetryAfterError(t *testing.T) {
+	var delays []time.Duration
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(3, time.Hour)),
+		WithRetryCallback(func(attempt int, err error, delay time.Duration) {
+			delays = append(delays, delay)
+		}),
+	)
+
+	attempts := 0
+	task := func(ctx context.Context) (string, error) {
+		attempts++
+		if attempts == 1 {
+			return "", &RetryAfterError{Err: errors.New("rate limited"), RetryAfter: 5 * time.Millisecond}
+		}
+		return "ok", nil
+	}
+
+	start := time.Now()
+	value, err := ExecuteSimple(executor, context.Background(), task)
+	if err != nil || value != "ok" {
+		t.Fatalf("Expected ok, got %q, %v", value, err)
+	}
+
+	if len(delays) != 1 || delays[0] != 5*time.Millisecond {
+		t.Errorf("Expected the Retry-After delay instead of the strategy's, got %v", delays)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected retry after 5ms, took %s", elapsed)
+	}
+}
+
+func TestExecutorKeepsCauseWhenRetryAfterPassesDeadline(t *testing.T) {
+	executor := NewExecutor(WithRetryStrategy(NewFixedDelayStrategy(3, time.Millisecond)))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	limited := errors.New("rate limited")
+	_, err := ExecuteSimple(executor, ctx, func(ctx context.Context) (string, error) {
+		return "", &RetryAfterError{Err: limited, RetryAfter: time.Hour}
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, limited) {
+		t.Errorf("Expected the deadline error to keep the rate limit cause, got %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		header string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{"0", 0, true},
+		{"Tue, 02 Jan 2024 03:04:35 GMT", 30 * time.Second, true},
+		{"Tue, 02 Jan 2024 03:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-5", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := ParseRetryAfter(tt.header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseRetryAfter(%q) = %s, %v, want %s, %v", tt.header, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestExecutorRetryDelayRespectsDeadline(t *testing.T) {
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(3, 10*time.Second)),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	deadline, _ := ctx.Deadline()
+
+	task := func(ctx context.Context) (string, error) {
+		return "", errors.New("temporary failure")
+	}
+
+	_, err := Execute(executor, ctx, task)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected DeadlineExceeded, got %v", err)
+	}
+
+	if late := time.Since(deadline); late > 10*time.Millisecond {
+		t.Errorf("Expected to return within 10ms of the deadline, returned %s after it", late)
+	}
+}
+
+func TestContextAwareSleep(t *testing.T) {
+	if err := contextAwareSleep(context.Background(), realClock{}, time.Millisecond); err != nil {
+		t.Errorf("Expected sleep to complete, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	if err := contextAwareSleep(ctx, realClock{}, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected sleep to stop on cancellation, took %s", elapsed)
+	}
+}
+
+func TestExecutorBackoffSequenceWithMockClock(t *testing.T) {
+	clock := NewMockClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	executor := NewExecutor(
+		WithRetryStrategy(NewExponentialBackoffStrategy(4, time.Second, 2.0)),
+		WithClock(clock),
+	)
+
+	attempts := 0
+	task := func(ctx context.Context) (int, error) {
+		attempts++
+		if attempts < 4 {
+			return 0, errors.New("temporary failure")
+		}
+		return attempts, nil
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := ExecuteSimple(executor, context.Background(), task)
+		done <- err
+	}()
+
+	// Each wait must not finish until the clock passes the full backoff delay
+	for _, delay := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second} {
+		waitForPendingWaiter(t, clock)
+
+		clock.Advance(delay - time.Millisecond)
+		if clock.PendingWaiters() != 1 {
+			t.Fatalf("Expected retry to still be waiting %s before its %s delay ends", time.Millisecond, delay)
+		}
+		clock.Advance(time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Expected success on the fourth attempt, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Execute did not finish after the clock was advanced")
+	}
+
+	if attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", attempts)
+	}
+}
+
+// waitForPendingWaiter blocks until the executor is waiting on the mock clock
+func waitForPendingWaiter(t *testing.T, clock *MockClock) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for clock.PendingWaiters() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the executor to wait on the clock")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExecutorReportsClippedTimeout(t *testing.T) {
+	var events []Event
+	executor := NewExecutor(
+		WithRetryStrategy(NewNoRetryStrategy()),
+		WithTimeoutStrategy(NewFixedTimeoutStrategy(time.Minute)),
+		WithEventCallback(func(ctx context.Context, event Event) {
+			if event.Type == EventTimeoutClipped {
+				events = append(events, event)
+			}
+		}),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	task := func(ctx context.Context) (string, error) {
+		return "ok", nil
+	}
+	if _, err := ExecuteSimple(executor, ctx, task); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected one clipped timeout event, got %d", len(events))
+	}
+	if events[0].StrategyTimeout != time.Minute {
+		t.Errorf("Expected strategy timeout of 1m, got %s", events[0].StrategyTimeout)
+	}
+	if events[0].Timeout <= 0 || events[0].Timeout > time.Second {
+		t.Errorf("Expected clipped timeout within the 1s deadline, got %s", events[0].Timeout)
+	}
+
+	// Without a shorter parent deadline nothing is clipped
+	events = nil
+	if _, err := ExecuteSimple(executor, context.Background(), task); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if len(events) != 0 {
+		t.Errorf("Expected no clipped timeout events, got %d", len(events))
+	}
+}
+
+func TestExecutorMinTimeoutFailsFast(t *testing.T) {
+	executor := NewExecutor(
+		WithTimeoutStrategy(NewFixedTimeoutStrategy(time.Minute)),
+		WithMinTimeout(time.Second),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	called := false
+	task := func(ctx context.Context) (string, error) {
+		called = true
+		return "ok", nil
+	}
+
+	_, err := ExecuteSimple(executor, ctx, task)
+	if !errors.Is(err, ErrInsufficientTime) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrInsufficientTime wrapping DeadlineExceeded, got %v", err)
+	}
+	if called {
+		t.Error("Expected the task not to run with too little time left")
+	}
+}
+
+func TestRetryStrategiesWithMinDelay(t *testing.T) {
+	if delay := NewFixedDelayStrategy(3, 0).WithMinDelay(100 * time.Millisecond).GetDelay(1); delay != 100*time.Millisecond {
+		t.Errorf("Expected fixed delay of 0 raised to 100ms, got %s", delay)
+	}
+
+	linear := NewLinearBackoffStrategy(3, 0, 50*time.Millisecond).WithMinDelay(100 * time.Millisecond)
+	if delay := linear.GetDelay(1); delay != 100*time.Millisecond {
+		t.Errorf("Expected linear delay of 0 raised to 100ms, got %s", delay)
+	}
+	if delay := linear.GetDelay(4); delay != 150*time.Millisecond {
+		t.Errorf("Expected linear delay above the minimum to be unchanged, got %s", delay)
+	}
+
+	exponential := NewExponentialBackoffStrategy(10, 10*time.Millisecond, 2.0).
+		WithMinDelay(100 * time.Millisecond).
+		WithMaxDelay(time.Second)
+	if delay := exponential.GetDelay(1); delay != 100*time.Millisecond {
+		t.Errorf("Expected 10ms raised to 100ms, got %s", delay)
+	}
+	if delay := exponential.GetDelay(5); delay != 160*time.Millisecond {
+		t.Errorf("Expected 160ms between the bounds to be unchanged, got %s", delay)
+	}
+	if delay := exponential.GetDelay(10); delay != time.Second {
+		t.Errorf("Expected delay capped at 1s, got %s", delay)
+	}
+
+	// A zero maximum would compute 0, which the minimum still raises
+	if delay := NewExponentialBackoffStrategy(3, time.Second, 2.0).WithMaxDelay(0).WithMinDelay(100 * time.Millisecond).GetDelay(1); delay != 100*time.Millisecond {
+		t.Errorf("Expected capped delay of 0 raised to 100ms, got %s", delay)
+	}
+}
+
+func TestAdaptiveTimeoutStrategyConverges(t *testing.T) {
+	clock := NewMockClock(time.Unix(0, 0))
+	strategy := NewAdaptiveTimeoutStrategy(10*time.Second, 100*time.Millisecond, 10*time.Second, 4, 2.0)
+	exec := NewExecutor(
+		WithRetryStrategy(NewNoRetryStrategy()),
+		WithTimeoutStrategy(strategy),
+		WithClock(clock),
+	)
+
+	if timeout := strategy.GetTimeout(1); timeout != 10*time.Second {
+		t.Fatalf("Expected initial timeout before any observation, got %s", timeout)
+	}
+
+	// A slow start followed by attempts alternating around 200ms
+	durations := []time.Duration{time.Second, 3 * time.Second}
+	for i := 0; i < 20; i++ {
+		durations = append(durations, 150*time.Millisecond, 250*time.Millisecond)
+	}
+
+	for _, duration := range durations {
+		result, err := Execute(exec, context.Background(), func(ctx context.Context) (string, error) {
+			clock.Advance(duration)
+			return "ok", nil
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result.AttemptRecords) != 1 || result.AttemptRecords[0].Duration != duration {
+			t.Fatalf("Expected one attempt record of %s, got %+v", duration, result.AttemptRecords)
+		}
+	}
+
+	// The EMA settles near 200ms, so the timeout settles near twice that
+	if timeout := strategy.GetTimeout(1); timeout < 350*time.Millisecond || timeout > 450*time.Millisecond {
+		t.Errorf("Expected timeout to converge near 400ms, got %s", timeout)
+	}
+
+	// Bounds apply to the scaled average
+	strategy.ObserveAttempt(AttemptRecord{Duration: time.Minute})
+	if timeout := strategy.GetTimeout(1); timeout != 10*time.Second {
+		t.Errorf("Expected timeout capped at 10s, got %s", timeout)
+	}
+
+	fast := NewAdaptiveTimeoutStrategy(time.Second, 100*time.Millisecond, time.Second, 4, 2.0)
+	fast.ObserveAttempt(AttemptRecord{Duration: time.Millisecond})
+	if timeout := fast.GetTimeout(1); timeout != 100*time.Millisecond {
+		t.Errorf("Expected timeout raised to the 100ms minimum, got %s", timeout)
+	}
+}
+
+func TestResultHelpers(t *testing.T) {
+	exec := NewExecutor(WithRetryStrategy(NewNoRetryStrategy()))
+
+	result, _ := Execute(exec, context.Background(), func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if !result.IsSuccess() {
+		t.Error("Expected IsSuccess for a successful task")
+	}
+	if value, err := result.Get(); value != 42 || err != nil {
+		t.Errorf("Expected 42 and no error, got %d and %v", value, err)
+	}
+	if result.Unwrap() != nil {
+		t.Errorf("Expected no error to unwrap, got %v", result.Unwrap())
+	}
+
+	result, _ = Execute(exec, context.Background(), func(ctx context.Context) (int, error) {
+		return 0, fmt.Errorf("lookup failed: %w", context.DeadlineExceeded)
+	})
+	if result.IsSuccess() {
+		t.Error("Expected IsSuccess to be false for a failed task")
+	}
+	if _, err := result.Get(); err == nil {
+		t.Error("Expected Get to return the error")
+	}
+	if !errors.Is(result.Unwrap(), context.DeadlineExceeded) {
+		t.Errorf("Expected unwrapped error to match DeadlineExceeded, got %v", result.Unwrap())
+	}
+}
+
+func TestExecutorBeforeAndAfterTaskHooks(t *testing.T) {
+	var calls []string
+	exec := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(3, 0)),
+		WithBeforeTask(func(ctx context.Context, attempt int) {
+			calls = append(calls, fmt.Sprintf("before %d", attempt))
+		}),
+		WithAfterTask(func(ctx context.Context, attempt int, duration time.Duration, err error) {
+			calls = append(calls, fmt.Sprintf("after %d err=%v", attempt, err != nil))
+		}),
+	)
+
+	attempts := 0
+	_, err := ExecuteSimple(exec, context.Background(), func(ctx context.Context) (string, error) {
+		attempts++
+		if attempts < 2 {
+			return "", errors.New("temporary")
+		}
+		return "ok", nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []string{"before 1", "after 1 err=true", "before 2", "after 2 err=false"}
+	if !slices.Equal(calls, want) {
+		t.Errorf("Expected hooks %v, got %v", want, calls)
+	}
+}
+
+// budgetStrategy allows a fixed number of retries per execution, counting them down as they happen
+type budgetStrategy struct {
+	budget, remaining int
+	resets            int
+}
+
+func (b *budgetStrategy) ShouldRetry(attempt int, err error) bool {
+	if b.remaining == 0 {
+		return false
+	}
+	b.remaining--
+	return true
+}
+
+func (b *budgetStrategy) GetDelay(attempt int) time.Duration {
+	return time.Millisecond
+}
+
+func (b *budgetStrategy) GetMaxAttempts() int {
+	return 10
+}
+
+func (b *budgetStrategy) Reset() {
+	b.remaining = b.budget
+	b.resets++
+}
+
+func TestExecuteResetsStrategyStateEachCall(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		chain bool
+	}{
+		{"direct", false},
+		{"chained", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := &budgetStrategy{budget: 2}
+			var strategy RetryStrategy = budget
+			if tt.chain {
+				strategy = NewChainRetryStrategy(budget)
+			}
+			executor := NewExecutor(WithRetryStrategy(strategy))
+
+			for call := 1; call <= 2; call++ {
+				attempts := 0
+				Execute(executor, context.Background(), func(ctx context.Context) (string, error) {
+					attempts++
+					return "", errors.New("failure")
+				})
+				if attempts != 3 {
+					t.Errorf("Call %d: expected 3 attempts, got %d", call, attempts)
+				}
+			}
+			if budget.resets != 2 {
+				t.Errorf("Expected a reset per call, got %d", budget.resets)
+			}
+		})
+	}
+}
+
+// classifiedError reports its own retryability, like ddns.ProviderError
+type classifiedError struct {
+	retryable bool
+}
+
+func (e classifiedError) Error() string     { return "classified" }
+func (e classifiedError) IsRetryable() bool { return e.retryable }
+
+func TestRetryStrategiesRespectRetryableErrors(t *testing.T) {
+	strategies := map[string]RetryStrategy{
+		"exponential": NewExponentialBackoffStrategy(3, time.Millisecond, 2),
+		"linear":      NewLinearBackoffStrategy(3, time.Millisecond, time.Millisecond),
+		"fixed":       NewFixedDelayStrategy(3, time.Millisecond),
+		"randomized":  NewRandomizedDelayStrategy(3, time.Millisecond, 2*time.Millisecond),
+		"conditional": NewConditionalRetryStrategy(3, time.Millisecond, nil, nil),
+	}
+
+	for name, strategy := range strategies {
+		if strategy.ShouldRetry(1, fmt.Errorf("wrapped: %w", classifiedError{retryable: false})) {
+			t.Errorf("%s: expected a non-retryable error not to be retried", name)
+		}
+		if !strategy.ShouldRetry(1, classifiedError{retryable: true}) {
+			t.Errorf("%s: expected a retryable error to be retried", name)
+		}
+		if !strategy.ShouldRetry(1, errors.New("unclassified")) {
+			t.Errorf("%s: expected an unclassified error to be retried", name)
+		}
+	}
+}
+
+// traceKey is a context key standing in for values like trace spans
+type traceKey struct{}
+
+func TestExecutePropagatesContextValues(t *testing.T) {
+	var hookValues []interface{}
+	executor := NewExecutor(
+		WithRetryStrategy(NewFixedDelayStrategy(3, time.Millisecond)),
+		WithTimeoutStrategy(NewFixedTimeoutStrategy(time.Minute)),
+		WithBeforeTask(func(ctx context.Context, attempt int) {
+			hookValues = append(hookValues, ctx.Value(traceKey{}))
+		}),
+		WithAfterTask(func(ctx context.Context, attempt int, duration time.Duration, err error) {
+			hookValues = append(hookValues, ctx.Value(traceKey{}))
+		}),
+	)
+
+	parentDeadline := time.Now().Add(time.Hour)
+	ctx, cancel := context.WithDeadline(context.WithValue(context.Background(), traceKey{}, "span-1"), parentDeadline)
+	defer cancel()
+	ctx = WithRequestID(ctx, "cycle-1")
+
+	attempts := 0
+	task := func(taskCtx context.Context) (string, error) {
+		attempts++
+		if taskCtx.Value(traceKey{}) != "span-1" || RequestIDFromContext(taskCtx) != "cycle-1" {
+			t.Errorf("Attempt %d: expected parent values in the task context, got %v and %q", attempts, taskCtx.Value(traceKey{}), RequestIDFromContext(taskCtx))
+		}
+		deadline, ok := taskCtx.Deadline()
+		if !ok || !deadline.Before(parentDeadline) || time.Until(deadline) > time.Minute {
+			t.Errorf("Attempt %d: expected the per-attempt deadline, got %v (set: %v)", attempts, deadline, ok)
+		}
+		if attempts < 3 {
+			return "", errors.New("temporary failure")
+		}
+		return "ok", nil
+	}
+
+	if _, err := ExecuteSimple(executor, ctx, task); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(hookValues) != 6 {
+		t.Fatalf("Expected both hooks to run for every attempt, got %d calls", len(hookValues))
+	}
+	for i, value := range hookValues {
+		if value != "span-1" {
+			t.Errorf("Hook call %d: expected the parent value, got %v", i, value)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryAfterError wraps an error with the delay the server asked for before the next attempt
+// Execute waits for RetryAfter instead of the retry strategy's delay when a task returns it
+type RetryAfterError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *RetryAfterError) Error() string {
+	return fmt.Sprintf("%v (retry after %s)", e.Err, e.RetryAfter)
+}
+
+// Unwrap returns the wrapped error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// ParseRetryAfter parses a Retry-After header given as delay seconds or an HTTP-date
+// ok is false if the header is empty or malformed; dates in the past yield zero
+func ParseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(header)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := date.Sub(now); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// retryDelay returns the delay before the next attempt, preferring a server-requested delay
+func retryDelay(strategy RetryStrategy, attempt int, err error) time.Duration {
+	var retryAfter *RetryAfterError
+	if errors.As(err, &retryAfter) {
+		return retryAfter.RetryAfter
+	}
+	return strategy.GetDelay(attempt)
+}
@@ -1,20 +1,57 @@
 package executor
 
 import (
+	"errors"
+	"fmt"
 	"math"
+	"math/rand/v2"
 	"time"
 )
 
+// Safe defaults used when a backoff strategy is constructed with out-of-range values
+const (
+	defaultBaseDelay  = time.Second
+	defaultMultiplier = 2.0
+)
+
+// RetryableError is implemented by errors that know whether retrying can succeed, such as ddns.ProviderError
+type RetryableError interface {
+	error
+	IsRetryable() bool
+}
+
+// IsRetryable reports whether err is worth retrying; errors that don't classify themselves are
+func IsRetryable(err error) bool {
+	var classified RetryableError
+	if errors.As(err, &classified) {
+		return classified.IsRetryable()
+	}
+	return err != nil
+}
+
 // ExponentialBackoffStrategy implements exponential backoff retry logic
 type ExponentialBackoffStrategy struct {
 	maxAttempts int
 	baseDelay   time.Duration
 	multiplier  float64
 	maxDelay    time.Duration
+	minDelay    time.Duration
 }
 
 // NewExponentialBackoffStrategy creates a new exponential backoff strategy
+// Out-of-range values are clamped: maxAttempts to at least 1, a non-positive baseDelay
+// to one second and a multiplier below 1 (or NaN) to 2
 func NewExponentialBackoffStrategy(maxAttempts int, baseDelay time.Duration, multiplier float64) *ExponentialBackoffStrategy {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	if baseDelay <= 0 {
+		baseDelay = defaultBaseDelay
+	}
+	if !(multiplier >= 1) || math.IsInf(multiplier, 0) {
+		multiplier = defaultMultiplier
+	}
+
 	return &ExponentialBackoffStrategy{
 		maxAttempts: maxAttempts,
 		baseDelay:   baseDelay,
@@ -23,12 +60,34 @@ func NewExponentialBackoffStrategy(maxAttempts int, baseDelay time.Duration, mul
 	}
 }
 
+// NewExponentialBackoffStrategyE creates an exponential backoff strategy, rejecting out-of-range values
+// instead of clamping them
+func NewExponentialBackoffStrategyE(maxAttempts int, baseDelay time.Duration, multiplier float64) (*ExponentialBackoffStrategy, error) {
+	if maxAttempts < 1 {
+		return nil, fmt.Errorf("max attempts must be at least 1, got %d", maxAttempts)
+	}
+	if baseDelay <= 0 {
+		return nil, fmt.Errorf("base delay must be positive, got %s", baseDelay)
+	}
+	if !(multiplier >= 1) || math.IsInf(multiplier, 0) {
+		return nil, fmt.Errorf("multiplier must be a finite number of at least 1, got %v", multiplier)
+	}
+
+	return NewExponentialBackoffStrategy(maxAttempts, baseDelay, multiplier), nil
+}
+
 // WithMaxDelay sets the maximum delay between retries
 func (e *ExponentialBackoffStrategy) WithMaxDelay(maxDelay time.Duration) *ExponentialBackoffStrategy {
 	e.maxDelay = maxDelay
 	return e
 }
 
+// WithMinDelay sets the minimum delay between retries; it takes precedence over the maximum
+func (e *ExponentialBackoffStrategy) WithMinDelay(minDelay time.Duration) *ExponentialBackoffStrategy {
+	e.minDelay = minDelay
+	return e
+}
+
 // ShouldRetry determines if a task should be retried
 func (e *ExponentialBackoffStrategy) ShouldRetry(attempt int, err error) bool {
 	// Don't retry if we've reached max attempts
@@ -36,20 +95,25 @@ func (e *ExponentialBackoffStrategy) ShouldRetry(attempt int, err error) bool {
 		return false
 	}
 
-	// Retry on any error (this can be customized per use case)
-	return err != nil
+	// Retry on any error that doesn't rule it out (this can be customized per use case)
+	return IsRetryable(err)
 }
 
 // GetDelay calculates the delay before the next retry using exponential backoff
 func (e *ExponentialBackoffStrategy) GetDelay(attempt int) time.Duration {
-	delay := time.Duration(float64(e.baseDelay) * math.Pow(e.multiplier, float64(attempt-1)))
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	// Compare in floating point so huge exponents can't overflow into a negative duration
+	delay := float64(e.baseDelay) * math.Pow(e.multiplier, float64(attempt-1))
 
 	// Cap the delay at maxDelay
-	if delay > e.maxDelay {
-		delay = e.maxDelay
+	if math.IsNaN(delay) || delay > float64(e.maxDelay) {
+		return max(e.maxDelay, e.minDelay, 0)
 	}
 
-	return delay
+	return max(time.Duration(delay), e.minDelay)
 }
 
 // GetMaxAttempts returns the maximum number of attempts
@@ -62,6 +126,7 @@ type LinearBackoffStrategy struct {
 	maxAttempts int
 	baseDelay   time.Duration
 	increment   time.Duration
+	minDelay    time.Duration
 }
 
 // NewLinearBackoffStrategy creates a new linear backoff strategy
@@ -73,17 +138,23 @@ func NewLinearBackoffStrategy(maxAttempts int, baseDelay, increment time.Duratio
 	}
 }
 
+// WithMinDelay sets the minimum delay between retries
+func (l *LinearBackoffStrategy) WithMinDelay(minDelay time.Duration) *LinearBackoffStrategy {
+	l.minDelay = minDelay
+	return l
+}
+
 // ShouldRetry determines if a task should be retried
 func (l *LinearBackoffStrategy) ShouldRetry(attempt int, err error) bool {
 	if attempt >= l.maxAttempts {
 		return false
 	}
-	return err != nil
+	return IsRetryable(err)
 }
 
 // GetDelay calculates the delay before the next retry using linear backoff
 func (l *LinearBackoffStrategy) GetDelay(attempt int) time.Duration {
-	return l.baseDelay + time.Duration(attempt-1)*l.increment
+	return max(l.baseDelay+time.Duration(attempt-1)*l.increment, l.minDelay)
 }
 
 // GetMaxAttempts returns the maximum number of attempts
@@ -95,6 +166,7 @@ func (l *LinearBackoffStrategy) GetMaxAttempts() int {
 type FixedDelayStrategy struct {
 	maxAttempts int
 	delay       time.Duration
+	minDelay    time.Duration
 }
 
 // NewFixedDelayStrategy creates a new fixed delay strategy
@@ -105,17 +177,23 @@ func NewFixedDelayStrategy(maxAttempts int, delay time.Duration) *FixedDelayStra
 	}
 }
 
+// WithMinDelay sets the minimum delay between retries
+func (f *FixedDelayStrategy) WithMinDelay(minDelay time.Duration) *FixedDelayStrategy {
+	f.minDelay = minDelay
+	return f
+}
+
 // ShouldRetry determines if a task should be retried
 func (f *FixedDelayStrategy) ShouldRetry(attempt int, err error) bool {
 	if attempt >= f.maxAttempts {
 		return false
 	}
-	return err != nil
+	return IsRetryable(err)
 }
 
 // GetDelay returns the fixed delay
 func (f *FixedDelayStrategy) GetDelay(attempt int) time.Duration {
-	return f.delay
+	return max(f.delay, f.minDelay)
 }
 
 // GetMaxAttempts returns the maximum number of attempts
@@ -177,7 +255,7 @@ func (c *ConditionalRetryStrategy) ShouldRetry(attempt int, err error) bool {
 	if c.shouldRetryFn != nil {
 		return c.shouldRetryFn(attempt, err)
 	}
-	return err != nil
+	return IsRetryable(err)
 }
 
 // GetDelay uses custom delay logic or falls back to base delay
@@ -192,3 +270,134 @@ func (c *ConditionalRetryStrategy) GetDelay(attempt int) time.Duration {
 func (c *ConditionalRetryStrategy) GetMaxAttempts() int {
 	return c.maxAttempts
 }
+
+// RandomizedDelayStrategy waits a uniformly random delay between minDelay and maxDelay before every retry
+// Unlike jittered backoff there is no underlying schedule, so clients recovering together don't batch up
+type RandomizedDelayStrategy struct {
+	maxAttempts int
+	minDelay    time.Duration
+	maxDelay    time.Duration
+	int64N      func(n int64) int64 // Returns a random value in [0, n)
+}
+
+// NewRandomizedDelayStrategy creates a strategy with uniformly random delays in [minDelay, maxDelay]
+func NewRandomizedDelayStrategy(maxAttempts int, minDelay, maxDelay time.Duration) *RandomizedDelayStrategy {
+	if maxDelay < minDelay {
+		minDelay, maxDelay = maxDelay, minDelay
+	}
+
+	return &RandomizedDelayStrategy{
+		maxAttempts: maxAttempts,
+		minDelay:    minDelay,
+		maxDelay:    maxDelay,
+		int64N:      rand.Int64N,
+	}
+}
+
+// WithRand sets the random source, e.g. a seeded generator for deterministic tests
+// A *rand.Rand is not safe for concurrent use, so don't share the strategy across goroutines with one set
+func (r *RandomizedDelayStrategy) WithRand(source *rand.Rand) *RandomizedDelayStrategy {
+	r.int64N = source.Int64N
+	return r
+}
+
+// ShouldRetry determines if a task should be retried
+func (r *RandomizedDelayStrategy) ShouldRetry(attempt int, err error) bool {
+	if attempt >= r.maxAttempts {
+		return false
+	}
+	return IsRetryable(err)
+}
+
+// GetDelay returns a random delay between minDelay and maxDelay, inclusive
+func (r *RandomizedDelayStrategy) GetDelay(attempt int) time.Duration {
+	spread := int64(r.maxDelay - r.minDelay)
+	if spread <= 0 {
+		return r.minDelay
+	}
+	return r.minDelay + time.Duration(r.int64N(spread+1))
+}
+
+// GetMaxAttempts returns the maximum number of attempts
+func (r *RandomizedDelayStrategy) GetMaxAttempts() int {
+	return r.maxAttempts
+}
+
+// ChainRetryStrategy applies a sequence of retry strategies one after another
+type ChainRetryStrategy struct {
+	strategies []RetryStrategy
+}
+
+// NewChainRetryStrategy creates a strategy that exhausts each strategy's attempts before moving to the next
+func NewChainRetryStrategy(strategies ...RetryStrategy) *ChainRetryStrategy {
+	return &ChainRetryStrategy{
+		strategies: strategies,
+	}
+}
+
+// locate returns the index of the strategy active for the given attempt and the attempt number local to it
+func (c *ChainRetryStrategy) locate(attempt int) (int, int) {
+	local := attempt
+	for i, strategy := range c.strategies {
+		if local <= strategy.GetMaxAttempts() {
+			return i, local
+		}
+		local -= strategy.GetMaxAttempts()
+	}
+	return -1, local
+}
+
+// ShouldRetry delegates to the active strategy, handing over to the next one once it is exhausted
+func (c *ChainRetryStrategy) ShouldRetry(attempt int, err error) bool {
+	if attempt >= c.GetMaxAttempts() {
+		return false
+	}
+
+	index, local := c.locate(attempt)
+	if index < 0 {
+		return false
+	}
+
+	strategy := c.strategies[index]
+	if local < strategy.GetMaxAttempts() {
+		return strategy.ShouldRetry(local, err)
+	}
+
+	// The active strategy is exhausted, so the next one decides whether its first attempt happens
+	if index+1 < len(c.strategies) {
+		return c.strategies[index+1].ShouldRetry(0, err)
+	}
+	return false
+}
+
+// GetDelay delegates to the strategy that owns the failed attempt
+func (c *ChainRetryStrategy) GetDelay(attempt int) time.Duration {
+	index, local := c.locate(attempt)
+	if index < 0 {
+		return 0
+	}
+	return c.strategies[index].GetDelay(local)
+}
+
+// GetMaxAttempts returns the sum of the maximum attempts of all chained strategies
+func (c *ChainRetryStrategy) GetMaxAttempts() int {
+	total := 0
+	for _, strategy := range c.strategies {
+		total += strategy.GetMaxAttempts()
+	}
+	return total
+}
+
+// Reset resets every chained strategy that keeps per-call state
+func (c *ChainRetryStrategy) Reset() {
+	for _, strategy := range c.strategies {
+		resetStrategy(strategy)
+	}
+}
+
+// resetStrategy clears the per-call state of strategy if it keeps any
+func resetStrategy(strategy RetryStrategy) {
+	if resettable, ok := strategy.(ResettableStrategy); ok {
+		resettable.Reset()
+	}
+}
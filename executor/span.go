@@ -0,0 +1,20 @@
+package executor
+
+import "context"
+
+// WithSpanIDs sets a function returning the trace and span IDs of the span in a context, used to fill
+// in Result.TraceID and Result.SpanID. The executor doesn't depend on OpenTelemetry itself; with it,
+// pass a function reading trace.SpanFromContext(ctx).SpanContext()
+func WithSpanIDs(spanIDs func(ctx context.Context) (traceID, spanID string)) ExecutorOption {
+	return func(e *Executor) {
+		e.spanIDs = spanIDs
+	}
+}
+
+// extractSpanIDs returns the trace and span IDs of the span in ctx, or empty strings without WithSpanIDs
+func (e *Executor) extractSpanIDs(ctx context.Context) (traceID, spanID string) {
+	if e.spanIDs == nil {
+		return "", ""
+	}
+	return e.spanIDs(ctx)
+}
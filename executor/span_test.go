@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// spanKey carries a fake span in test contexts
+type spanKey struct{}
+
+func TestResultSpanIDsEmptyWithoutExtractor(t *testing.T) {
+	executor := NewExecutor(WithRetryStrategy(NewFixedDelayStrategy(2, 0)))
+
+	result, _ := Execute(executor, context.Background(), func(ctx context.Context) (int, error) {
+		return 0, errors.New("failed")
+	})
+	if result.TraceID != "" || result.SpanID != "" {
+		t.Errorf("Expected empty span IDs, got %q and %q", result.TraceID, result.SpanID)
+	}
+}
+
+func TestResultSpanIDsFromExtractor(t *testing.T) {
+	executor := NewExecutor(WithSpanIDs(func(ctx context.Context) (string, string) {
+		if span, ok := ctx.Value(spanKey{}).(string); ok {
+			return "trace-" + span, span
+		}
+		return "", ""
+	}))
+
+	ctx := context.WithValue(context.Background(), spanKey{}, "span-1")
+	result, err := Execute(executor, ctx, func(ctx context.Context) (int, error) {
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.TraceID != "trace-span-1" || result.SpanID != "span-1" {
+		t.Errorf("Expected the span IDs of the context, got %q and %q", result.TraceID, result.SpanID)
+	}
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"math"
+	"sync"
 	"time"
 )
 
@@ -92,3 +93,67 @@ func NewConditionalTimeoutStrategy(getTimeoutFn func(attempt int) time.Duration)
 func (c *ConditionalTimeoutStrategy) GetTimeout(attempt int) time.Duration {
 	return c.getTimeoutFn(attempt)
 }
+
+// AdaptiveTimeoutStrategy sets timeouts from an exponential moving average (EMA) of observed attempt durations
+// It is safe to share across concurrent executions
+type AdaptiveTimeoutStrategy struct {
+	initialTimeout time.Duration
+	minTimeout     time.Duration
+	maxTimeout     time.Duration
+	multiplier     float64
+	alpha          float64 // EMA smoothing factor derived from the window size
+
+	mu       sync.Mutex
+	ema      float64 // In nanoseconds
+	observed bool
+}
+
+// NewAdaptiveTimeoutStrategy creates a timeout strategy that uses initialTimeout until an attempt has been observed,
+// then the EMA over roughly window attempts times multiplier, bounded by [minTimeout, maxTimeout]
+func NewAdaptiveTimeoutStrategy(initialTimeout, minTimeout, maxTimeout time.Duration, window int, multiplier float64) *AdaptiveTimeoutStrategy {
+	if window < 1 {
+		window = 1
+	}
+
+	return &AdaptiveTimeoutStrategy{
+		initialTimeout: initialTimeout,
+		minTimeout:     minTimeout,
+		maxTimeout:     maxTimeout,
+		multiplier:     multiplier,
+		alpha:          2 / float64(window+1),
+	}
+}
+
+// GetTimeout returns the EMA-based timeout, or the initial timeout before any attempt was observed
+func (a *AdaptiveTimeoutStrategy) GetTimeout(attempt int) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.observed {
+		return a.initialTimeout
+	}
+
+	timeout := time.Duration(a.ema * a.multiplier)
+	if timeout < a.minTimeout {
+		timeout = a.minTimeout
+	}
+	if timeout > a.maxTimeout {
+		timeout = a.maxTimeout
+	}
+
+	return timeout
+}
+
+// ObserveAttempt folds the attempt's duration into the moving average
+func (a *AdaptiveTimeoutStrategy) ObserveAttempt(record AttemptRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.observed {
+		a.ema = float64(record.Duration)
+		a.observed = true
+		return
+	}
+
+	a.ema = a.alpha*float64(record.Duration) + (1-a.alpha)*a.ema
+}
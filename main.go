@@ -2,25 +2,142 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/jq1836/DDNS/config"
 	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
 	"github.com/jq1836/DDNS/providers"
+	"github.com/jq1836/DDNS/systemd"
+	"github.com/jq1836/DDNS/version"
+	"io"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 )
 
 func main() {
+	// Parse command line flags
+	recordValue := flag.String("record-value", "", "Publish this value instead of the detected IP (e.g. for TXT records)")
+	listProviders := flag.Bool("list-providers", false, "List supported providers and their configuration fields, then exit")
+	output := flag.String("output", "text", "Output format for --list-providers, --check and --check-credentials: text or json")
+	check := flag.Bool("check", false, "Validate the configuration without contacting any provider, then exit")
+	checkCredentials := flag.Bool("check-credentials", false, "Validate the configuration and the provider credentials, which needs network access, then exit")
+	showVersion := flag.Bool("version", false, "Print version information and exit")
+	force := flag.Bool("force", false, "Push the record once even if it already matches, then exit")
+	flag.Parse()
+
+	if *showVersion {
+		if err := printVersion(os.Stdout); err != nil {
+			log.Fatalf("Failed to print version: %v", err)
+		}
+		os.Exit(0)
+	}
+
+	if *check || *checkCredentials {
+		if err := checkConfig(os.Stdout, *output, *checkCredentials); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if *listProviders {
+		if err := printProviders(os.Stdout, providers.NewFactory(), *output); err != nil {
+			log.Fatalf("Failed to list providers: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	// Load and validate configuration
 	cfg := loadAndValidateConfig()
 
+	if *force {
+		cfg.DDNS.ForceUpdate = true
+		if err := ddns.RunOnce(context.Background(), setupDDNSService(cfg), *recordValue, slog.Default()); err != nil {
+			log.Fatalf("Forced update failed: %v", err)
+		}
+		os.Exit(0)
+	}
+
 	// Setup DDNS service
 	service := setupDDNSService(cfg)
 
+	removePIDFile, err := writePIDFile(cfg.Server.PIDFile)
+	if err != nil {
+		log.Fatalf("Failed to write PID file: %v", err)
+	}
+
 	// Run the DDNS client
-	runDDNSClient(service, cfg.DDNS.UpdateInterval.Duration)
+	runConfig := ddns.RunConfig{
+		UpdateInterval:         cfg.DDNS.UpdateInterval.Duration,
+		JitterPercent:          cfg.DDNS.UpdateJitter,
+		RecordValue:            *recordValue,
+		ShutdownTimeout:        cfg.Server.ShutdownTimeout.Duration,
+		MaxConsecutiveFailures: cfg.DDNS.MaxConsecutiveFailures,
+		Logger:                 slog.Default(),
+	}
+	if cfg.DDNS.ClearOnShutdown {
+		// Clearing gets what is left of the shutdown timeout after in-flight updates finish
+		runConfig.OnShutdown = func(ctx context.Context) {
+			clearRecordsOnShutdown(ctx, service)
+		}
+	}
+	err = runDDNSClient(service, runConfig)
+	removePIDFile()
+	if err != nil {
+		log.Fatalf("DDNS client stopped: %v", err)
+	}
+}
+
+// printVersion writes the build information as JSON
+func printVersion(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(version.Get())
+}
+
+// printProviders writes every supported provider with its configuration fields in the given format
+func printProviders(w io.Writer, factory *providers.Factory, format string) error {
+	var descriptors []providers.ProviderDescriptor
+	for _, name := range factory.GetSupportedProviders() {
+		descriptors = append(descriptors, factory.DescribeProvider(name))
+	}
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(descriptors)
+
+	case "text":
+		// One provider per line, as space-separated key=value pairs, so the output is easy to parse
+		for _, descriptor := range descriptors {
+			fmt.Fprintf(w, "%s required=%s optional=%s %s description=%q\n",
+				descriptor.Name, fieldNames(descriptor.RequiredFields), fieldNames(descriptor.OptionalFields),
+				descriptor.Capabilities, descriptor.Description)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported output format %q (use text or json)", format)
+	}
+}
+
+// fieldNames joins the names of the fields with commas
+func fieldNames(fields []providers.FieldDescriptor) string {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+	}
+	return strings.Join(names, ",")
 }
 
 func loadAndValidateConfig() *config.Config {
@@ -29,48 +146,327 @@ func loadAndValidateConfig() *config.Config {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	if err := cfg.Validate(); err != nil {
+	if err := validateConfig(cfg); err != nil {
 		log.Fatalf("Configuration validation failed: %v", err)
 	}
 
-	log.Printf("Starting DDNS client for domain: %s", cfg.DDNS.Domain)
-	log.Printf("Using provider: %s", cfg.DDNS.Provider)
+	var providerNames []string
+	for _, providerConfig := range buildProviderConfigs(cfg) {
+		providerNames = append(providerNames, providerConfig.Provider)
+	}
+
+	log.Printf("Starting %s for domain: %s", version.BuildInfo(), strings.Join(cfg.DDNS.Domains(), ", "))
+	log.Printf("Using provider: %s", strings.Join(providerNames, ", "))
 	log.Printf("Update interval: %s", cfg.DDNS.UpdateInterval.Duration)
 
 	return cfg
 }
 
-func setupDDNSService(cfg *config.Config) *ddns.Service {
-	// Create provider factory
+// validateConfig checks the configuration without touching the network
+func validateConfig(cfg *config.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	// Check the provider, domain, record type and interval fit together
 	factory := providers.NewFactory()
+	var errs []error
+	if cfg.HTTP.Proxy != "" {
+		if _, err := providers.ParseProxyURL(cfg.HTTP.Proxy); err != nil {
+			errs = append(errs, fmt.Errorf("http.proxy: %w", err))
+		}
+	}
+	for _, providerConfig := range buildProviderConfigs(cfg) {
+		if err := factory.ValidateConfig(providerConfig); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if cfg.DDNS.FailoverProvider != "" {
+		failoverConfig := buildDDNSConfig(cfg)
+		failoverConfig.Provider = cfg.DDNS.FailoverProvider
+		failoverConfig.APIKey = cfg.DDNS.FailoverAPIKey
+		if err := factory.ValidateConfig(failoverConfig); err != nil {
+			errs = append(errs, fmt.Errorf("failover: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// credentialCheckTimeout bounds validating every provider's credentials for --check-credentials
+const credentialCheckTimeout = time.Minute
+
+// checkReport is the outcome of --check or --check-credentials, as written with --output json
+type checkReport struct {
+	Valid              bool     `json:"valid"`
+	Errors             []string `json:"errors,omitempty"`
+	Domains            []string `json:"domains,omitempty"`
+	RecordType         string   `json:"record_type,omitempty"`
+	Providers          []string `json:"providers,omitempty"`
+	Interval           string   `json:"interval,omitempty"`
+	CredentialsChecked bool     `json:"credentials_checked"`
+}
+
+// checkConfig loads and validates the configuration, writing a report to w in the given format
+// Provider credentials are only checked, which needs network access, when credentials is set
+func checkConfig(w io.Writer, format string, credentials bool) error {
+	if format != "text" && format != "json" {
+		err := fmt.Errorf("unsupported output format %q (use text or json)", format)
+		fmt.Fprintln(w, err)
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err == nil {
+		err = validateConfig(cfg)
+	}
+	if err == nil && credentials {
+		ctx, cancel := context.WithTimeout(context.Background(), credentialCheckTimeout)
+		defer cancel()
+		err = checkProviderCredentials(ctx, cfg)
+	}
+
+	report := checkReport{Valid: err == nil, CredentialsChecked: credentials && err == nil}
+	if err != nil {
+		report.Errors = strings.Split(err.Error(), "\n")
+	} else {
+		report.Domains = cfg.DDNS.Domains()
+		report.RecordType = buildDDNSConfig(cfg).RecordType
+		for _, providerConfig := range buildProviderConfigs(cfg) {
+			report.Providers = append(report.Providers, providerConfig.Provider)
+		}
+		report.Interval = cfg.DDNS.UpdateInterval.Duration.String()
+	}
+
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(report); encodeErr != nil {
+			return encodeErr
+		}
+		return err
+	}
+
+	if err != nil {
+		fmt.Fprintf(w, "Configuration invalid:\n%v\n", err)
+		return err
+	}
+
+	fmt.Fprintf(w, "Configuration OK\n")
+	fmt.Fprintf(w, "  domain:   %s (%s)\n", strings.Join(report.Domains, ", "), report.RecordType)
+	for _, provider := range report.Providers {
+		fmt.Fprintf(w, "  provider: %s\n", provider)
+	}
+	fmt.Fprintf(w, "  interval: %s\n", report.Interval)
+	if credentials {
+		fmt.Fprintf(w, "  credentials: valid\n")
+	}
+	return nil
+}
+
+// checkProviderCredentials validates the credentials of every configured provider, including the failover one
+func checkProviderCredentials(ctx context.Context, cfg *config.Config) error {
+	built, err := buildProviders(cfg)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, provider := range built.all() {
+		if err := provider.ValidateCredentials(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s credentials: %w", provider.GetProviderName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// configuredProviders holds the providers built from the configuration, with the HTTP client and executor they share
+type configuredProviders struct {
+	httpClient *http.Client
+	executor   *executor.Executor
+	ddnsConfig ddns.Config
+	providers  []ddns.Provider // One per provider block, or the single configured provider
 
-	// Create DDNS config
-	ddnsConfig := ddns.Config{
-		Provider:   cfg.DDNS.Provider,
-		APIKey:     cfg.DDNS.APIKey,
-		Domain:     cfg.DDNS.Domain,
-		TTL:        300, // Default TTL
-		RecordType: "A", // Default to A record
+	failover       ddns.Provider // nil without a failover provider
+	failoverConfig ddns.Config
+}
+
+// buildProviders creates the shared HTTP client and executor and every configured provider, including the failover one
+// options configure the executor, e.g. to log its events
+func buildProviders(cfg *config.Config, options ...executor.ExecutorOption) (*configuredProviders, error) {
+	// Create a single HTTP client shared by the providers and IP detector
+	httpClient, err := providers.ClientWithProxy(providers.NewHTTPClient(cfg.HTTP.Timeout.Duration, cfg.HTTP.UserAgent, cfg.HTTP.Headers), cfg.HTTP.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure HTTP proxy: %w", err)
 	}
 
-	// Create provider
-	provider, err := factory.CreateProvider(ddnsConfig)
+	// Build the retry policy from the HTTP configuration
+	exec := cfg.HTTP.NewExecutor(options...)
+	factory := providers.NewFactory(
+		providers.WithHTTPClient(httpClient),
+		providers.WithExecutor(exec),
+	)
+
+	built := &configuredProviders{httpClient: httpClient, executor: exec, ddnsConfig: buildDDNSConfig(cfg)}
+
+	var errs []error
+	built.providers, err = factory.CreateProviders(buildProviderConfigs(cfg))
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// A failover provider is only used when an update on the primary fails
+	if cfg.DDNS.FailoverProvider != "" {
+		built.failoverConfig = built.ddnsConfig
+		built.failoverConfig.Provider = cfg.DDNS.FailoverProvider
+		built.failoverConfig.APIKey = cfg.DDNS.FailoverAPIKey
+
+		built.failover, err = factory.CreateProvider(built.failoverConfig)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failover %s: %w", cfg.DDNS.FailoverProvider, err))
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return built, nil
+}
+
+// all returns every configured provider, the failover one last
+func (p *configuredProviders) all() []ddns.Provider {
+	if p.failover == nil {
+		return p.providers
+	}
+	return append(slices.Clip(p.providers), p.failover)
+}
+
+func buildDDNSConfig(cfg *config.Config) ddns.Config {
+	// Default to an A record when the config file doesn't specify one
+	recordType := cfg.DDNS.RecordType
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	domains := cfg.DDNS.Domains()
+
+	var records []ddns.RecordSpec
+	for _, record := range cfg.DDNS.Records {
+		records = append(records, ddns.RecordSpec{
+			Domain:     strings.TrimSpace(record.Domain),
+			RecordType: record.RecordType,
+			TTL:        record.TTL,
+		})
+	}
+
+	return ddns.Config{
+		Provider:       cfg.DDNS.Provider,
+		Username:       cfg.DDNS.Username,
+		APIKey:         cfg.DDNS.APIKey,
+		Domain:         domains[0],
+		Domains:        domains,
+		Records:        records,
+		ZoneID:         cfg.DDNS.ZoneID,
+		FilePath:       cfg.DDNS.FilePath,
+		Target:         cfg.DDNS.Target,
+		TTL:            cfg.DDNS.TTL,
+		RecordType:     recordType,
+		UpdateInterval: cfg.DDNS.UpdateInterval.Duration,
+
+		IPDetectionMethod: cfg.HTTP.IPDetectionMethod,
+		IPv6Interface:     cfg.HTTP.IPv6Interface,
+		SkipTemporaryIPv6: cfg.HTTP.SkipTemporaryIPv6,
+		AllowPrivateIP:    cfg.DDNS.AllowPrivateIP,
+		ForceUpdate:       cfg.DDNS.ForceUpdate,
+		ConfirmIPChange:   cfg.DDNS.ConfirmIPChange,
+		RecordCacheTTL:    cfg.DDNS.RecordCacheTTL.Duration,
+
+		VerifyPropagation:  cfg.DDNS.VerifyPropagation,
+		PropagationTimeout: cfg.DDNS.PropagationTimeout.Duration,
+		Nameserver:         cfg.DDNS.Nameserver,
+	}
+}
+
+// buildProviderConfigs returns one DDNS config per provider the record is mirrored to
+func buildProviderConfigs(cfg *config.Config) []ddns.Config {
+	base := buildDDNSConfig(cfg)
+	if len(cfg.DDNS.Providers) == 0 {
+		return []ddns.Config{base}
+	}
+
+	configs := make([]ddns.Config, 0, len(cfg.DDNS.Providers))
+	for _, block := range cfg.DDNS.Providers {
+		providerConfig := base
+		providerConfig.Provider = block.Provider
+		providerConfig.Username = block.Username
+		providerConfig.APIKey = block.APIKey
+		providerConfig.ZoneID = block.ZoneID
+		providerConfig.FilePath = block.FilePath
+		providerConfig.Headers = block.Headers
+		providerConfig.Proxy = block.Proxy
+		configs = append(configs, providerConfig)
+	}
+
+	return configs
+}
+
+// confirmationDetector returns a detector independent of the configured detection method, for confirming IP changes
+func confirmationDetector(ddnsConfig ddns.Config, httpDetector ddns.IPDetector) ddns.IPDetector {
+	if ddnsConfig.IPDetectionMethod == "" || ddnsConfig.IPDetectionMethod == ddns.IPDetectionHTTP {
+		return ddns.NewIPDetector(ddns.IPDetectionSTUN, httpDetector, ddnsConfig.AllowPrivateIP)
+	}
+	return httpDetector
+}
+
+func setupDDNSService(cfg *config.Config) ddns.Updater {
+	built, err := buildProviders(cfg, executor.WithEventCallback(logExecutorEvent))
 	if err != nil {
 		log.Fatalf("Failed to create provider: %v", err)
 	}
+	for _, provider := range built.all() {
+		logCapabilities(provider)
+	}
+	ddnsConfig := built.ddnsConfig
+
+	// Create DDNS service
+	httpDetector := ddns.NewHTTPIPDetector(built.httpClient, built.executor, ddns.IPFamilyForRecordType(ddnsConfig.RecordType)).WithAllowPrivateIP(ddnsConfig.AllowPrivateIP)
+	ipDetector := ddns.NewConfiguredIPDetector(ddnsConfig, httpDetector)
+	if ddnsConfig.UpdateInterval > 0 {
+		// Detections within the same cycle, e.g. per record family or for verification, share one lookup
+		ipDetector = ddns.NewCachedIPDetector(ipDetector, ddnsConfig.UpdateInterval/2)
+	}
+	var service ddns.Updater = ddns.NewMultiProviderService(built.providers, ddnsConfig, ipDetector).
+		WithConfirmationDetector(confirmationDetector(ddnsConfig, httpDetector))
+
+	if built.failover != nil {
+		service = ddns.NewFailoverService([]ddns.FailoverTarget{
+			{Provider: built.providers[0], Config: ddnsConfig},
+			{Provider: built.failover, Config: built.failoverConfig},
+		}, ipDetector)
+	}
 
 	// Validate provider credentials
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := provider.ValidateCredentials(ctx); err != nil {
+	if err := service.Validate(ctx); err != nil {
 		log.Fatalf("Provider credential validation failed: %v", err)
 	}
 
 	log.Printf("Provider credentials validated successfully")
 
-	// Create and return DDNS service
-	return ddns.NewService(provider, ddnsConfig)
+	return service
+}
+
+// logCapabilities logs what the provider supports, noting when records are read through DNS
+func logCapabilities(provider ddns.Provider) {
+	capabilities := provider.Capabilities()
+	log.Printf("Provider %s capabilities: %s", provider.GetProviderName(), capabilities)
+	if !capabilities.SupportsRead {
+		log.Printf("Provider %s can't read records, current values are resolved through DNS", provider.GetProviderName())
+	}
 }
 
 func setupGracefulShutdown() (context.Context, context.CancelFunc) {
@@ -82,55 +478,128 @@ func setupGracefulShutdown() (context.Context, context.CancelFunc) {
 	go func() {
 		<-sigChan
 		log.Println("Received shutdown signal, stopping...")
+		notifySystemd("STOPPING=1")
 		mainCancel()
 	}()
 
 	return mainCtx, mainCancel
 }
 
-func performDDNSUpdate(ctx context.Context, service *ddns.Service) {
-	updateCtx, updateCancel := context.WithTimeout(ctx, 2*time.Minute)
-	defer updateCancel()
+// logExecutorEvent logs retries and clipped timeouts so they can be traced back to their update cycle
+func logExecutorEvent(ctx context.Context, event executor.Event) {
+	switch event.Type {
+	case executor.EventRetry:
+		slog.Warn("Attempt failed, retrying", "request_id", executor.RequestIDFromContext(ctx), "attempt", event.Attempt, "delay", event.Delay, "error", event.Err)
+	case executor.EventTimeoutClipped:
+		slog.Info("Attempt timeout clipped by the update deadline", "request_id", executor.RequestIDFromContext(ctx), "attempt", event.Attempt, "timeout", event.StrategyTimeout, "clipped_to", event.Timeout.Round(time.Millisecond))
+	}
+}
 
-	log.Println("Checking for IP changes...")
-	response, err := service.UpdateIP(updateCtx)
-	if err != nil {
-		log.Printf("Failed to update IP: %v", err)
+// runDDNSClient runs the update loop until a shutdown signal arrives (see ddns.Run)
+func runDDNSClient(service ddns.Updater, runConfig ddns.RunConfig) error {
+	// Setup graceful shutdown
+	mainCtx, mainCancel := setupGracefulShutdown()
+	defer mainCancel()
+
+	startWatchdog(mainCtx)
+	if s, ok := service.(*ddns.Service); ok {
+		go reportUpdateStatus(mainCtx, s.Subscribe())
+	}
+	notifySystemd("READY=1")
+
+	runConfig.Trigger = updateOnSignal(mainCtx)
+	return ddns.Run(mainCtx, service, runConfig)
+}
+
+// updateOnSignal returns a channel that receives whenever SIGUSR1 asks for an immediate update, until ctx is done
+// Signals arriving while an update is already pending are coalesced into it
+// Without an update signal on this platform the channel never receives
+func updateOnSignal(ctx context.Context) <-chan struct{} {
+	if updateSignal == nil {
+		return nil
+	}
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, updateSignal)
+
+	trigger := make(chan struct{}, 1)
+	go func() {
+		defer signal.Stop(sigChan)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigChan:
+				log.Println("Received SIGUSR1, updating now...")
+				select {
+				case trigger <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return trigger
+}
+
+// clearRecordsOnShutdown removes the records the service publishes, giving up once ctx is done
+func clearRecordsOnShutdown(ctx context.Context, service ddns.Updater) {
+	s, ok := service.(*ddns.Service)
+	if !ok {
+		log.Printf("Clearing records on shutdown is not supported with a failover provider")
 		return
 	}
 
-	if response.Success {
-		log.Printf("DNS update successful: %s", response.Message)
-	} else {
-		log.Printf("DNS update failed: %s", response.Message)
+	log.Println("Clearing DNS records...")
+	if err := s.ClearRecords(ctx); err != nil {
+		log.Printf("Failed to clear DNS records: %v", err)
+		return
 	}
+	log.Println("DNS records cleared")
+}
 
-	if response.RecordID != "" {
-		log.Printf("Record ID: %s", response.RecordID)
+// notifySystemd sends state to systemd, logging rather than failing when it can't
+func notifySystemd(state string) {
+	if err := systemd.Notify(state); err != nil {
+		log.Printf("Failed to notify systemd: %v", err)
 	}
 }
 
-func runDDNSClient(service *ddns.Service, updateInterval time.Duration) {
-	// Setup graceful shutdown
-	mainCtx, mainCancel := setupGracefulShutdown()
-	defer mainCancel()
+// startWatchdog pets the systemd watchdog at half its interval until ctx is done, if WatchdogSec= enabled it
+func startWatchdog(ctx context.Context) {
+	interval, err := systemd.WatchdogInterval()
+	if err != nil {
+		log.Printf("Systemd watchdog disabled: %v", err)
+		return
+	}
+	if interval == 0 {
+		return
+	}
 
-	// Create ticker for periodic updates
-	ticker := time.NewTicker(updateInterval)
-	defer ticker.Stop()
+	go func() {
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
 
-	// Perform initial update
-	log.Println("Performing initial IP update...")
-	performDDNSUpdate(mainCtx, service)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				notifySystemd("WATCHDOG=1")
+			}
+		}
+	}()
+}
 
-	// Start the update loop
+// reportUpdateStatus publishes every record update as the systemd status line until ctx is done
+func reportUpdateStatus(ctx context.Context, events <-chan ddns.UpdateEvent) {
 	for {
 		select {
-		case <-mainCtx.Done():
-			log.Println("DDNS client stopped")
+		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			performDDNSUpdate(mainCtx, service)
+		case event := <-events:
+			notifySystemd(fmt.Sprintf("STATUS=Updated %s %s record to %s at %s", event.Domain, event.RecordType, event.IP, event.OccurredAt.Format(time.RFC3339)))
 		}
 	}
 }
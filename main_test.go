@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/config"
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/providers"
+)
+
+func TestRunDDNSClientStopsAfterConsecutiveFailures(t *testing.T) {
+	provider := providers.NewMockProvider("test").WithFailure(true)
+	service := ddns.NewServiceWithIPDetector(provider, ddns.Config{Domain: "example.com", RecordType: "A"}, providers.NewMockIPDetector("203.0.113.1"))
+
+	result := make(chan error, 1)
+	go func() {
+		result <- runDDNSClient(service, ddns.RunConfig{UpdateInterval: 10 * time.Millisecond, ShutdownTimeout: time.Second, MaxConsecutiveFailures: 3})
+	}()
+
+	select {
+	case err := <-result:
+		if err == nil || !strings.Contains(err.Error(), "3 consecutive") {
+			t.Errorf("Expected error after 3 consecutive failures, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Client did not stop after repeated failures")
+	}
+
+	if updates := provider.CallsForMethod("UpdateRecord"); len(updates) != 3 {
+		t.Errorf("Expected 3 update cycles, got %d UpdateRecord calls", len(updates))
+	}
+}
+
+func TestPrintProvidersJSON(t *testing.T) {
+	factory := providers.NewFactory()
+
+	var out bytes.Buffer
+	if err := printProviders(&out, factory, "json"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var descriptors []providers.ProviderDescriptor
+	if err := json.Unmarshal(out.Bytes(), &descriptors); err != nil {
+		t.Fatalf("Expected valid JSON, got %v", err)
+	}
+
+	if len(descriptors) != len(factory.GetSupportedProviders()) {
+		t.Errorf("Expected %d providers, got %d", len(factory.GetSupportedProviders()), len(descriptors))
+	}
+
+	if err := printProviders(&out, factory, "yaml"); err == nil {
+		t.Error("Expected error for unsupported output format")
+	}
+}
+
+func TestPrintProvidersText(t *testing.T) {
+	factory := providers.NewFactory()
+
+	var out bytes.Buffer
+	if err := printProviders(&out, factory, "text"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	supported := factory.GetSupportedProviders()
+	if len(lines) != len(supported) {
+		t.Fatalf("Expected one line per provider, got %d lines for %d providers", len(lines), len(supported))
+	}
+	for i, name := range supported {
+		if !strings.HasPrefix(lines[i], name+" required=") {
+			t.Errorf("Expected line %d to describe %s, got %q", i, name, lines[i])
+		}
+	}
+	if want := "duckdns required=domain,api_key optional=record_type read=true aaaa=true txt=false cname=false srv=false"; !strings.HasPrefix(lines[0], want) {
+		t.Errorf("Expected %q, got %q", want, lines[0])
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "non-existent-config.json")
+	t.Setenv("DDNS_PROVIDER", "duckdns")
+	t.Setenv("DDNS_DOMAIN", "home.duckdns.org")
+	t.Setenv("DDNS_API_KEY", "c0ffee00-1234-4abc-9def-0123456789ab")
+
+	var out bytes.Buffer
+	if err := checkConfig(&out, "text", false); err != nil {
+		t.Fatalf("Expected valid configuration, got %v", err)
+	}
+	if !strings.Contains(out.String(), "Configuration OK") {
+		t.Errorf("Expected OK summary, got %q", out.String())
+	}
+
+	// DuckDNS can't hold TXT records, which only the provider checks catch
+	t.Setenv("DDNS_RECORD_TYPE", "TXT")
+	out.Reset()
+	if err := checkConfig(&out, "text", false); err == nil {
+		t.Fatal("Expected invalid configuration")
+	}
+	if !strings.Contains(out.String(), "Configuration invalid") {
+		t.Errorf("Expected error summary, got %q", out.String())
+	}
+}
+
+func TestCheckConfigProviderAPIKey(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "non-existent-config.json")
+	t.Setenv("DDNS_PROVIDER", "file")
+	t.Setenv("DDNS_DOMAIN", "home.example.com")
+	t.Setenv("DDNS_FILE_PATH", filepath.Join(t.TempDir(), "hosts"))
+
+	var out bytes.Buffer
+	if err := checkConfig(&out, "text", false); err != nil {
+		t.Fatalf("Expected the file provider to need no API key, got %v", err)
+	}
+
+	t.Setenv("DDNS_PROVIDER", "duckdns")
+	out.Reset()
+	if err := checkConfig(&out, "text", false); err == nil || !strings.Contains(err.Error(), "requires API key") {
+		t.Errorf("Expected the provider to require an API key, got %v", err)
+	}
+}
+
+func TestBuildProvidersIncludesFailover(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "non-existent-config.json")
+	t.Setenv("DDNS_PROVIDER", "mock")
+	t.Setenv("DDNS_DOMAIN", "home.example.com")
+	t.Setenv("DDNS_FAILOVER_PROVIDER", "mock")
+	t.Setenv("DDNS_FAILOVER_API_KEY", "unused")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected configuration, got %v", err)
+	}
+	built, err := buildProviders(cfg)
+	if err != nil {
+		t.Fatalf("Expected providers, got %v", err)
+	}
+	if len(built.providers) != 1 || built.failover == nil || len(built.all()) != 2 {
+		t.Errorf("Expected the primary and failover providers, got %d and %v", len(built.providers), built.failover)
+	}
+	if built.failoverConfig.Provider != "mock" || built.failoverConfig.APIKey != "unused" || built.failoverConfig.Domain != "home.example.com" {
+		t.Errorf("Unexpected failover config %+v", built.failoverConfig)
+	}
+
+	cfg.DDNS.FailoverProvider = "unknown"
+	if _, err := buildProviders(cfg); err == nil || !strings.Contains(err.Error(), "failover unknown") {
+		t.Errorf("Expected a failover provider error, got %v", err)
+	}
+}
+
+func TestCheckConfigJSONWithCredentials(t *testing.T) {
+	t.Setenv("CONFIG_PATH", "non-existent-config.json")
+	t.Setenv("DDNS_PROVIDER", "mock")
+	t.Setenv("DDNS_DOMAIN", "home.example.com")
+	t.Setenv("DDNS_API_KEY", "unused")
+
+	var out bytes.Buffer
+	if err := checkConfig(&out, "json", true); err != nil {
+		t.Fatalf("Expected valid configuration and credentials, got %v", err)
+	}
+	var report checkReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+	if !report.Valid || !report.CredentialsChecked || report.RecordType != "A" || !slices.Equal(report.Providers, []string{"mock"}) {
+		t.Errorf("Unexpected report %+v", report)
+	}
+
+	t.Setenv("DDNS_DOMAIN", "not a domain")
+	out.Reset()
+	if err := checkConfig(&out, "json", true); err == nil {
+		t.Fatal("Expected invalid configuration")
+	}
+	report = checkReport{}
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", out.String(), err)
+	}
+	if report.Valid || report.CredentialsChecked || len(report.Errors) == 0 {
+		t.Errorf("Expected an invalid report with errors, got %+v", report)
+	}
+
+	if err := checkConfig(&out, "yaml", false); err == nil {
+		t.Error("Expected an unsupported output format error")
+	}
+}
+
+// redirectTransport sends every request to target, whatever host it was made for
+type redirectTransport struct {
+	target *url.URL
+}
+
+// RoundTrip implements http.RoundTripper
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.target.Scheme, t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestConfiguredTTLReachesProvider(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v2/dns":
+			w.Write([]byte(`{"domains":[{"id":12,"name":"home.example.com"}]}`))
+		case r.Method == "GET" && r.URL.Path == "/v2/dns/12":
+			w.Write([]byte(`{"id":12,"name":"home.example.com","ipv4Address":"198.51.100.1"}`))
+		case r.Method == "POST" && r.URL.Path == "/v2/dns/12":
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	t.Setenv("CONFIG_PATH", "non-existent-config.json")
+	t.Setenv("DDNS_PROVIDER", "dynu")
+	t.Setenv("DDNS_DOMAIN", "home.example.com")
+	t.Setenv("DDNS_API_KEY", "secret")
+	t.Setenv("DDNS_TTL", "600")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Expected valid configuration, got %v", err)
+	}
+	ddnsConfig := buildDDNSConfig(cfg)
+
+	factory := providers.NewFactory(providers.WithHTTPClient(&http.Client{Transport: redirectTransport{target: target}}))
+	provider, err := factory.CreateProvider(ddnsConfig)
+	if err != nil {
+		t.Fatalf("Expected provider, got %v", err)
+	}
+
+	service := ddns.NewServiceWithIPDetector(provider, ddnsConfig, providers.NewMockIPDetector("203.0.113.1"))
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+	if !strings.Contains(body, `"ttl":600`) {
+		t.Errorf("Expected TTL 600 in the update request, got %q", body)
+	}
+}
+
+func TestPrintVersion(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printVersion(&buf); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var info map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &info); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", buf.String(), err)
+	}
+	for _, key := range []string{"version", "git_commit", "build_date", "go_version"} {
+		if info[key] == "" {
+			t.Errorf("Expected %s in version output, got %v", key, info)
+		}
+	}
+}
+
+func TestReportUpdateStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+	t.Setenv("NOTIFY_SOCKET", path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := make(chan ddns.UpdateEvent, 1)
+	go reportUpdateStatus(ctx, events)
+
+	events <- ddns.UpdateEvent{Domain: "home.example.com", RecordType: "A", IP: "203.0.113.1", OccurredAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	buf := make([]byte, 256)
+	listener.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatalf("Expected a status notification, got %v", err)
+	}
+	if want := "STATUS=Updated home.example.com A record to 203.0.113.1 at 2024-05-01T12:00:00Z"; string(buf[:n]) != want {
+		t.Errorf("Expected %q, got %q", want, buf[:n])
+	}
+}
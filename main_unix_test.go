@@ -0,0 +1,75 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/providers"
+)
+
+// runUntilSignal starts the client, sends SIGTERM once the first update is underway and waits for it to stop
+func runUntilSignal(t *testing.T, provider *providers.MockProvider, shutdownTimeout time.Duration) {
+	t.Helper()
+
+	service := ddns.NewServiceWithIPDetector(provider, ddns.Config{Domain: "example.com", RecordType: "A"}, providers.NewMockIPDetector("203.0.113.1"))
+
+	stopped := make(chan struct{})
+	go func() {
+		runDDNSClient(service, ddns.RunConfig{UpdateInterval: time.Hour, ShutdownTimeout: shutdownTimeout})
+		close(stopped)
+	}()
+
+	// Give the client time to install its signal handler and start the initial update
+	time.Sleep(50 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("Failed to send signal: %v", err)
+	}
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Client did not stop after the shutdown signal")
+	}
+}
+
+func TestShutdownWaitsForInFlightUpdate(t *testing.T) {
+	provider := providers.NewMockProvider("test").WithLatency(100 * time.Millisecond)
+
+	runUntilSignal(t, provider, 5*time.Second)
+
+	updates := provider.CallsForMethod("UpdateRecord")
+	if len(updates) != 1 {
+		t.Fatalf("Expected the in-flight update to complete, got %d UpdateRecord calls", len(updates))
+	}
+	if updates[0].Err != nil {
+		t.Errorf("Expected the update to succeed, got %v", updates[0].Err)
+	}
+}
+
+func TestShutdownCancelsUpdateAfterTimeout(t *testing.T) {
+	provider := providers.NewMockProvider("test").WithLatency(time.Minute)
+
+	start := time.Now()
+	runUntilSignal(t, provider, 50*time.Millisecond)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected shutdown to give up after the timeout, took %s", elapsed)
+	}
+
+	// The cancelled lookup is recorded once the update goroutine observes the cancellation
+	deadline := time.Now().Add(time.Second)
+	for len(provider.CallsForMethod("GetCurrentRecord")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	lookups := provider.CallsForMethod("GetCurrentRecord")
+	if len(lookups) != 1 || !errors.Is(lookups[0].Err, context.Canceled) {
+		t.Errorf("Expected the in-flight lookup to be cancelled, got %+v", lookups)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// writePIDFile records the current process ID in path and returns a function that removes it again
+// A PID file left by a process that is still running is an error; one left by a dead process is replaced
+// The file is created exclusively, so of two daemons starting together only one gets it
+func writePIDFile(path string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+
+	err := createPIDFile(path)
+	if errors.Is(err, os.ErrExist) {
+		if pid, running := pidFileProcess(path); running {
+			return nil, fmt.Errorf("PID file %s belongs to running process %d", path, pid)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale PID file: %w", err)
+		}
+		err = createPIDFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write PID file: %w", err)
+	}
+
+	return func() {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to remove PID file %s: %v", path, err)
+		}
+	}, nil
+}
+
+// createPIDFile writes the current process ID to path, failing with os.ErrExist if the file is already there
+func createPIDFile(path string) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// pidFileProcess reports the process ID stored in path and whether that process is still running
+func pidFileProcess(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, processRunning(pid)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritePIDFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns.pid")
+
+	remove, err := writePIDFile(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected PID file to exist, got %v", err)
+	}
+	if got := strings.TrimSpace(string(data)); got != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected PID %d, got %s", os.Getpid(), got)
+	}
+
+	remove()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected PID file to be removed, got %v", err)
+	}
+}
+
+func TestWritePIDFileRefusesRunningProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns.pid")
+
+	// This test process is certainly running
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := writePIDFile(path); err == nil || !strings.Contains(err.Error(), "running process") {
+		t.Errorf("Expected error for a PID file of a running process, got %v", err)
+	}
+}
+
+func TestWritePIDFileReplacesStalePID(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ddns.pid")
+
+	// A process that has exited and been reaped leaves a stale PID behind
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("Cannot start a child process: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(strconv.Itoa(cmd.Process.Pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	remove, err := writePIDFile(path)
+	if err != nil {
+		t.Fatalf("Expected stale PID file to be replaced, got %v", err)
+	}
+	defer remove()
+
+	data, _ := os.ReadFile(path)
+	if got := strings.TrimSpace(string(data)); got != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected PID %d, got %s", os.Getpid(), got)
+	}
+}
+
+func TestWritePIDFileDisabled(t *testing.T) {
+	remove, err := writePIDFile("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	remove()
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// updateSignal is nil where there is no SIGUSR1, so updates only run on the interval
+var updateSignal os.Signal
+
+// processRunning reports whether a process with the given ID exists
+func processRunning(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
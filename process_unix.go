@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// updateSignal asks a running daemon for an immediate update
+var updateSignal os.Signal = syscall.SIGUSR1
+
+// processRunning reports whether a process with the given ID exists
+func processRunning(pid int) bool {
+	// Signal 0 only checks the process exists; EPERM means it exists but belongs to another user
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
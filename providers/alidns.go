@@ -0,0 +1,374 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const aliDNSBaseURL = "https://alidns.aliyuncs.com/"
+
+// aliDNSAPIVersion is the AliDNS RPC API version the requests are written against
+const aliDNSAPIVersion = "2015-01-09"
+
+// aliDNSMinTTL is the lowest TTL AliDNS accepts on free plans
+const aliDNSMinTTL = 600
+
+// aliDNSDomainPageSize is how many domains each DescribeDomains call asks for, the API's maximum
+const aliDNSDomainPageSize = 100
+
+// AliDNSProvider implements the DDNS Provider interface for Alibaba Cloud DNS
+type AliDNSProvider struct {
+	accessKeyID     string
+	accessKeySecret string
+	zone            string // Domain records belong to; looked up with DescribeDomains when empty
+	baseURL         string
+	httpClient      *http.Client
+	executor        *executor.Executor
+
+	// Overridable for tests
+	now   func() time.Time
+	nonce func() string
+}
+
+// AliDNSConfig holds AliDNS-specific configuration
+type AliDNSConfig struct {
+	AccessKeyID     string
+	AccessKeySecret string
+	Zone            string             // Optional domain records belong to, e.g. example.com.cn; found through DescribeDomains when empty
+	HTTPClient      *http.Client       // Optional shared client; a bare client is used when nil
+	Executor        *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// aliDNSRecord represents a DNS record in a DescribeDomainRecords response
+type aliDNSRecord struct {
+	RecordID string `json:"RecordId"`
+	RR       string `json:"RR"`
+	Type     string `json:"Type"`
+	Value    string `json:"Value"`
+}
+
+// aliDNSResponse represents the fields used from AliDNS responses
+type aliDNSResponse struct {
+	RecordID      string `json:"RecordId"`
+	DomainRecords struct {
+		Record []aliDNSRecord `json:"Record"`
+	} `json:"DomainRecords"`
+	Domains struct {
+		Domain []struct {
+			DomainName string `json:"DomainName"`
+		} `json:"Domain"`
+	} `json:"Domains"`
+	TotalCount int `json:"TotalCount"`
+
+	// Set on errors
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// NewAliDNSProvider creates a new AliDNS DDNS provider
+func NewAliDNSProvider(config AliDNSConfig) *AliDNSProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &AliDNSProvider{
+		accessKeyID:     config.AccessKeyID,
+		accessKeySecret: config.AccessKeySecret,
+		zone:            config.Zone,
+		baseURL:         aliDNSBaseURL,
+		httpClient:      httpClientOrDefault(config.HTTPClient),
+		executor:        exec,
+		now:             time.Now,
+		nonce:           newAliDNSNonce,
+	}
+}
+
+// UpdateRecord updates a DNS record in AliDNS, creating it if it does not exist yet
+func (a *AliDNSProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, a.GetProviderName(), "UpdateRecord")
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		zone, err := a.findZone(taskCtx, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		rr := aliDNSRR(relativeName(req.Domain, zone))
+
+		existing, err := a.findRecord(taskCtx, zone, rr, req.RecordType)
+		if err != nil {
+			return nil, err
+		}
+
+		params := url.Values{}
+		params.Set("RR", rr)
+		params.Set("Type", req.RecordType)
+		params.Set("Value", req.Value)
+		if req.TTL >= aliDNSMinTTL {
+			params.Set("TTL", strconv.Itoa(req.TTL))
+		}
+
+		action := "AddDomainRecord"
+		if existing != nil {
+			action = "UpdateDomainRecord"
+			params.Set("RecordId", existing.RecordID)
+		} else {
+			params.Set("DomainName", zone)
+		}
+
+		reply, err := a.call(taskCtx, action, params)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "AliDNS record updated successfully",
+			RecordID:  reply.RecordID,
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(a.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value from DescribeDomainRecords
+func (a *AliDNSProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, a.GetProviderName(), "GetCurrentRecord")
+
+	task := func(taskCtx context.Context) (*aliDNSRecord, error) {
+		zone, err := a.findZone(taskCtx, domain)
+		if err != nil {
+			return nil, err
+		}
+		return a.findRecord(taskCtx, zone, aliDNSRR(relativeName(domain, zone)), recordType)
+	}
+
+	record, err := executor.ExecuteSimple(a.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("AliDNS %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return record.Value, nil
+}
+
+// ValidateCredentials checks if the AliDNS AccessKey pair is valid
+func (a *AliDNSProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, a.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		params := url.Values{}
+		params.Set("PageSize", "1")
+		_, err := a.call(taskCtx, "DescribeDomains", params)
+		return nil, err
+	}
+
+	_, err = executor.ExecuteSimple(a.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (a *AliDNSProvider) GetProviderName() string {
+	return "alidns"
+}
+
+// Capabilities reports the record types Alibaba Cloud DNS supports
+func (a *AliDNSProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("alidns")
+}
+
+// findZone returns the configured zone, or the longest domain in the account that fqdn falls under
+func (a *AliDNSProvider) findZone(ctx context.Context, fqdn string) (string, error) {
+	if a.zone != "" {
+		return a.zone, nil
+	}
+
+	var zones []string
+	for page := 1; ; page++ {
+		params := url.Values{}
+		params.Set("PageNumber", strconv.Itoa(page))
+		params.Set("PageSize", strconv.Itoa(aliDNSDomainPageSize))
+
+		reply, err := a.call(ctx, "DescribeDomains", params)
+		if err != nil {
+			return "", err
+		}
+		for _, domain := range reply.Domains.Domain {
+			zones = append(zones, domain.DomainName)
+		}
+
+		if len(reply.Domains.Domain) == 0 || len(zones) >= reply.TotalCount {
+			break
+		}
+	}
+
+	best := closestZone(fqdn, zones, func(zone string) string { return zone })
+	if best == nil {
+		return "", fmt.Errorf("AliDNS %w: no domain in the account for %s", ddns.ErrNotFound, fqdn)
+	}
+
+	return *best, nil
+}
+
+// findRecord looks up the record matching the RR and type, returning nil if none exists
+func (a *AliDNSProvider) findRecord(ctx context.Context, zone, rr, recordType string) (*aliDNSRecord, error) {
+	params := url.Values{}
+	params.Set("DomainName", zone)
+	params.Set("RRKeyWord", rr)
+	params.Set("Type", recordType)
+
+	reply, err := a.call(ctx, "DescribeDomainRecords", params)
+	if err != nil {
+		return nil, err
+	}
+
+	// RRKeyWord is a fuzzy match, so the exact RR still has to be checked
+	return findRecordByNameType(reply.DomainRecords.Record, rr, recordType, func(r aliDNSRecord) (string, string) {
+		return r.RR, r.Type
+	}), nil
+}
+
+// call performs a signed AliDNS RPC action
+func (a *AliDNSProvider) call(ctx context.Context, action string, params url.Values) (*aliDNSResponse, error) {
+	params.Set("Action", action)
+	params.Set("Format", "JSON")
+	params.Set("Version", aliDNSAPIVersion)
+	params.Set("AccessKeyId", a.accessKeyID)
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("SignatureNonce", a.nonce())
+	params.Set("Timestamp", a.now().UTC().Format("2006-01-02T15:04:05Z"))
+	params.Set("Signature", aliDNSSign(a.accessKeySecret, aliDNSStringToSign("GET", params)))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", a.baseURL+"?"+aliDNSCanonicalQuery(params), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var reply aliDNSResponse
+	if err := json.Unmarshal(body, &reply); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, httpStatusError(resp, nil)
+		}
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpStatusError(resp, fmt.Errorf("AliDNS %s failed with code %s: %s", action, reply.Code, reply.Message))
+	}
+	if reply.Code != "" {
+		return nil, fmt.Errorf("AliDNS %s failed with code %s: %s", action, reply.Code, reply.Message)
+	}
+
+	return &reply, nil
+}
+
+// aliDNSStringToSign builds the RPC signature input: method, encoded "/", and the encoded canonical query
+func aliDNSStringToSign(method string, params url.Values) string {
+	return method + "&" + aliDNSPercentEncode("/") + "&" + aliDNSPercentEncode(aliDNSCanonicalQuery(params))
+}
+
+// aliDNSSign signs the string with HMAC-SHA1, keyed by the secret followed by "&"
+func aliDNSSign(secret, stringToSign string) string {
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliDNSCanonicalQuery encodes the parameters sorted by key, as the signature requires
+func aliDNSCanonicalQuery(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, aliDNSPercentEncode(key)+"="+aliDNSPercentEncode(params.Get(key)))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// aliDNSPercentEncode applies RFC 3986 encoding, which differs from url.QueryEscape for space, "*" and "~"
+func aliDNSPercentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
+
+// aliDNSRR returns the record name AliDNS expects, using "@" for the apex
+func aliDNSRR(host string) string {
+	if host == "" {
+		return "@"
+	}
+	return host
+}
+
+// newAliDNSNonce returns a random nonce so replayed requests are rejected
+func newAliDNSNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// registerAliDNS adds the AliDNS provider to the factory
+func registerAliDNS(f *Factory) {
+	f.Register("alidns", func(config ddns.Config) (ddns.Provider, error) {
+		if config.Username == "" {
+			return nil, fmt.Errorf("alidns provider requires a username (AccessKey ID)")
+		}
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("alidns provider requires API key (AccessKey secret)")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewAliDNSProvider(AliDNSConfig{
+			AccessKeyID:     config.Username,
+			AccessKeySecret: config.APIKey,
+			Zone:            config.ZoneID,
+			HTTPClient:      client,
+			Executor:        f.executor,
+		}), nil
+	})
+}
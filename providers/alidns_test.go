@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestAliDNSStringToSign(t *testing.T) {
+	// Example from the Alibaba Cloud DNS signature documentation
+	params := url.Values{}
+	params.Set("AccessKeyId", "testid")
+	params.Set("Action", "DescribeDomainRecords")
+	params.Set("DomainName", "example.com")
+	params.Set("Format", "XML")
+	params.Set("SignatureMethod", "HMAC-SHA1")
+	params.Set("SignatureNonce", "f59ed6a9-83fc-473b-9cc6-99c95df3856e")
+	params.Set("SignatureVersion", "1.0")
+	params.Set("Timestamp", "2016-03-24T16:41:54Z")
+	params.Set("Version", "2015-01-09")
+
+	want := "GET&%2F&AccessKeyId%3Dtestid%26Action%3DDescribeDomainRecords%26DomainName%3Dexample.com" +
+		"%26Format%3DXML%26SignatureMethod%3DHMAC-SHA1%26SignatureNonce%3Df59ed6a9-83fc-473b-9cc6-99c95df3856e" +
+		"%26SignatureVersion%3D1.0%26Timestamp%3D2016-03-24T16%253A41%253A54Z%26Version%3D2015-01-09"
+
+	got := aliDNSStringToSign("GET", params)
+	if got != want {
+		t.Fatalf("aliDNSStringToSign mismatch\ngot:  %s\nwant: %s", got, want)
+	}
+
+	if sig := aliDNSSign("testsecret", got); sig != "uRpHwaSEt3J+6KQD//svCh/x+pI=" {
+		t.Errorf("Expected documented signature, got %s", sig)
+	}
+}
+
+func TestAliDNSPercentEncode(t *testing.T) {
+	tests := map[string]string{
+		"a b":   "a%20b",
+		"a*b":   "a%2Ab",
+		"a~b":   "a~b",
+		"a+b":   "a%2Bb",
+		"a/b=c": "a%2Fb%3Dc",
+	}
+
+	for input, want := range tests {
+		if got := aliDNSPercentEncode(input); got != want {
+			t.Errorf("aliDNSPercentEncode(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestAliDNSUpdateRecordSignsAndModifies(t *testing.T) {
+	var actions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		actions = append(actions, query.Get("Action"))
+
+		// The server recomputes the signature over everything but the signature itself
+		signature := query.Get("Signature")
+		query.Del("Signature")
+		if want := aliDNSSign("secret", aliDNSStringToSign("GET", query)); signature != want {
+			t.Errorf("Expected signature %s, got %s", want, signature)
+		}
+
+		switch query.Get("Action") {
+		case "DescribeDomains":
+			fmt.Fprint(w, `{"TotalCount":2,"Domains":{"Domain":[{"DomainName":"example.org"},{"DomainName":"example.com"}]}}`)
+		case "DescribeDomainRecords":
+			if query.Get("DomainName") != "example.com" || query.Get("RRKeyWord") != "home" {
+				t.Errorf("Unexpected describe params %v", query)
+			}
+			fmt.Fprint(w, `{"DomainRecords":{"Record":[{"RecordId":"9","RR":"homelab","Type":"A","Value":"1.1.1.1"},{"RecordId":"7","RR":"home","Type":"A","Value":"203.0.113.1"}]}}`)
+		case "UpdateDomainRecord":
+			if query.Get("RecordId") != "7" || query.Get("Value") != "203.0.113.2" {
+				t.Errorf("Unexpected update params %v", query)
+			}
+			fmt.Fprint(w, `{"RecordId":"7","RequestId":"abc"}`)
+		default:
+			t.Errorf("Unexpected action %s", query.Get("Action"))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAliDNSProvider(AliDNSConfig{AccessKeyID: "id", AccessKeySecret: "secret"})
+	provider.baseURL = server.URL + "/"
+	provider.now = func() time.Time { return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.2"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(actions) != 3 || actions[2] != "UpdateDomainRecord" {
+		t.Errorf("Expected the domain and record lookups then an update, got %v", actions)
+	}
+	if resp.RecordID != "7" {
+		t.Errorf("Expected record ID 7, got %s", resp.RecordID)
+	}
+}
+
+func TestAliDNSFindsZoneUnderPublicSuffix(t *testing.T) {
+	var pages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("Action") {
+		case "DescribeDomains":
+			// One domain per page, so the second page must be requested
+			pages = append(pages, query.Get("PageNumber"))
+			name := "example.cn"
+			if query.Get("PageNumber") == "2" {
+				name = "example.com.cn"
+			}
+			fmt.Fprintf(w, `{"TotalCount":2,"Domains":{"Domain":[{"DomainName":%q}]}}`, name)
+		case "DescribeDomainRecords":
+			if query.Get("DomainName") != "example.com.cn" || query.Get("RRKeyWord") != "www" {
+				t.Errorf("Unexpected describe params %v", query)
+			}
+			fmt.Fprint(w, `{"DomainRecords":{"Record":[{"RecordId":"7","RR":"www","Type":"A","Value":"203.0.113.1"}]}}`)
+		default:
+			t.Errorf("Unexpected action %s", query.Get("Action"))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewAliDNSProvider(AliDNSConfig{AccessKeyID: "id", AccessKeySecret: "secret"})
+	provider.baseURL = server.URL + "/"
+
+	value, err := provider.GetCurrentRecord(context.Background(), "www.example.com.cn", "A")
+	if err != nil || value != "203.0.113.1" {
+		t.Fatalf("Expected 203.0.113.1, got %q, %v", value, err)
+	}
+	if len(pages) != 2 {
+		t.Errorf("Expected both DescribeDomains pages, got %q", pages)
+	}
+
+	// A configured zone skips DescribeDomains
+	pages = nil
+	provider.zone = "example.com.cn"
+	if _, err := provider.GetCurrentRecord(context.Background(), "www.example.com.cn", "A"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(pages) != 0 {
+		t.Errorf("Expected no DescribeDomains call with a configured zone, got %q", pages)
+	}
+}
+
+func TestAliDNSErrorResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"Code":"InvalidAccessKeyId.NotFound","Message":"Specified access key is not found."}`)
+	}))
+	defer server.Close()
+
+	provider := NewAliDNSProvider(AliDNSConfig{AccessKeyID: "id", AccessKeySecret: "secret"})
+	provider.baseURL = server.URL + "/"
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	err := provider.ValidateCredentials(context.Background())
+	if err == nil {
+		t.Fatal("Expected error for invalid access key")
+	}
+	if want := "alidns ValidateCredentials: AliDNS DescribeDomains failed with code InvalidAccessKeyId.NotFound: Specified access key is not found."; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+}
@@ -0,0 +1,118 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// CachingProvider wraps a provider and caches record values to avoid redundant API calls
+type CachingProvider struct {
+	inner ddns.Provider
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+// cacheEntry holds a cached record value and when it was stored
+type cacheEntry struct {
+	value    string
+	cachedAt time.Time
+}
+
+// NewCachingProvider creates a provider that caches GetCurrentRecord results for ttl
+func NewCachingProvider(inner ddns.Provider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{
+		inner:   inner,
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// UpdateRecord delegates to the inner provider and caches the new value on success
+func (c *CachingProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, c.GetProviderName(), "UpdateRecord")
+
+	resp, err := c.inner.UpdateRecord(ctx, req)
+	if err != nil {
+		// The record may or may not have changed, so don't trust the cache
+		c.invalidate(req.Domain, req.RecordType)
+		return nil, err
+	}
+
+	if resp.Success {
+		c.store(req.Domain, req.RecordType, req.Value)
+	}
+
+	return resp, nil
+}
+
+// GetCurrentRecord returns the cached value while fresh, otherwise queries the inner provider
+func (c *CachingProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, c.GetProviderName(), "GetCurrentRecord")
+
+	key := cacheKey(domain, recordType)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok && c.now().Sub(entry.cachedAt) < c.ttl {
+		return entry.value, nil
+	}
+
+	value, err := c.inner.GetCurrentRecord(ctx, domain, recordType)
+	if err != nil {
+		return "", err
+	}
+
+	c.store(domain, recordType, value)
+	return value, nil
+}
+
+// ValidateCredentials delegates to the inner provider
+func (c *CachingProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, c.GetProviderName(), "ValidateCredentials")
+
+	return c.inner.ValidateCredentials(ctx)
+}
+
+// GetProviderName returns the name of the inner provider
+func (c *CachingProvider) GetProviderName() string {
+	return c.inner.GetProviderName()
+}
+
+// Capabilities returns the capabilities of the inner provider
+func (c *CachingProvider) Capabilities() ddns.Capabilities {
+	return c.inner.Capabilities()
+}
+
+// store caches a record value
+func (c *CachingProvider) store(domain, recordType, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[cacheKey(domain, recordType)] = cacheEntry{
+		value:    value,
+		cachedAt: c.now(),
+	}
+}
+
+// invalidate removes a cached record value
+func (c *CachingProvider) invalidate(domain, recordType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, cacheKey(domain, recordType))
+}
+
+// cacheKey builds the cache key for a record
+func cacheKey(domain, recordType string) string {
+	return fmt.Sprintf("%s:%s", domain, recordType)
+}
@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// countingProvider counts GetCurrentRecord calls on top of a mock provider
+type countingProvider struct {
+	*MockProvider
+	mu      sync.Mutex
+	lookups int
+}
+
+func (c *countingProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error) {
+	c.mu.Lock()
+	c.lookups++
+	c.mu.Unlock()
+	return c.MockProvider.GetCurrentRecord(ctx, domain, recordType)
+}
+
+func TestCachingProviderCachesLookups(t *testing.T) {
+	inner := &countingProvider{MockProvider: NewMockProvider("test")}
+	inner.SetRecord("example.com", "A", "203.0.113.1")
+
+	provider := NewCachingProvider(inner, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		value, err := provider.GetCurrentRecord(ctx, "example.com", "A")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if value != "203.0.113.1" {
+			t.Errorf("Expected 203.0.113.1, got %s", value)
+		}
+	}
+
+	if inner.lookups != 1 {
+		t.Errorf("Expected inner provider to be queried once, got %d", inner.lookups)
+	}
+}
+
+func TestCachingProviderExpiresEntries(t *testing.T) {
+	inner := &countingProvider{MockProvider: NewMockProvider("test")}
+	inner.SetRecord("example.com", "A", "203.0.113.1")
+
+	provider := NewCachingProvider(inner, time.Minute)
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	provider.GetCurrentRecord(ctx, "example.com", "A")
+	now = now.Add(2 * time.Minute)
+	provider.GetCurrentRecord(ctx, "example.com", "A")
+
+	if inner.lookups != 2 {
+		t.Errorf("Expected expired entry to be refreshed, got %d lookups", inner.lookups)
+	}
+}
+
+func TestCachingProviderUpdateRefreshesCache(t *testing.T) {
+	inner := &countingProvider{MockProvider: NewMockProvider("test")}
+	provider := NewCachingProvider(inner, time.Minute)
+	ctx := context.Background()
+
+	req := ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.9"}
+	if _, err := provider.UpdateRecord(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	value, err := provider.GetCurrentRecord(ctx, "example.com", "A")
+	if err != nil || value != "203.0.113.9" {
+		t.Errorf("Expected cached value 203.0.113.9, got %q, %v", value, err)
+	}
+
+	if inner.lookups != 0 {
+		t.Errorf("Expected lookup to be served from cache after update, got %d lookups", inner.lookups)
+	}
+}
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// FieldDescriptor describes a configuration field a provider reads
+type FieldDescriptor struct {
+	Name        string `json:"name"`    // Key in the "ddns" section of config.json
+	EnvVar      string `json:"env_var"` // Equivalent environment variable
+	Description string `json:"description"`
+}
+
+// ProviderDescriptor describes a provider and the configuration it needs
+type ProviderDescriptor struct {
+	Name           string            `json:"name"`
+	Description    string            `json:"description"`
+	RequiredFields []FieldDescriptor `json:"required_fields"`
+	OptionalFields []FieldDescriptor `json:"optional_fields"`
+	SupportsIPv6   bool              `json:"supports_ipv6"`
+	Capabilities   ddns.Capabilities `json:"capabilities"`
+}
+
+// Fields shared by several providers
+var (
+	domainField     = FieldDescriptor{Name: "domain", EnvVar: "DDNS_DOMAIN", Description: "Fully qualified record to update"}
+	recordTypeField = FieldDescriptor{Name: "record_type", EnvVar: "DDNS_RECORD_TYPE", Description: "Record type to update"}
+	targetField     = FieldDescriptor{Name: "target", EnvVar: "DDNS_TARGET", Description: "Target hostname for CNAME records"}
+)
+
+// providerDescriptors holds the metadata for each supported provider
+var providerDescriptors = map[string]ProviderDescriptor{
+	"duckdns": {
+		Description:    "DuckDNS free dynamic DNS (duckdns.org)",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "DuckDNS account token"}},
+		OptionalFields: []FieldDescriptor{recordTypeField},
+	},
+	"namesilo": {
+		Description:    "NameSilo registrar DNS",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "NameSilo API key"}},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField},
+	},
+	"linode": {
+		Description:    "Linode (Akamai Cloud) DNS Manager",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "Personal access token with Domains read/write scope"}},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField, {Name: "zone_id", EnvVar: "DDNS_ZONE_ID", Description: "Numeric domain ID, looked up by name when omitted"}},
+	},
+	"vultr": {
+		Description:    "Vultr DNS",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "Vultr API key"}},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField},
+	},
+	"namedotcom": {
+		Description: "Name.com registrar DNS",
+		RequiredFields: []FieldDescriptor{
+			domainField,
+			{Name: "username", EnvVar: "DDNS_USERNAME", Description: "Name.com account username"},
+			{Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "Name.com API token"},
+		},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField, {Name: "zone_id", EnvVar: "DDNS_ZONE_ID", Description: "Domain holding the record, derived from the record name when omitted"}},
+	},
+	"hurricane_electric": {
+		Description:    "Hurricane Electric free DNS (dns.he.net) dynamic records",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "DDNS key generated for the record"}},
+		OptionalFields: []FieldDescriptor{recordTypeField},
+	},
+	"dnspod": {
+		Description:    "DNSPod (Tencent Cloud) DNS",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "Login token in \"ID,Token\" form"}},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField},
+	},
+	"alidns": {
+		Description: "Alibaba Cloud DNS (AliDNS)",
+		RequiredFields: []FieldDescriptor{
+			domainField,
+			{Name: "username", EnvVar: "DDNS_USERNAME", Description: "AccessKey ID"},
+			{Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "AccessKey secret"},
+		},
+		OptionalFields: []FieldDescriptor{recordTypeField, targetField},
+	},
+	"dynu": {
+		Description:    "Dynu free dynamic DNS",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "Dynu API key (Control Panel > API Credentials)"}},
+		OptionalFields: []FieldDescriptor{recordTypeField},
+	},
+	"noip": {
+		Description: "No-IP dynamic DNS (noip.com)",
+		RequiredFields: []FieldDescriptor{
+			domainField,
+			{Name: "username", EnvVar: "DDNS_USERNAME", Description: "No-IP account username, or a DDNS key's username"},
+			{Name: "api_key", EnvVar: "DDNS_API_KEY", Description: "No-IP account password, or the DDNS key's password"},
+		},
+	},
+	"file": {
+		Description:    "Writes records into a hosts-style file",
+		RequiredFields: []FieldDescriptor{domainField, {Name: "file_path", EnvVar: "DDNS_FILE_PATH", Description: "Hosts-style file to manage"}},
+		OptionalFields: []FieldDescriptor{recordTypeField},
+	},
+	"mock": {
+		Description:    "In-memory provider for testing",
+		RequiredFields: []FieldDescriptor{domainField},
+	},
+}
+
+// DescribeProvider returns the metadata for the named provider
+// Unknown providers yield a descriptor with only the name set
+func (f *Factory) DescribeProvider(name string) ProviderDescriptor {
+	descriptor, ok := providerDescriptors[name]
+	if !ok {
+		return ProviderDescriptor{Name: name}
+	}
+
+	descriptor.Name = name
+	descriptor.SupportsIPv6 = supportsRecordType(name, "AAAA")
+	descriptor.Capabilities = recordCapabilities(name)
+	return descriptor
+}
+
+// supportsRecordType reports whether the provider can update records of the given type
+func supportsRecordType(provider, recordType string) bool {
+	types, known := supportedRecordTypes[provider]
+	if !known {
+		return false
+	}
+	if types == nil {
+		return true
+	}
+
+	for _, supported := range types {
+		if strings.EqualFold(supported, recordType) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCapabilities builds a provider's capabilities from the record types it supports
+func recordCapabilities(provider string) ddns.Capabilities {
+	return ddns.Capabilities{
+		SupportsRead:  true,
+		SupportsAAAA:  supportsRecordType(provider, "AAAA"),
+		SupportsTXT:   supportsRecordType(provider, "TXT"),
+		SupportsCNAME: supportsRecordType(provider, "CNAME"),
+		SupportsSRV:   supportsRecordType(provider, "SRV"),
+	}
+}
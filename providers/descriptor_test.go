@@ -0,0 +1,71 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+func TestDescribeProviderCoversSupportedProviders(t *testing.T) {
+	factory := NewFactory()
+
+	for _, name := range factory.GetSupportedProviders() {
+		descriptor := factory.DescribeProvider(name)
+		if descriptor.Name != name {
+			t.Errorf("Expected descriptor name %s, got %s", name, descriptor.Name)
+		}
+		if descriptor.Description == "" {
+			t.Errorf("Provider %s has no description", name)
+		}
+		if len(descriptor.RequiredFields) == 0 {
+			t.Errorf("Provider %s lists no required fields", name)
+		}
+	}
+
+	if !factory.DescribeProvider("duckdns").SupportsIPv6 {
+		t.Error("Expected duckdns to support IPv6")
+	}
+
+	if descriptor := factory.DescribeProvider("unknown"); descriptor.Description != "" || descriptor.Name != "unknown" {
+		t.Errorf("Expected empty descriptor for unknown provider, got %+v", descriptor)
+	}
+}
+
+func TestProviderCapabilities(t *testing.T) {
+	duckdns := NewDuckDNSProvider(DuckDNSConfig{Token: "token"}).Capabilities()
+	if !duckdns.SupportsRead || !duckdns.SupportsAAAA || duckdns.SupportsTXT || duckdns.SupportsCNAME {
+		t.Errorf("Unexpected duckdns capabilities %s", duckdns)
+	}
+
+	if capabilities := NewMockProvider("test").Capabilities(); !capabilities.SupportsRead || !capabilities.SupportsCNAME {
+		t.Errorf("Expected mock to support everything, got %s", capabilities)
+	}
+}
+
+func TestDescribeProviderMatchesProviderCapabilities(t *testing.T) {
+	configs := []ddns.Config{
+		{Provider: "duckdns", APIKey: "c0ffee00-1234-4abc-9def-0123456789ab"},
+		{Provider: "namesilo", APIKey: "key"},
+		{Provider: "linode", APIKey: "token"},
+		{Provider: "vultr", APIKey: "key"},
+		{Provider: "namedotcom", Username: "alice", APIKey: "token"},
+		{Provider: "hurricane_electric", APIKey: "key"},
+		{Provider: "dnspod", APIKey: "12345,secret"},
+		{Provider: "alidns", Username: "id", APIKey: "secret"},
+		{Provider: "dynu", APIKey: "key"},
+		{Provider: "noip", Username: "alice", APIKey: "secret"},
+		{Provider: "file", FilePath: "/tmp/hosts"},
+		{Provider: "mock"},
+	}
+
+	factory := NewFactory()
+	for _, config := range configs {
+		provider, err := factory.CreateProvider(config)
+		if err != nil {
+			t.Fatalf("Expected %s provider, got %v", config.Provider, err)
+		}
+		if got, want := factory.DescribeProvider(config.Provider).Capabilities, provider.Capabilities(); got != want {
+			t.Errorf("Provider %s: descriptor reports %s, provider reports %s", config.Provider, got, want)
+		}
+	}
+}
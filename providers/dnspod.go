@@ -0,0 +1,341 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const dnsPodBaseURL = "https://dnsapi.cn"
+
+// DNSPod status codes
+const (
+	dnsPodSuccessCode   = "1"
+	dnsPodNoRecordsCode = "10" // Record.List found no matching records
+)
+
+// dnsPodDefaultLineID is the "default" resolution line every record needs
+const dnsPodDefaultLineID = "0"
+
+// dnsPodMinTTL is the lowest TTL DNSPod accepts on free plans
+const dnsPodMinTTL = 600
+
+// dnsPodDomainPageSize is how many domains each Domain.List call asks for
+const dnsPodDomainPageSize = 100
+
+// DNSPodProvider implements the DDNS Provider interface for DNSPod (Tencent Cloud)
+type DNSPodProvider struct {
+	loginToken string
+	zone       string // Domain records belong to; looked up with Domain.List when empty
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+}
+
+// DNSPodConfig holds DNSPod-specific configuration
+type DNSPodConfig struct {
+	LoginToken string             // API token in "ID,Token" form
+	Zone       string             // Optional domain records belong to, e.g. example.com.cn; found through Domain.List when empty
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// dnsPodStatus is the status block included in every DNSPod response
+type dnsPodStatus struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// dnsPodRecord represents a DNS record in a Record.List response
+type dnsPodRecord struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// dnsPodResponse represents the envelope returned by the DNSPod API
+type dnsPodResponse struct {
+	Status  dnsPodStatus   `json:"status"`
+	Records []dnsPodRecord `json:"records"`
+	Record  struct {
+		ID json.Number `json:"id"`
+	} `json:"record"`
+	Domains []struct {
+		Name string `json:"name"`
+	} `json:"domains"`
+	Info struct {
+		DomainTotal json.Number `json:"domain_total"`
+	} `json:"info"`
+}
+
+// NewDNSPodProvider creates a new DNSPod DDNS provider
+func NewDNSPodProvider(config DNSPodConfig) *DNSPodProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &DNSPodProvider{
+		loginToken: config.LoginToken,
+		zone:       config.Zone,
+		baseURL:    dnsPodBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+		executor:   exec,
+	}
+}
+
+// UpdateRecord updates a DNS record in DNSPod, creating it if it does not exist yet
+func (d *DNSPodProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "UpdateRecord")
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		zone, err := d.findZone(taskCtx, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+		subDomain := dnsPodSubDomain(relativeName(req.Domain, zone))
+
+		existing, err := d.findRecord(taskCtx, zone, subDomain, req.RecordType)
+		if err != nil {
+			return nil, err
+		}
+
+		params := url.Values{}
+		params.Set("domain", zone)
+		params.Set("sub_domain", subDomain)
+		params.Set("record_type", req.RecordType)
+		params.Set("record_line_id", dnsPodDefaultLineID)
+		params.Set("value", req.Value)
+		if req.TTL >= dnsPodMinTTL {
+			params.Set("ttl", strconv.Itoa(req.TTL))
+		}
+
+		action := "Record.Create"
+		if existing != nil {
+			action = "Record.Modify"
+			params.Set("record_id", existing.ID)
+		}
+
+		reply, err := d.call(taskCtx, action, params)
+		if err != nil {
+			return nil, err
+		}
+
+		recordID := reply.Record.ID.String()
+		if recordID == "" && existing != nil {
+			recordID = existing.ID
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "DNSPod record updated successfully",
+			RecordID:  recordID,
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(d.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value from Record.List
+func (d *DNSPodProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "GetCurrentRecord")
+
+	task := func(taskCtx context.Context) (*dnsPodRecord, error) {
+		zone, err := d.findZone(taskCtx, domain)
+		if err != nil {
+			return nil, err
+		}
+		return d.findRecord(taskCtx, zone, dnsPodSubDomain(relativeName(domain, zone)), recordType)
+	}
+
+	record, err := executor.ExecuteSimple(d.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("DNSPod %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return record.Value, nil
+}
+
+// ValidateCredentials checks if the DNSPod login token is valid
+func (d *DNSPodProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		_, err := d.call(taskCtx, "User.Detail", url.Values{})
+		return nil, err
+	}
+
+	_, err = executor.ExecuteSimple(d.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (d *DNSPodProvider) GetProviderName() string {
+	return "dnspod"
+}
+
+// Capabilities reports the record types DNSPod supports
+func (d *DNSPodProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("dnspod")
+}
+
+// findZone returns the configured zone, or the longest domain in the account that fqdn falls under
+// Two-label guesses would break names under public suffixes such as com.cn, so the account is asked
+func (d *DNSPodProvider) findZone(ctx context.Context, fqdn string) (string, error) {
+	if d.zone != "" {
+		return d.zone, nil
+	}
+
+	var zones []string
+	for {
+		params := url.Values{}
+		params.Set("offset", strconv.Itoa(len(zones)))
+		params.Set("length", strconv.Itoa(dnsPodDomainPageSize))
+
+		reply, err := d.call(ctx, "Domain.List", params)
+		if err != nil {
+			return "", err
+		}
+		for _, domain := range reply.Domains {
+			zones = append(zones, domain.Name)
+		}
+
+		total, _ := reply.Info.DomainTotal.Int64()
+		if len(reply.Domains) == 0 || int64(len(zones)) >= total {
+			break
+		}
+	}
+
+	best := closestZone(fqdn, zones, func(zone string) string { return zone })
+	if best == nil {
+		return "", fmt.Errorf("DNSPod %w: no domain in the account for %s", ddns.ErrNotFound, fqdn)
+	}
+
+	return *best, nil
+}
+
+// findRecord looks up the record matching the sub-domain and type, returning nil if none exists
+func (d *DNSPodProvider) findRecord(ctx context.Context, zone, subDomain, recordType string) (*dnsPodRecord, error) {
+	params := url.Values{}
+	params.Set("domain", zone)
+	params.Set("sub_domain", subDomain)
+	params.Set("record_type", recordType)
+
+	reply, err := d.call(ctx, "Record.List", params)
+	if err != nil {
+		return nil, err
+	}
+
+	return findRecordByNameType(reply.Records, subDomain, recordType, func(r dnsPodRecord) (string, string) {
+		return r.Name, r.Type
+	}), nil
+}
+
+// call performs a DNSPod API action and checks the status code
+// An empty Record.List is reported as a successful reply with no records
+func (d *DNSPodProvider) call(ctx context.Context, action string, params url.Values) (*dnsPodResponse, error) {
+	params.Set("login_token", d.loginToken)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", d.baseURL+"/"+action, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// DNSPod asks clients to identify themselves and may block generic user agents
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpStatusError(resp, nil)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var reply dnsPodResponse
+	if err := json.Unmarshal(body, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	switch {
+	case reply.Status.Code == dnsPodSuccessCode:
+	case action == "Record.List" && reply.Status.Code == dnsPodNoRecordsCode:
+		reply.Records = nil
+	default:
+		return nil, fmt.Errorf("DNSPod %s failed with code %s: %s", action, reply.Status.Code, reply.Status.Message)
+	}
+
+	return &reply, nil
+}
+
+// dnsPodSubDomain returns the sub-domain DNSPod expects, using "@" for the apex
+func dnsPodSubDomain(host string) string {
+	if host == "" {
+		return "@"
+	}
+	return host
+}
+
+// validateDNSPodToken checks the token has the "ID,Token" form DNSPod issues
+func validateDNSPodToken(token string) error {
+	id, secret, ok := strings.Cut(token, ",")
+	if !ok || id == "" || secret == "" {
+		return fmt.Errorf("dnspod provider requires API key in \"ID,Token\" form")
+	}
+
+	if _, err := strconv.Atoi(id); err != nil {
+		return fmt.Errorf("dnspod provider requires a numeric token ID, got %q", id)
+	}
+
+	return nil
+}
+
+// registerDNSPod adds the DNSPod provider to the factory
+func registerDNSPod(f *Factory) {
+	f.Register("dnspod", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("dnspod provider requires API key (login token)")
+		}
+		if err := validateDNSPodToken(config.APIKey); err != nil {
+			return nil, err
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDNSPodProvider(DNSPodConfig{
+			LoginToken: config.APIKey,
+			Zone:       config.ZoneID,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}), nil
+	})
+}
@@ -0,0 +1,160 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestDNSPodUpdateRecordModifiesExisting(t *testing.T) {
+	var modify url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		if got := r.PostForm.Get("login_token"); got != "12345,secret" {
+			t.Errorf("Expected login token 12345,secret, got %q", got)
+		}
+
+		switch r.URL.Path {
+		case "/Domain.List":
+			fmt.Fprint(w, `{"status":{"code":"1"},"info":{"domain_total":"2"},"domains":[{"name":"example.org"},{"name":"example.com"}]}`)
+		case "/Record.List":
+			if r.PostForm.Get("domain") != "example.com" || r.PostForm.Get("sub_domain") != "home" {
+				t.Errorf("Unexpected list params %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"status":{"code":"1"},"records":[{"id":"42","name":"home","type":"A","value":"203.0.113.1"}]}`)
+		case "/Record.Modify":
+			modify = r.PostForm
+			fmt.Fprint(w, `{"status":{"code":"1"},"record":{"id":42}}`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewDNSPodProvider(DNSPodConfig{LoginToken: "12345,secret"})
+	provider.baseURL = server.URL
+
+	resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.2"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if modify.Get("record_id") != "42" || modify.Get("value") != "203.0.113.2" || modify.Get("record_type") != "A" {
+		t.Errorf("Unexpected modify params %v", modify)
+	}
+	if resp.RecordID != "42" {
+		t.Errorf("Expected record ID 42, got %s", resp.RecordID)
+	}
+}
+
+func TestDNSPodUpdateRecordCreatesMissingApex(t *testing.T) {
+	var create url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/Record.List":
+			fmt.Fprint(w, `{"status":{"code":"10","message":"No records"}}`)
+		case "/Record.Create":
+			create = r.PostForm
+			fmt.Fprint(w, `{"status":{"code":"1"},"record":{"id":"43"}}`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	// A configured zone skips Domain.List
+	provider := NewDNSPodProvider(DNSPodConfig{LoginToken: "12345,secret", Zone: "example.com"})
+	provider.baseURL = server.URL
+
+	resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.2"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if create.Get("sub_domain") != "@" {
+		t.Errorf("Expected apex sub_domain @, got %q", create.Get("sub_domain"))
+	}
+	if resp.RecordID != "43" {
+		t.Errorf("Expected record ID 43, got %s", resp.RecordID)
+	}
+}
+
+func TestDNSPodFindsZoneUnderPublicSuffix(t *testing.T) {
+	var offsets []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		switch r.URL.Path {
+		case "/Domain.List":
+			// One domain per page, so the second page must be requested
+			offsets = append(offsets, r.PostForm.Get("offset"))
+			name := "example.cn"
+			if r.PostForm.Get("offset") == "1" {
+				name = "example.com.cn"
+			}
+			fmt.Fprintf(w, `{"status":{"code":"1"},"info":{"domain_total":2},"domains":[{"name":%q}]}`, name)
+		case "/Record.List":
+			if r.PostForm.Get("domain") != "example.com.cn" || r.PostForm.Get("sub_domain") != "www" {
+				t.Errorf("Unexpected list params %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"status":{"code":"1"},"records":[{"id":"42","name":"www","type":"A","value":"203.0.113.1"}]}`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewDNSPodProvider(DNSPodConfig{LoginToken: "12345,secret"})
+	provider.baseURL = server.URL
+
+	value, err := provider.GetCurrentRecord(context.Background(), "www.example.com.cn", "A")
+	if err != nil || value != "203.0.113.1" {
+		t.Fatalf("Expected 203.0.113.1, got %q, %v", value, err)
+	}
+	if len(offsets) != 2 || offsets[1] != "1" {
+		t.Errorf("Expected both Domain.List pages, got offsets %q", offsets)
+	}
+}
+
+func TestDNSPodValidateCredentialsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status":{"code":"-1","message":"Login failed"}}`)
+	}))
+	defer server.Close()
+
+	provider := NewDNSPodProvider(DNSPodConfig{LoginToken: "12345,wrong"})
+	provider.baseURL = server.URL
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	if err := provider.ValidateCredentials(context.Background()); err == nil {
+		t.Fatal("Expected error for failed login")
+	}
+}
+
+func TestValidateDNSPodToken(t *testing.T) {
+	tests := []struct {
+		token   string
+		wantErr bool
+	}{
+		{"12345,secret", false},
+		{"secret", true},
+		{",secret", true},
+		{"12345,", true},
+		{"abc,secret", true},
+	}
+
+	for _, tt := range tests {
+		err := validateDNSPodToken(tt.token)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("validateDNSPodToken(%q) error = %v, wantErr %v", tt.token, err, tt.wantErr)
+		}
+	}
+}
@@ -13,114 +13,193 @@ import (
 	"github.com/jq1836/DDNS/executor"
 )
 
+const duckDNSBaseURL = "https://www.duckdns.org"
+
+// ErrDuckDNSRejected is returned when DuckDNS answers KO, meaning the token or domain is invalid
+// Retrying cannot fix this, so it classifies itself as non-retryable for every executor
+var ErrDuckDNSRejected error = &duckDNSRejectedError{}
+
+// duckDNSRejectedError is the type of ErrDuckDNSRejected
+type duckDNSRejectedError struct{}
+
+// Error implements the error interface
+func (e *duckDNSRejectedError) Error() string {
+	return "DuckDNS update failed: invalid token or domain"
+}
+
+// IsRetryable implements executor.RetryableError, so shared executors don't repeat rejected updates
+func (e *duckDNSRejectedError) IsRetryable() bool {
+	return false
+}
+
 // DuckDNSProvider implements the DDNS Provider interface for DuckDNS
 type DuckDNSProvider struct {
 	token      string
+	baseURL    string
 	httpClient *http.Client
 	executor   *executor.Executor
+	nameserver string
 }
 
 // DuckDNSConfig holds DuckDNS-specific configuration
 type DuckDNSConfig struct {
-	Token string
+	Token      string
+	BaseURL    string             // Optional API base URL override, defaults to https://www.duckdns.org
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+	Nameserver string             // Optional nameserver records are read from; the system resolver is used when empty
 }
 
 // NewDuckDNSProvider creates a new DuckDNS DDNS provider
 func NewDuckDNSProvider(config DuckDNSConfig) *DuckDNSProvider {
 	// Set up executor with retry logic for API calls
-	exec := executor.NewExecutor(
-		executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)),
-		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(30*time.Second)),
-	)
+	exec := executorOrDefault(config.Executor)
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = duckDNSBaseURL
+	}
 
 	return &DuckDNSProvider{
 		token:      config.Token,
-		httpClient: &http.Client{},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClientOrDefault(config.HTTPClient),
 		executor:   exec,
+		nameserver: config.Nameserver,
 	}
 }
 
 // UpdateRecord updates a DNS record in DuckDNS
-func (d *DuckDNSProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (*ddns.UpdateResponse, error) {
+func (d *DuckDNSProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "UpdateRecord")
+
+	// DuckDNS only stores addresses, anything else would be sent as a bogus IP
+	if req.RecordType != "A" && req.RecordType != "AAAA" {
+		return nil, fmt.Errorf("DuckDNS does not support %s records, only A and AAAA", req.RecordType)
+	}
+
 	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
-		// Build the DuckDNS update URL
-		baseURL := "https://www.duckdns.org/update"
 		params := url.Values{}
 		params.Set("domains", req.Domain)
-		params.Set("token", d.token)
-		params.Set("ip", req.Value)
-
-		updateURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
-
-		// Create HTTP request
-		httpReq, err := http.NewRequestWithContext(taskCtx, "GET", updateURL, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
-		}
-
-		httpReq.Header.Set("User-Agent", "ddns-client/1.0")
+		params.Set("verbose", "true")
+		setDuckDNSAddresses(params, req)
 
-		// Make the request
-		resp, err := d.httpClient.Do(httpReq)
+		result, err := d.update(taskCtx, params)
 		if err != nil {
-			return nil, fmt.Errorf("request failed: %w", err)
+			return nil, err
 		}
-		defer resp.Body.Close()
 
-		// Read response body
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read response: %w", err)
+		response := &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "DuckDNS record updated successfully",
+			RecordID:  req.Domain, // DuckDNS doesn't have record IDs, use domain
+			UpdatedAt: time.Now(),
 		}
-
-		responseText := strings.TrimSpace(string(body))
-
-		// DuckDNS returns "OK" for success, "KO" for failure
-		if responseText == "OK" {
-			return &ddns.UpdateResponse{
-				Success:   true,
-				Message:   "DuckDNS record updated successfully",
-				RecordID:  req.Domain, // DuckDNS doesn't have record IDs, use domain
-				UpdatedAt: time.Now(),
-			}, nil
-		} else if responseText == "KO" {
-			return nil, fmt.Errorf("DuckDNS update failed: invalid token or domain")
-		} else {
-			return nil, fmt.Errorf("unexpected DuckDNS response: %s", responseText)
+		if result.verbose && !result.changed {
+			// NOCHANGE means the record already held the address, so that is also the previous one
+			response.Message = "DuckDNS record already up to date"
+			response.Unchanged = true
+			response.PreviousIP = result.ipv4
+			if req.RecordType == "AAAA" {
+				response.PreviousIP = result.ipv6
+			}
 		}
+		return response, nil
 	}
 
 	return executor.ExecuteSimple(d.executor, ctx, task)
 }
 
-// GetCurrentRecord retrieves the current DNS record value
-// Note: DuckDNS doesn't provide an API to get current records, so we'll return an error
-// This forces the service to always attempt an update
-func (d *DuckDNSProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error) {
-	// DuckDNS doesn't provide a way to query current records
-	// Return an error to force updates
-	return "", fmt.Errorf("DuckDNS does not support querying current records")
+// ClearRecord removes the address of a DuckDNS domain, e.g. the AAAA record of a host going IPv4-only
+// DuckDNS's clear=true empties both addresses of the domain, so an A record still in use has to be republished
+func (d *DuckDNSProvider) ClearRecord(ctx context.Context, domain, recordType string) (err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "ClearRecord")
+
+	params := url.Values{}
+	params.Set("domains", domain)
+	params.Set("clear", "true")
+	switch recordType {
+	case "A":
+		params.Set("ip", "")
+	case "AAAA":
+		params.Set("ipv6", "")
+	default:
+		return fmt.Errorf("DuckDNS does not support %s records, only A and AAAA", recordType)
+	}
+
+	task := func(taskCtx context.Context) (duckDNSResult, error) {
+		return d.update(taskCtx, params)
+	}
+
+	_, err = executor.ExecuteSimple(d.executor, ctx, task)
+	return err
+}
+
+// update sends an authenticated request to the DuckDNS update endpoint and parses the answer
+func (d *DuckDNSProvider) update(ctx context.Context, params url.Values) (duckDNSResult, error) {
+	query := url.Values{"token": {d.token}}
+	for name, values := range params {
+		query[name] = values
+	}
+	updateURL := fmt.Sprintf("%s/update?%s", d.baseURL, query.Encode())
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", updateURL, nil)
+	if err != nil {
+		return duckDNSResult{}, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("User-Agent", ddns.DefaultUserAgent)
+
+	resp, err := d.httpClient.Do(httpReq)
+	if err != nil {
+		return duckDNSResult{}, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return duckDNSResult{}, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return duckDNSResult{}, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return parseDuckDNSResponse(string(body))
+}
+
+// GetCurrentRecord retrieves the current address of a DuckDNS domain through DNS
+// DuckDNS has no API to read records, but its hostnames are always publicly resolvable
+func (d *DuckDNSProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "GetCurrentRecord")
+
+	if recordType != "A" && recordType != "AAAA" {
+		return "", fmt.Errorf("DuckDNS does not support %s records, only A and AAAA", recordType)
+	}
+
+	return ResolverBackedGetCurrentRecord(ctx, duckDNSHostname(domain), recordType, d.nameserver)
 }
 
 // ValidateCredentials checks if the DuckDNS credentials are valid
-func (d *DuckDNSProvider) ValidateCredentials(ctx context.Context) error {
+func (d *DuckDNSProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "ValidateCredentials")
+
 	task := func(taskCtx context.Context) (interface{}, error) {
 		// Use a test domain to validate credentials
 		// We'll make a request without actually updating anything
-		baseURL := "https://www.duckdns.org/update"
 		params := url.Values{}
 		params.Set("domains", "test") // Use a test domain that likely doesn't exist
 		params.Set("token", d.token)
 		params.Set("verbose", "true")
 
-		validateURL := fmt.Sprintf("%s?%s", baseURL, params.Encode())
+		validateURL := fmt.Sprintf("%s/update?%s", d.baseURL, params.Encode())
 
 		req, err := http.NewRequestWithContext(taskCtx, "GET", validateURL, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		req.Header.Set("User-Agent", "ddns-client/1.0")
+		req.Header.Set("User-Agent", ddns.DefaultUserAgent)
 
 		resp, err := d.httpClient.Do(req)
 		if err != nil {
@@ -128,16 +207,20 @@ func (d *DuckDNSProvider) ValidateCredentials(ctx context.Context) error {
 		}
 		defer resp.Body.Close()
 
+		if err := rateLimitError(resp); err != nil {
+			return nil, err
+		}
+
 		// If we get a valid HTTP response, the service is reachable
 		// DuckDNS will return "KO" for invalid token, but at least we know the service works
 		if resp.StatusCode == http.StatusOK {
 			return nil, nil // Service is reachable, token format is acceptable
 		}
 
-		return nil, fmt.Errorf("DuckDNS service returned status: %s", resp.Status)
+		return nil, httpStatusError(resp, fmt.Errorf("DuckDNS service returned status: %s", resp.Status))
 	}
 
-	_, err := executor.ExecuteSimple(d.executor, ctx, task)
+	_, err = executor.ExecuteSimple(d.executor, ctx, task)
 	return err
 }
 
@@ -145,3 +228,104 @@ func (d *DuckDNSProvider) ValidateCredentials(ctx context.Context) error {
 func (d *DuckDNSProvider) GetProviderName() string {
 	return "duckdns"
 }
+
+// Capabilities reports the record types DuckDNS supports
+func (d *DuckDNSProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("duckdns")
+}
+
+// duckDNSHostname returns the full hostname of a DuckDNS domain, which may be given without the duckdns.org suffix
+func duckDNSHostname(domain string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	if strings.HasSuffix(strings.ToLower(domain), ".duckdns.org") {
+		return domain
+	}
+	return domain + ".duckdns.org"
+}
+
+// duckDNSResult is a parsed DuckDNS update response
+type duckDNSResult struct {
+	verbose    bool // Whether the response carried the verbose lines below
+	ipv4, ipv6 string
+	changed    bool
+}
+
+// parseDuckDNSResponse parses an update response: "OK" or "KO", followed with verbose=true by
+// the current IPv4 and IPv6 addresses and UPDATED or NOCHANGE, one per line
+func parseDuckDNSResponse(body string) (duckDNSResult, error) {
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	switch lines[0] {
+	case "OK":
+	case "KO":
+		return duckDNSResult{}, ErrDuckDNSRejected
+	default:
+		return duckDNSResult{}, fmt.Errorf("unexpected DuckDNS response: %s", strings.TrimSpace(body))
+	}
+
+	if len(lines) == 1 {
+		return duckDNSResult{}, nil
+	}
+	if len(lines) != 4 || (lines[3] != "UPDATED" && lines[3] != "NOCHANGE") {
+		return duckDNSResult{}, fmt.Errorf("unexpected DuckDNS verbose response: %q", strings.TrimSpace(body))
+	}
+
+	return duckDNSResult{verbose: true, ipv4: lines[1], ipv6: lines[2], changed: lines[3] == "UPDATED"}, nil
+}
+
+// setDuckDNSAddresses sets the ip and ipv6 parameters for the request's record type
+// AAAA records go in ipv6; an A request may also carry an IPv6 address to update both at once
+func setDuckDNSAddresses(params url.Values, req ddns.UpdateRequest) {
+	if req.RecordType == "AAAA" {
+		params.Set("ipv6", req.Value)
+		return
+	}
+
+	params.Set("ip", req.Value)
+	if req.IPv6 != "" {
+		params.Set("ipv6", req.IPv6)
+	}
+}
+
+// validateDuckDNSToken checks the token has the UUID form DuckDNS issues
+func validateDuckDNSToken(token string) error {
+	groups := strings.Split(token, "-")
+	lengths := []int{8, 4, 4, 4, 12}
+	valid := len(groups) == len(lengths)
+	for i := 0; valid && i < len(groups); i++ {
+		valid = len(groups[i]) == lengths[i] && strings.Trim(strings.ToLower(groups[i]), "0123456789abcdef") == ""
+	}
+
+	if !valid {
+		// The token is a secret, so only its shape is described
+		return fmt.Errorf("duckdns provider requires a token in UUID form (8-4-4-4-12 hex digits), got %d characters", len(token))
+	}
+	return nil
+}
+
+// registerDuckDNS adds the DuckDNS provider to the factory
+func registerDuckDNS(f *Factory) {
+	f.Register("duckdns", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("duckdns provider requires API key (token)")
+		}
+		if err := validateDuckDNSToken(config.APIKey); err != nil {
+			return nil, err
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDuckDNSProvider(DuckDNSConfig{
+			Token:      config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+			Nameserver: config.Nameserver,
+		}), nil
+	})
+}
@@ -0,0 +1,301 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestDuckDNSUpdateRecordAddressParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      ddns.UpdateRequest
+		wantIP   string
+		wantIPv6 string
+	}{
+		{
+			name:   "A record sets ip",
+			req:    ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"},
+			wantIP: "203.0.113.10",
+		},
+		{
+			name:     "AAAA record sets ipv6",
+			req:      ddns.UpdateRequest{Domain: "home", RecordType: "AAAA", Value: "2001:db8::10"},
+			wantIPv6: "2001:db8::10",
+		},
+		{
+			name:     "dual-stack sets both",
+			req:      ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10", IPv6: "2001:db8::10"},
+			wantIP:   "203.0.113.10",
+			wantIPv6: "2001:db8::10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var query url.Values
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query = r.URL.Query()
+				fmt.Fprint(w, "OK")
+			}))
+			defer server.Close()
+
+			provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: server.URL})
+
+			if _, err := provider.UpdateRecord(context.Background(), tt.req); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if _, ok := query["ip"]; ok != (tt.wantIP != "") || query.Get("ip") != tt.wantIP {
+				t.Errorf("Expected ip=%q, got %v", tt.wantIP, query["ip"])
+			}
+			if _, ok := query["ipv6"]; ok != (tt.wantIPv6 != "") || query.Get("ipv6") != tt.wantIPv6 {
+				t.Errorf("Expected ipv6=%q, got %v", tt.wantIPv6, query["ipv6"])
+			}
+		})
+	}
+}
+
+// newDuckDNSTestServer serves the given status and body and counts requests
+func newDuckDNSTestServer(t *testing.T, status int, body string, requests *int32) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(requests, 1)
+		if r.URL.Path != "/update" {
+			t.Errorf("Expected /update path, got %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("token") != "test-token" {
+			t.Errorf("Expected token to be sent, got %q", r.URL.Query().Get("token"))
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDuckDNSUpdateRecordOK(t *testing.T) {
+	var requests int32
+	server := newDuckDNSTestServer(t, http.StatusOK, "OK\n", &requests)
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: server.URL})
+
+	resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Success || resp.RecordID != "home" {
+		t.Errorf("Expected successful response for home, got %+v", resp)
+	}
+}
+
+func TestDuckDNSUpdateRecordVerbose(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		recordType    string
+		wantUnchanged bool
+		wantPrevious  string
+		wantErr       bool
+	}{
+		{name: "updated", body: "OK\n203.0.113.10\n\nUPDATED", recordType: "A"},
+		{name: "unchanged A", body: "OK\n203.0.113.10\n2001:db8::10\nNOCHANGE\n", recordType: "A", wantUnchanged: true, wantPrevious: "203.0.113.10"},
+		{name: "unchanged AAAA", body: "OK\n203.0.113.10\n2001:db8::10\nNOCHANGE", recordType: "AAAA", wantUnchanged: true, wantPrevious: "2001:db8::10"},
+		{name: "truncated", body: "OK\n203.0.113.10", recordType: "A", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := newDuckDNSTestServer(t, http.StatusOK, tt.body, &requests)
+			provider := NewDuckDNSProvider(DuckDNSConfig{
+				Token:    "test-token",
+				BaseURL:  server.URL,
+				Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())),
+			})
+
+			value := "203.0.113.10"
+			if tt.recordType == "AAAA" {
+				value = "2001:db8::10"
+			}
+			resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: tt.recordType, Value: value})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error for malformed verbose response")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if resp.Unchanged != tt.wantUnchanged || resp.PreviousIP != tt.wantPrevious {
+				t.Errorf("Expected unchanged=%v previous=%q, got %+v", tt.wantUnchanged, tt.wantPrevious, resp)
+			}
+		})
+	}
+}
+
+func TestDuckDNSUpdateRecordRequestsVerbose(t *testing.T) {
+	var verbose string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verbose = r.URL.Query().Get("verbose")
+		fmt.Fprint(w, "OK\n203.0.113.10\n\nUPDATED")
+	}))
+	defer server.Close()
+
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: server.URL})
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if verbose != "true" {
+		t.Errorf("Expected verbose=true, got %q", verbose)
+	}
+}
+
+func TestValidateDuckDNSTokenHidesToken(t *testing.T) {
+	const token = "secret-token-value"
+
+	err := validateDuckDNSToken(token)
+	if err == nil {
+		t.Fatal("Expected an error for a malformed token")
+	}
+	if strings.Contains(err.Error(), token) {
+		t.Errorf("Expected the error not to contain the token, got %v", err)
+	}
+}
+
+func TestDuckDNSUpdateRecordKOIsNotRetried(t *testing.T) {
+	var requests int32
+	server := newDuckDNSTestServer(t, http.StatusOK, "KO", &requests)
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: server.URL})
+
+	_, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"})
+	if !errors.Is(err, ErrDuckDNSRejected) {
+		t.Fatalf("Expected ErrDuckDNSRejected, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected KO to fail without retrying, got %d requests", got)
+	}
+}
+
+func TestDuckDNSUpdateRecordKOIsNotRetriedBySharedExecutor(t *testing.T) {
+	var requests int32
+	server := newDuckDNSTestServer(t, http.StatusOK, "KO", &requests)
+	provider := NewDuckDNSProvider(DuckDNSConfig{
+		Token:    "test-token",
+		BaseURL:  server.URL,
+		Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewFixedDelayStrategy(3, time.Millisecond))),
+	})
+
+	_, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"})
+	if !errors.Is(err, ErrDuckDNSRejected) {
+		t.Fatalf("Expected ErrDuckDNSRejected, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected KO to fail without retrying, got %d requests", got)
+	}
+}
+
+func TestDuckDNSUpdateRecordUnexpectedResponse(t *testing.T) {
+	var requests int32
+	server := newDuckDNSTestServer(t, http.StatusOK, "<html>maintenance</html>", &requests)
+	provider := NewDuckDNSProvider(DuckDNSConfig{
+		Token:    "test-token",
+		BaseURL:  server.URL,
+		Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewFixedDelayStrategy(2, time.Millisecond))),
+	})
+
+	_, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "A", Value: "203.0.113.10"})
+	if err == nil || errors.Is(err, ErrDuckDNSRejected) {
+		t.Fatalf("Expected unexpected response error, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("Expected unexpected responses to be retried, got %d requests", got)
+	}
+}
+
+func TestDuckDNSValidateCredentialsNon200(t *testing.T) {
+	var requests int32
+	server := newDuckDNSTestServer(t, http.StatusServiceUnavailable, "", &requests)
+	provider := NewDuckDNSProvider(DuckDNSConfig{
+		Token:    "test-token",
+		BaseURL:  server.URL,
+		Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())),
+	})
+
+	if err := provider.ValidateCredentials(context.Background()); err == nil {
+		t.Fatal("Expected error for non-200 status")
+	}
+}
+
+func TestDuckDNSUpdateRecordRejectsNonAddressTypes(t *testing.T) {
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: "http://127.0.0.1:0"})
+
+	_, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home", RecordType: "CNAME", Value: "target.example.net"})
+	if err == nil || !strings.Contains(err.Error(), "does not support CNAME") {
+		t.Errorf("Expected unsupported record type error, got %v", err)
+	}
+}
+
+func TestDuckDNSGetCurrentRecordResolvesHostname(t *testing.T) {
+	nameserver := startMockDNSServer(t, map[string][]string{
+		"home.duckdns.org.": {"203.0.113.10", "2001:db8::10"},
+	})
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", Nameserver: nameserver})
+
+	tests := []struct {
+		domain, recordType, want string
+	}{
+		{"home", "A", "203.0.113.10"},
+		{"home.duckdns.org", "AAAA", "2001:db8::10"},
+	}
+	for _, tt := range tests {
+		value, err := provider.GetCurrentRecord(context.Background(), tt.domain, tt.recordType)
+		if err != nil {
+			t.Fatalf("Expected %s %s record, got %v", tt.domain, tt.recordType, err)
+		}
+		if value != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, value)
+		}
+	}
+
+	if _, err := provider.GetCurrentRecord(context.Background(), "home", "TXT"); err == nil {
+		t.Error("Expected error for TXT records")
+	}
+}
+
+func TestDuckDNSClearRecord(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprint(w, "OK")
+	}))
+	defer server.Close()
+
+	provider := NewDuckDNSProvider(DuckDNSConfig{Token: "test-token", BaseURL: server.URL})
+	var _ ddns.RecordClearer = provider
+
+	for recordType, param := range map[string]string{"A": "ip", "AAAA": "ipv6"} {
+		if err := provider.ClearRecord(context.Background(), "home", recordType); err != nil {
+			t.Fatalf("%s: expected no error, got %v", recordType, err)
+		}
+		if values, ok := query[param]; !ok || values[0] != "" {
+			t.Errorf("%s: expected an empty %s parameter, got %v", recordType, param, query)
+		}
+		if query.Get("clear") != "true" || query.Get("domains") != "home" || query.Get("token") != "test-token" {
+			t.Errorf("%s: expected clear=true for home with the token, got %v", recordType, query)
+		}
+	}
+
+	if err := provider.ClearRecord(context.Background(), "home", "TXT"); err == nil {
+		t.Error("Expected an error clearing a TXT record")
+	}
+}
@@ -0,0 +1,194 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+// DynDNS2Client speaks the DynDNS v2 update protocol (/nic/update) shared by several providers
+type DynDNS2Client struct {
+	name       string // Provider name used in error messages
+	updateURL  string
+	username   string // Sent with HTTP Basic auth when set
+	password   string
+	httpClient *http.Client
+	executor   *executor.Executor
+
+	// passwordInQuery sends the password as a query parameter instead of Basic auth
+	passwordInQuery bool
+
+	// backoff maps return codes to how long the provider wants no further updates after them
+	backoff      map[string]time.Duration
+	mu           sync.Mutex
+	backoffUntil time.Time
+	backoffErr   *DynDNS2Error // Response that started the current backoff
+}
+
+// DynDNS2Result is the parsed outcome of a successful update
+type DynDNS2Result struct {
+	Changed bool   // False when the provider answered nochg
+	IP      string // Address the provider now has on record
+}
+
+// DynDNS2Error is returned when the provider rejects an update with a protocol return code
+type DynDNS2Error struct {
+	Provider string
+	Code     string
+
+	backoff bool // Set when the client already enforces the provider's requested wait
+}
+
+// Error describes the return code in plain words
+func (e *DynDNS2Error) Error() string {
+	reasons := map[string]string{
+		"badauth":  "invalid credentials",
+		"abuse":    "hostname blocked for abuse",
+		"nohost":   "hostname does not exist in this account",
+		"notfqdn":  "hostname is not a fully qualified domain name",
+		"badagent": "user agent rejected",
+		"!donator": "feature not available for this account",
+		"dnserr":   "provider DNS error",
+		"911":      "provider is having problems",
+	}
+
+	if reason, ok := reasons[e.Code]; ok {
+		return fmt.Sprintf("%s update failed: %s (%s)", e.Provider, reason, e.Code)
+	}
+	return fmt.Sprintf("%s update failed: unexpected response %q", e.Provider, e.Code)
+}
+
+// Retryable reports whether the code indicates a temporary server-side problem
+// Repeating a rejected update (e.g. badauth) can get the client blocked, so only these are retried
+// Codes the client backs off after are not retried either, since the wait outlasts any single update
+func (e *DynDNS2Error) Retryable() bool {
+	if e.backoff {
+		return false
+	}
+	return e.Code == "dnserr" || e.Code == "911"
+}
+
+// IsRetryable implements executor.RetryableError, so shared executors don't repeat rejected updates either
+func (e *DynDNS2Error) IsRetryable() bool {
+	return e.Retryable()
+}
+
+// Unwrap returns the ddns sentinel matching the return code, if any
+func (e *DynDNS2Error) Unwrap() error {
+	switch e.Code {
+	case "badauth":
+		return ddns.ErrAuthFailed
+	case "nohost":
+		return ddns.ErrNotFound
+	}
+	return nil
+}
+
+// isRetryableDynDNS2Error reports whether err is worth retrying under the DynDNS v2 protocol
+func isRetryableDynDNS2Error(err error) bool {
+	var protocolErr *DynDNS2Error
+	if errors.As(err, &protocolErr) {
+		return protocolErr.Retryable()
+	}
+	return true
+}
+
+// Update sets hostname to ip, letting the provider use the request's source address when ip is empty
+// A return code listed in backoff fails at once with an executor.RetryAfterError, and later calls
+// fail the same way without a request until the delay has passed
+func (c *DynDNS2Client) Update(ctx context.Context, hostname, ip string) (*DynDNS2Result, error) {
+	c.mu.Lock()
+	until, cause := c.backoffUntil, c.backoffErr
+	c.mu.Unlock()
+	if wait := time.Until(until); wait > 0 {
+		return nil, &executor.RetryAfterError{Err: fmt.Errorf("%s asked for no updates until %s: %w", c.name, until.Format(time.RFC3339), cause), RetryAfter: wait}
+	}
+
+	task := func(taskCtx context.Context) (*DynDNS2Result, error) {
+		params := url.Values{}
+		params.Set("hostname", hostname)
+		if ip != "" {
+			params.Set("myip", ip)
+		}
+		if c.passwordInQuery {
+			params.Set("password", c.password)
+		}
+
+		req, err := http.NewRequestWithContext(taskCtx, "GET", c.updateURL+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+
+		if !c.passwordInQuery {
+			req.SetBasicAuth(c.username, c.password)
+		}
+		req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if err := rateLimitError(resp); err != nil {
+			return nil, err
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK && len(strings.TrimSpace(string(body))) == 0 {
+			return nil, httpStatusError(resp, nil)
+		}
+
+		result, err := parseDynDNS2Response(c.name, string(body))
+		var protocolErr *DynDNS2Error
+		if errors.As(err, &protocolErr) {
+			if delay, ok := c.backoff[protocolErr.Code]; ok {
+				protocolErr.backoff = true
+				c.mu.Lock()
+				c.backoffUntil = time.Now().Add(delay)
+				c.backoffErr = protocolErr
+				c.mu.Unlock()
+				return nil, &executor.RetryAfterError{Err: err, RetryAfter: delay}
+			}
+		}
+		return result, err
+	}
+
+	return executor.ExecuteSimple(c.executor, ctx, task)
+}
+
+// parseDynDNS2Response parses a "good <ip>" / "nochg <ip>" reply or returns the error code
+func parseDynDNS2Response(provider, body string) (*DynDNS2Result, error) {
+	fields := strings.Fields(body)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s returned an empty response", provider)
+	}
+
+	result := &DynDNS2Result{}
+	if len(fields) > 1 {
+		result.IP = fields[1]
+	}
+
+	switch fields[0] {
+	case "good":
+		result.Changed = true
+		return result, nil
+	case "nochg":
+		return result, nil
+	default:
+		return nil, &DynDNS2Error{Provider: provider, Code: fields[0]}
+	}
+}
@@ -0,0 +1,301 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const dynuBaseURL = "https://api.dynu.com/v2"
+
+// DynuProvider implements the DDNS Provider interface for Dynu
+type DynuProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+
+	// Domain IDs are cached since they don't change between updates
+	mu        sync.Mutex
+	domainIDs map[string]int
+}
+
+// DynuConfig holds Dynu-specific configuration
+type DynuConfig struct {
+	APIKey     string
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// dynuDomain represents a DDNS domain in the Dynu API
+type dynuDomain struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name"`
+	IPv4Address string `json:"ipv4Address,omitempty"`
+	IPv6Address string `json:"ipv6Address,omitempty"`
+	TTL         int    `json:"ttl,omitempty"`
+}
+
+// dynuErrorResponse represents Dynu's error envelope
+type dynuErrorResponse struct {
+	Type   string `json:"type"`
+	Status int    `json:"status"`
+	Err    string `json:"error"`
+}
+
+// Error formats the error type and message into a single message
+func (e *dynuErrorResponse) Error() string {
+	return fmt.Sprintf("Dynu API error %d (%s): %s", e.Status, e.Type, e.Err)
+}
+
+// NewDynuProvider creates a new Dynu DDNS provider
+func NewDynuProvider(config DynuConfig) *DynuProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &DynuProvider{
+		apiKey:     config.APIKey,
+		baseURL:    dynuBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+		executor:   exec,
+		domainIDs:  make(map[string]int),
+	}
+}
+
+// UpdateRecord updates the address of a Dynu domain
+func (d *DynuProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "UpdateRecord")
+
+	if req.RecordType != "A" && req.RecordType != "AAAA" {
+		return nil, fmt.Errorf("Dynu does not support %s records", req.RecordType)
+	}
+
+	domainID, err := d.resolveDomainID(ctx, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		// Dynu replaces the whole domain on POST, so the current object is sent back with only the
+		// address (and a configured TTL) changed; fields this client doesn't know about are kept as is
+		var domain map[string]json.RawMessage
+		path := fmt.Sprintf("/dns/%d", domainID)
+		if err := d.do(taskCtx, "GET", path, nil, &domain); err != nil {
+			return nil, err
+		}
+		if domain == nil {
+			domain = map[string]json.RawMessage{}
+		}
+
+		field := "ipv4Address"
+		if req.RecordType == "AAAA" {
+			field = "ipv6Address"
+		}
+		value, _ := json.Marshal(req.Value)
+		domain[field] = value
+		if req.TTL > 0 {
+			domain["ttl"], _ = json.Marshal(req.TTL)
+		}
+
+		if err := d.do(taskCtx, "POST", path, domain, nil); err != nil {
+			return nil, err
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "Dynu domain updated successfully",
+			RecordID:  fmt.Sprintf("%d", domainID),
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(d.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current address of a Dynu domain
+func (d *DynuProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "GetCurrentRecord")
+
+	domainID, err := d.resolveDomainID(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	task := func(taskCtx context.Context) (*dynuDomain, error) {
+		var result dynuDomain
+		if err := d.do(taskCtx, "GET", fmt.Sprintf("/dns/%d", domainID), nil, &result); err != nil {
+			return nil, err
+		}
+		return &result, nil
+	}
+
+	result, err := executor.ExecuteSimple(d.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	value := result.IPv4Address
+	if recordType == "AAAA" {
+		value = result.IPv6Address
+	}
+	if value == "" {
+		return "", fmt.Errorf("Dynu %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return value, nil
+}
+
+// ValidateCredentials checks if the Dynu API key is valid
+func (d *DynuProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, d.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		_, err := d.listDomains(taskCtx)
+		return nil, err
+	}
+
+	_, err = executor.ExecuteSimple(d.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (d *DynuProvider) GetProviderName() string {
+	return "dynu"
+}
+
+// Capabilities reports the record types Dynu supports
+func (d *DynuProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("dynu")
+}
+
+// resolveDomainID returns the ID of the Dynu domain named fqdn, looking it up on first use
+func (d *DynuProvider) resolveDomainID(ctx context.Context, fqdn string) (int, error) {
+	name := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+
+	d.mu.Lock()
+	id, ok := d.domainIDs[name]
+	d.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	task := func(taskCtx context.Context) (int, error) {
+		domains, err := d.listDomains(taskCtx)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, domain := range domains {
+			if strings.EqualFold(domain.Name, name) {
+				return domain.ID, nil
+			}
+		}
+		return 0, fmt.Errorf("no Dynu domain found for %s", fqdn)
+	}
+
+	id, err := executor.ExecuteSimple(d.executor, ctx, task)
+	if err != nil {
+		return 0, err
+	}
+
+	d.mu.Lock()
+	d.domainIDs[name] = id
+	d.mu.Unlock()
+
+	return id, nil
+}
+
+// listDomains returns every DDNS domain on the account
+func (d *DynuProvider) listDomains(ctx context.Context) ([]dynuDomain, error) {
+	var list struct {
+		Domains []dynuDomain `json:"domains"`
+	}
+	if err := d.do(ctx, "GET", "/dns", nil, &list); err != nil {
+		return nil, err
+	}
+	return list.Domains, nil
+}
+
+// do performs an authenticated Dynu API request, decoding the response into out if non-nil
+func (d *DynuProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, d.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("API-Key", d.apiKey)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr dynuErrorResponse
+		if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Err != "" {
+			return httpStatusError(resp, &apiErr)
+		}
+		return httpStatusError(resp, nil)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// registerDynu adds the Dynu provider to the factory
+func registerDynu(f *Factory) {
+	f.Register("dynu", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("dynu provider requires API key")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewDynuProvider(DynuConfig{
+			APIKey:     config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}), nil
+	})
+}
@@ -0,0 +1,201 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestDynuUpdateRecordCachesDomainID(t *testing.T) {
+	lookups := 0
+	var updates []dynuDomain
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("API-Key"); got != "secret" {
+			t.Errorf("Expected API-Key secret, got %q", got)
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/dns":
+			lookups++
+			fmt.Fprint(w, `{"statusCode":200,"domains":[{"id":11,"name":"other.dynu.net"},{"id":12,"name":"home.dynu.net"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/dns/12":
+			fmt.Fprint(w, `{"id":12,"name":"home.dynu.net","ipv4Address":"198.51.100.1","ttl":90}`)
+		case r.Method == "POST" && r.URL.Path == "/dns/12":
+			var domain dynuDomain
+			json.NewDecoder(r.Body).Decode(&domain)
+			updates = append(updates, domain)
+			fmt.Fprint(w, `{"statusCode":200}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "secret"})
+	provider.baseURL = server.URL
+	ctx := context.Background()
+
+	for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+		resp, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "home.dynu.net", RecordType: "A", Value: ip, TTL: 120})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.RecordID != "12" {
+			t.Errorf("Expected record ID 12, got %s", resp.RecordID)
+		}
+	}
+
+	if lookups != 1 {
+		t.Errorf("Expected domain ID to be looked up once, got %d lookups", lookups)
+	}
+	if len(updates) != 2 || updates[1].IPv4Address != "203.0.113.2" || updates[1].TTL != 120 {
+		t.Errorf("Unexpected updates %+v", updates)
+	}
+}
+
+func TestDynuUpdateRecordKeepsOtherFields(t *testing.T) {
+	var update map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/dns":
+			fmt.Fprint(w, `{"statusCode":200,"domains":[{"id":12,"name":"home.dynu.net"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/dns/12":
+			fmt.Fprint(w, `{"id":12,"name":"home.dynu.net","group":"office","ipv4Address":"198.51.100.1","ipv6Address":"2001:db8::1","ttl":90,"ipv4WildcardAlias":true,"ipv6":true}`)
+		case r.Method == "POST" && r.URL.Path == "/dns/12":
+			json.NewDecoder(r.Body).Decode(&update)
+			fmt.Fprint(w, `{"statusCode":200}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "secret"})
+	provider.baseURL = server.URL
+
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.dynu.net", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := map[string]interface{}{
+		"id": 12.0, "name": "home.dynu.net", "group": "office", "ipv4Address": "203.0.113.1", "ipv6Address": "2001:db8::1",
+		"ttl": 90.0, "ipv4WildcardAlias": true, "ipv6": true,
+	}
+	if fmt.Sprint(update) != fmt.Sprint(want) {
+		t.Errorf("Expected only the IPv4 address to change, got %v", update)
+	}
+}
+
+func TestDynuGetCurrentRecord(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns":
+			fmt.Fprint(w, `{"domains":[{"id":12,"name":"home.dynu.net"}]}`)
+		case "/dns/12":
+			fmt.Fprint(w, `{"id":12,"name":"home.dynu.net","ipv4Address":"203.0.113.1","ipv6Address":"2001:db8::1"}`)
+		default:
+			t.Errorf("Unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "secret"})
+	provider.baseURL = server.URL
+
+	value, err := provider.GetCurrentRecord(context.Background(), "home.dynu.net", "AAAA")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "2001:db8::1" {
+		t.Errorf("Expected 2001:db8::1, got %s", value)
+	}
+}
+
+func TestDynuErrorEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type":"Authentication Exception","status":401,"error":"Invalid API key"}`)
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "wrong"})
+	provider.baseURL = server.URL
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	err := provider.ValidateCredentials(context.Background())
+	if err == nil {
+		t.Fatal("Expected error for invalid API key")
+	}
+	if want := "dynu ValidateCredentials: Dynu API error 401 (Authentication Exception): Invalid API key"; err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err.Error())
+	}
+
+	providerErr, ok := ddns.IsProviderError(err)
+	if !ok {
+		t.Fatalf("Expected a ProviderError, got %T", err)
+	}
+	if providerErr.Provider != "dynu" || providerErr.Operation != "ValidateCredentials" || providerErr.StatusCode != http.StatusUnauthorized || providerErr.Retryable {
+		t.Errorf("Unexpected ProviderError %+v", providerErr)
+	}
+	if !errors.Is(err, ddns.ErrAuthFailed) {
+		t.Error("Expected the error to match ErrAuthFailed")
+	}
+}
+
+func TestDynuClientErrorsAreNotRetried(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "secret"})
+	provider.baseURL = server.URL
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewFixedDelayStrategy(5, time.Millisecond)))
+
+	_, err := provider.GetCurrentRecord(context.Background(), "home.dynu.net", "A")
+	if attempts != 2 {
+		t.Errorf("Expected the 503 to be retried and the 404 not to be, got %d attempts", attempts)
+	}
+	if !errors.Is(err, ddns.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if providerErr, ok := ddns.IsProviderError(err); !ok || providerErr.StatusCode != http.StatusNotFound || providerErr.Retryable {
+		t.Errorf("Expected a non-retryable 404 ProviderError, got %v", err)
+	}
+}
+
+func TestDynuRateLimitReturnsRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewDynuProvider(DynuConfig{APIKey: "secret"})
+	provider.baseURL = server.URL
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	err := provider.ValidateCredentials(context.Background())
+
+	var retryAfter *executor.RetryAfterError
+	if !errors.As(err, &retryAfter) {
+		t.Fatalf("Expected RetryAfterError, got %v", err)
+	}
+	if retryAfter.RetryAfter != 7*time.Second {
+		t.Errorf("Expected 7s Retry-After, got %s", retryAfter.RetryAfter)
+	}
+}
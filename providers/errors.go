@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+// statusError is an unsuccessful HTTP response
+// It classifies itself so the executor stops retrying requests the server will keep rejecting
+type statusError struct {
+	StatusCode int
+	Err        error
+}
+
+// Error implements the error interface
+func (e *statusError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *statusError) Unwrap() error {
+	return e.Err
+}
+
+// IsRetryable reports whether repeating the request may succeed
+func (e *statusError) IsRetryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusRequestTimeout || e.StatusCode >= 500
+}
+
+// httpStatusError returns the error for an unsuccessful response, describing it with cause if non-nil
+func httpStatusError(resp *http.Response, cause error) error {
+	if cause == nil {
+		cause = fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
+	}
+	return &statusError{StatusCode: resp.StatusCode, Err: cause}
+}
+
+// classifiedError attaches a ddns sentinel to an error without changing its message
+type classifiedError struct {
+	sentinel error
+	err      error
+}
+
+// Error implements the error interface
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns both the sentinel and the wrapped error
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.sentinel, e.err}
+}
+
+// wrapProviderError replaces the error a provider method is returning with a *ddns.ProviderError
+// Errors that already carry one, e.g. from a wrapped provider, are left as they are
+func wrapProviderError(err *error, provider, operation string) {
+	if *err == nil {
+		return
+	}
+	if _, ok := ddns.IsProviderError(*err); ok {
+		return
+	}
+
+	providerErr := &ddns.ProviderError{Provider: provider, Operation: operation, Retryable: true, Cause: *err}
+
+	// Executor errors join every attempt's error, and the last attempt decides how the call failed
+	last := *err
+	if joined, ok := last.(interface{ Unwrap() []error }); ok {
+		if errs := joined.Unwrap(); len(errs) > 0 {
+			last = errs[len(errs)-1]
+		}
+	}
+
+	var status *statusError
+	if errors.As(last, &status) {
+		providerErr.StatusCode = status.StatusCode
+	}
+	var classified executor.RetryableError
+	if errors.As(last, &classified) {
+		providerErr.Retryable = classified.IsRetryable()
+	}
+
+	switch providerErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		providerErr.Cause = &classifiedError{sentinel: ddns.ErrAuthFailed, err: *err}
+	case http.StatusNotFound:
+		providerErr.Cause = &classifiedError{sentinel: ddns.ErrNotFound, err: *err}
+	case http.StatusTooManyRequests:
+		providerErr.Cause = &classifiedError{sentinel: ddns.ErrRateLimited, err: *err}
+	}
+
+	// A cancelled wait between attempts wraps the attempt errors, so cancellation is checked on the whole error
+	if errors.Is(last, ddns.ErrAuthFailed) || errors.Is(last, ddns.ErrNotFound) || errors.Is(*err, context.Canceled) {
+		providerErr.Retryable = false
+	}
+
+	*err = providerErr
+}
@@ -1,63 +1,172 @@
 package providers
 
 import (
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
 
 	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
 )
 
+// ProviderConstructor builds a provider from configuration, reporting invalid configuration as an error
+// Constructors must not contact the provider, since they also back ValidateProviderConfig
+type ProviderConstructor func(config ddns.Config) (ddns.Provider, error)
+
 // Factory creates DDNS providers based on configuration
-type Factory struct{}
+type Factory struct {
+	httpClient *http.Client
+	executor   *executor.Executor
+
+	constructors map[string]ProviderConstructor
+	names        []string // Registration order, for GetSupportedProviders
+}
+
+// FactoryOption defines a function type for configuring the factory
+type FactoryOption func(*Factory)
+
+// registry holds providers added with Register, in registration order
+var registry struct {
+	mu           sync.Mutex
+	constructors map[string]ProviderConstructor
+	names        []string
+}
+
+// Register adds a provider to every factory created afterwards
+// External packages call it, typically from init, to plug in their own providers
+func Register(name string, constructor ProviderConstructor) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if registry.constructors == nil {
+		registry.constructors = make(map[string]ProviderConstructor)
+	}
+	if _, exists := registry.constructors[name]; !exists {
+		registry.names = append(registry.names, name)
+	}
+	registry.constructors[name] = constructor
+}
 
 // NewFactory creates a new provider factory
-func NewFactory() *Factory {
-	return &Factory{}
+func NewFactory(options ...FactoryOption) *Factory {
+	factory := &Factory{
+		constructors: make(map[string]ProviderConstructor),
+	}
+
+	for _, option := range options {
+		option(factory)
+	}
+
+	// Built-in providers capture the factory, so register them once the options are applied
+	registerDuckDNS(factory)
+	registerNameSilo(factory)
+	registerLinode(factory)
+	registerVultr(factory)
+	registerNameDotCom(factory)
+	registerHurricaneElectric(factory)
+	registerDNSPod(factory)
+	registerAliDNS(factory)
+	registerDynu(factory)
+	registerNoIP(factory)
+	registerFile(factory)
+	registerMock(factory)
+
+	registry.mu.Lock()
+	for _, name := range registry.names {
+		factory.Register(name, registry.constructors[name])
+	}
+	registry.mu.Unlock()
+
+	return factory
+}
+
+// WithHTTPClient sets the HTTP client injected into created providers
+func WithHTTPClient(client *http.Client) FactoryOption {
+	return func(f *Factory) {
+		f.httpClient = client
+	}
+}
+
+// WithExecutor sets the executor injected into created providers
+func WithExecutor(exec *executor.Executor) FactoryOption {
+	return func(f *Factory) {
+		f.executor = exec
+	}
+}
+
+// Register adds or replaces the constructor for the named provider on this factory
+func (f *Factory) Register(name string, constructor ProviderConstructor) {
+	if _, exists := f.constructors[name]; !exists {
+		f.names = append(f.names, name)
+	}
+	f.constructors[name] = constructor
+}
+
+// Unregister removes the named provider from this factory
+func (f *Factory) Unregister(name string) {
+	if _, exists := f.constructors[name]; !exists {
+		return
+	}
+
+	delete(f.constructors, name)
+	for i, registered := range f.names {
+		if registered == name {
+			f.names = append(f.names[:i], f.names[i+1:]...)
+			break
+		}
+	}
 }
 
 // CreateProvider creates a DDNS provider based on the configuration
 func (f *Factory) CreateProvider(config ddns.Config) (ddns.Provider, error) {
-	switch config.Provider {
-	case "duckdns":
-		if config.APIKey == "" {
-			return nil, fmt.Errorf("duckdns provider requires API key (token)")
-		}
+	constructor, ok := f.constructors[config.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unsupported DDNS provider: %s", config.Provider)
+	}
 
-		duckConfig := DuckDNSConfig{
-			Token: config.APIKey,
+	return constructor(config)
+}
+
+// CreateProviders creates a provider for each configuration, reporting all failures together
+func (f *Factory) CreateProviders(configs []ddns.Config) ([]ddns.Provider, error) {
+	providers := make([]ddns.Provider, 0, len(configs))
+	var errs []error
+
+	for _, config := range configs {
+		provider, err := f.CreateProvider(config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", config.Provider, err))
+			continue
 		}
+		providers = append(providers, provider)
+	}
 
-		return NewDuckDNSProvider(duckConfig), nil
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
 
-	case "mock":
-		return NewMockProvider("test"), nil
+	return providers, nil
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported DDNS provider: %s", config.Provider)
+// clientFor returns the HTTP client injected into the provider built from config
+func (f *Factory) clientFor(config ddns.Config) (*http.Client, error) {
+	client, err := ClientWithProxy(f.httpClient, config.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("%s provider: %w", config.Provider, err)
 	}
+	return ClientWithHeaders(client, config.Headers), nil
 }
 
-// GetSupportedProviders returns a list of supported provider names
+// GetSupportedProviders returns a list of supported provider names, in registration order
 func (f *Factory) GetSupportedProviders() []string {
-	return []string{
-		"duckdns",
-		"mock",
-	}
+	names := make([]string, len(f.names))
+	copy(names, f.names)
+	return names
 }
 
 // ValidateProviderConfig validates the configuration for a specific provider
 func (f *Factory) ValidateProviderConfig(config ddns.Config) error {
-	switch config.Provider {
-	case "duckdns":
-		if config.APIKey == "" {
-			return fmt.Errorf("duckdns provider requires API key (token)")
-		}
-		return nil
-
-	case "mock":
-		// Mock provider doesn't require any specific configuration
-		return nil
-
-	default:
-		return fmt.Errorf("unsupported DDNS provider: %s", config.Provider)
-	}
+	_, err := f.CreateProvider(config)
+	return err
 }
@@ -0,0 +1,279 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// Markers delimiting the section of the file owned by FileProvider
+const (
+	fileBlockBegin = "# BEGIN DDNS MANAGED BLOCK"
+	fileBlockEnd   = "# END DDNS MANAGED BLOCK"
+)
+
+// FileProvider implements the DDNS Provider interface by writing records to a hosts-style file
+type FileProvider struct {
+	path string
+	mu   sync.Mutex
+
+	// Overridable for tests
+	rename func(oldpath, newpath string) error
+}
+
+// FileConfig holds file provider configuration
+type FileConfig struct {
+	Path string
+}
+
+// fileEntry is a single record line inside the managed block
+type fileEntry struct {
+	value      string
+	domain     string
+	recordType string
+}
+
+// NewFileProvider creates a provider that manages a block of entries in a local file
+func NewFileProvider(config FileConfig) *FileProvider {
+	return &FileProvider{
+		path:   config.Path,
+		rename: os.Rename,
+	}
+}
+
+// UpdateRecord writes the record into the managed block, replacing any existing entry
+func (f *FileProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, f.GetProviderName(), "UpdateRecord")
+
+	if err := validateFileRecord(req.RecordType, req.Value); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	before, entries, after, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := fileEntry{value: req.Value, domain: req.Domain, recordType: req.RecordType}
+	replaced := false
+	for i, entry := range entries {
+		if strings.EqualFold(entry.domain, req.Domain) && entry.recordType == req.RecordType {
+			entries[i] = updated
+			replaced = true
+		}
+	}
+	if !replaced {
+		entries = append(entries, updated)
+	}
+
+	if err := f.write(before, entries, after); err != nil {
+		return nil, err
+	}
+
+	return &ddns.UpdateResponse{
+		Success:   true,
+		Message:   fmt.Sprintf("Record written to %s", f.path),
+		RecordID:  fmt.Sprintf("%s:%s", req.Domain, req.RecordType),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// GetCurrentRecord parses the managed block for an existing entry
+func (f *FileProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, f.GetProviderName(), "GetCurrentRecord")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, entries, _, err := f.read()
+	if err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if strings.EqualFold(entry.domain, domain) && entry.recordType == recordType {
+			return entry.value, nil
+		}
+	}
+
+	return "", ddns.ErrNotFound
+}
+
+// ValidateCredentials checks that the file can be opened for writing
+func (f *FileProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, f.GetProviderName(), "ValidateCredentials")
+
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("file %s is not writable: %w", f.path, err)
+	}
+	return file.Close()
+}
+
+// GetProviderName returns the name of the provider
+func (f *FileProvider) GetProviderName() string {
+	return "file"
+}
+
+// Capabilities reports the record types the file provider supports
+func (f *FileProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("file")
+}
+
+// read splits the file into the content before, inside and after the managed block
+func (f *FileProvider) read() ([]string, []fileEntry, []string, error) {
+	data, err := os.ReadFile(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+
+	var before, after []string
+	var entries []fileEntry
+	inBlock, seenBlock := false, false
+
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return nil, nil, nil, nil
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.TrimSpace(line) == fileBlockBegin && !seenBlock:
+			inBlock, seenBlock = true, true
+		case strings.TrimSpace(line) == fileBlockEnd && inBlock:
+			inBlock = false
+		case inBlock:
+			if entry, ok := parseFileEntry(line); ok {
+				entries = append(entries, entry)
+			}
+		case seenBlock:
+			after = append(after, line)
+		default:
+			before = append(before, line)
+		}
+	}
+
+	if inBlock {
+		return nil, nil, nil, fmt.Errorf("unterminated managed block in %s", f.path)
+	}
+
+	return before, entries, after, nil
+}
+
+// write replaces the managed block while preserving the surrounding content
+// Symlinks are followed so the link itself is kept. The content is written to a temporary file
+// renamed over the target, so readers never see a partial file; targets that can't be replaced,
+// such as Docker's bind-mounted /etc/hosts, are rewritten in place instead
+func (f *FileProvider) write(before []string, entries []fileEntry, after []string) error {
+	lines := append([]string{}, before...)
+	lines = append(lines, fileBlockBegin)
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s\t%s\t# %s", entry.value, entry.domain, entry.recordType))
+	}
+	lines = append(lines, fileBlockEnd)
+	lines = append(lines, after...)
+	content := []byte(strings.Join(lines, "\n") + "\n")
+
+	path := f.path
+	if target, err := filepath.EvalSymlinks(f.path); err == nil {
+		path = target
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	if err := f.replace(path, content, mode); err != nil {
+		// Truncating keeps the file's inode, mode and owner, at the cost of a brief partial read
+		if err := os.WriteFile(path, content, mode); err != nil {
+			return fmt.Errorf("failed to write %s: %w", f.path, err)
+		}
+	}
+
+	return nil
+}
+
+// replace writes content to a temporary file next to path and renames it over path
+func (f *FileProvider) replace(path string, content []byte, mode os.FileMode) error {
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name()) // Fails harmlessly once the rename succeeded
+
+	_, err = temp.Write(content)
+	if err == nil {
+		err = temp.Chmod(mode)
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = f.rename(temp.Name(), path)
+	}
+	return err
+}
+
+// parseFileEntry parses a "value<TAB>domain<TAB># TYPE" line
+func parseFileEntry(line string) (fileEntry, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+		return fileEntry{}, false
+	}
+
+	entry := fileEntry{value: fields[0], domain: fields[1]}
+	if len(fields) >= 4 && fields[2] == "#" {
+		entry.recordType = fields[3]
+	} else if ip := net.ParseIP(entry.value); ip != nil && ip.To4() == nil {
+		entry.recordType = "AAAA"
+	} else {
+		entry.recordType = "A"
+	}
+
+	return entry, true
+}
+
+// validateFileRecord ensures the record can be represented in a hosts-style file
+func validateFileRecord(recordType, value string) error {
+	ip := net.ParseIP(value)
+
+	switch recordType {
+	case "A":
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("file provider requires an IPv4 address for A records, got %q", value)
+		}
+	case "AAAA":
+		if ip == nil || ip.To4() != nil {
+			return fmt.Errorf("file provider requires an IPv6 address for AAAA records, got %q", value)
+		}
+	default:
+		return fmt.Errorf("file provider does not support %s records", recordType)
+	}
+
+	return nil
+}
+
+// registerFile adds the hosts-file provider to the factory
+func registerFile(f *Factory) {
+	f.Register("file", func(config ddns.Config) (ddns.Provider, error) {
+		if config.FilePath == "" {
+			return nil, fmt.Errorf("file provider requires a file path")
+		}
+
+		return NewFileProvider(FileConfig{Path: config.FilePath}), nil
+	})
+}
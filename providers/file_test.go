@@ -0,0 +1,182 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+func TestFileProviderUpdateAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	original := "127.0.0.1\tlocalhost\n::1\tlocalhost\n"
+	if err := os.WriteFile(path, []byte(original), 0644); err != nil {
+		t.Fatalf("failed to write hosts file: %v", err)
+	}
+
+	provider := NewFileProvider(FileConfig{Path: path})
+	ctx := context.Background()
+
+	if _, err := provider.GetCurrentRecord(ctx, "home.example.com", "A"); err == nil {
+		t.Error("Expected error for missing record")
+	}
+
+	for _, ip := range []string{"203.0.113.1", "203.0.113.2"} {
+		req := ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: ip}
+		if _, err := provider.UpdateRecord(ctx, req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	value, err := provider.GetCurrentRecord(ctx, "home.example.com", "A")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "203.0.113.2" {
+		t.Errorf("Expected 203.0.113.2, got %s", value)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read hosts file: %v", err)
+	}
+
+	content := string(data)
+	if !strings.HasPrefix(content, original) {
+		t.Errorf("Expected existing entries to be preserved, got:\n%s", content)
+	}
+	if strings.Count(content, "home.example.com") != 1 {
+		t.Errorf("Expected a single entry for the domain, got:\n%s", content)
+	}
+}
+
+func TestFileProviderKeepsRecordTypesSeparate(t *testing.T) {
+	provider := NewFileProvider(FileConfig{Path: filepath.Join(t.TempDir(), "hosts")})
+	ctx := context.Background()
+
+	requests := []ddns.UpdateRequest{
+		{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1"},
+		{Domain: "home.example.com", RecordType: "AAAA", Value: "2001:db8::1"},
+	}
+	for _, req := range requests {
+		if _, err := provider.UpdateRecord(ctx, req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	for _, req := range requests {
+		value, err := provider.GetCurrentRecord(ctx, req.Domain, req.RecordType)
+		if err != nil || value != req.Value {
+			t.Errorf("GetCurrentRecord(%s) = %q, %v, want %q", req.RecordType, value, err, req.Value)
+		}
+	}
+}
+
+func TestFileProviderRejectsUnsupportedRecords(t *testing.T) {
+	provider := NewFileProvider(FileConfig{Path: filepath.Join(t.TempDir(), "hosts")})
+
+	tests := []ddns.UpdateRequest{
+		{Domain: "example.com", RecordType: "A", Value: "2001:db8::1"},
+		{Domain: "example.com", RecordType: "AAAA", Value: "203.0.113.1"},
+		{Domain: "example.com", RecordType: "TXT", Value: "token"},
+	}
+
+	for _, req := range tests {
+		if _, err := provider.UpdateRecord(context.Background(), req); err == nil {
+			t.Errorf("Expected error for %s record with value %s", req.RecordType, req.Value)
+		}
+	}
+}
+
+func TestFileProviderReplacesFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0600); err != nil {
+		t.Fatalf("failed to write hosts file: %v", err)
+	}
+
+	// A reader holding the old file keeps seeing its complete content
+	old, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open hosts file: %v", err)
+	}
+	defer old.Close()
+
+	provider := NewFileProvider(FileConfig{Path: path})
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	oldData, _ := io.ReadAll(old)
+	if string(oldData) != "127.0.0.1\tlocalhost\n" {
+		t.Errorf("Expected the original file to be replaced rather than rewritten, got:\n%s", oldData)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat hosts file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected the file mode to be kept, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary file to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestFileProviderWritesThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "hosts.real")
+	if err := os.WriteFile(target, []byte("127.0.0.1\tlocalhost\n"), 0644); err != nil {
+		t.Fatalf("failed to write hosts file: %v", err)
+	}
+	link := filepath.Join(dir, "hosts")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	provider := NewFileProvider(FileConfig{Path: link})
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected the symlink to be kept, got %v, %v", info, err)
+	}
+	data, _ := os.ReadFile(target)
+	if !strings.Contains(string(data), "203.0.113.1\thome.example.com") {
+		t.Errorf("Expected the link target to be updated, got:\n%s", data)
+	}
+}
+
+func TestFileProviderRewritesInPlaceWhenRenameFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1\tlocalhost\n"), 0644); err != nil {
+		t.Fatalf("failed to write hosts file: %v", err)
+	}
+
+	// Renaming over a bind-mounted file fails with EBUSY
+	provider := NewFileProvider(FileConfig{Path: path})
+	provider.rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: syscall.EBUSY}
+	}
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected the in-place fallback to succeed, got %v", err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "127.0.0.1\tlocalhost\n") || !strings.Contains(string(data), "203.0.113.1\thome.example.com") {
+		t.Errorf("Expected the file to be rewritten in place, got:\n%s", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected no temporary file to be left behind, got %d entries", len(entries))
+	}
+}
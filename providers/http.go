@@ -0,0 +1,219 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+// NewHTTPClient creates an HTTP client shared by providers and IP detectors
+// userAgent and headers, when set, replace those of every outgoing request
+func NewHTTPClient(timeout time.Duration, userAgent string, headers map[string]string) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 10
+	transport.MaxIdleConnsPerHost = 2
+	transport.IdleConnTimeout = 90 * time.Second
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.ResponseHeaderTimeout = timeout
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &headerTransport{
+			userAgent: userAgent,
+			headers:   headers,
+			next:      transport,
+		},
+	}
+}
+
+// ClientWithHeaders returns a copy of client that also sends headers with every request
+// headers take precedence over those the client already sets, such as the global http.headers
+// client is returned as is when headers is empty; a bare client is used when it's nil
+func ClientWithHeaders(client *http.Client, headers map[string]string) *http.Client {
+	if len(headers) == 0 {
+		return client
+	}
+
+	clone := *httpClientOrDefault(client)
+	switch next := clone.Transport.(type) {
+	case nil:
+		clone.Transport = &headerTransport{headers: headers, next: http.DefaultTransport}
+	case *headerTransport:
+		// Merged into one transport, since an inner one would overwrite these headers with its own
+		clone.Transport = &headerTransport{userAgent: next.userAgent, headers: mergeHeaders(next.headers, headers), next: next.next}
+	default:
+		clone.Transport = &headerTransport{headers: headers, next: next}
+	}
+	return &clone
+}
+
+// mergeHeaders returns base with overrides applied, matching header names case-insensitively
+func mergeHeaders(base, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(overrides))
+	for name, value := range base {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range overrides {
+		merged[http.CanonicalHeaderKey(name)] = value
+	}
+	return merged
+}
+
+// ClientWithProxy returns a copy of client whose requests go through proxyURL, an http, https, socks5 or socks5h URL
+// client is returned as is when proxyURL is empty, so HTTP_PROXY, HTTPS_PROXY and NO_PROXY still apply
+func ClientWithProxy(client *http.Client, proxyURL string) (*http.Client, error) {
+	if proxyURL == "" {
+		return client, nil
+	}
+
+	proxy, err := ParseProxyURL(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := *httpClientOrDefault(client)
+	transport, err := proxiedTransport(clone.Transport, proxy)
+	if err != nil {
+		return nil, err
+	}
+	clone.Transport = transport
+	return &clone, nil
+}
+
+// ParseProxyURL parses a proxy URL, checking it has a supported scheme and a host
+// Errors never include the URL, since it may hold the proxy's credentials
+func ParseProxyURL(proxyURL string) (*url.URL, error) {
+	proxy, err := url.Parse(proxyURL)
+	if err != nil {
+		// url.Error repeats the whole URL, so only its cause is reported
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	switch proxy.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https, socks5 or socks5h)", proxy.Scheme)
+	}
+	if proxy.Host == "" {
+		return nil, fmt.Errorf("proxy URL has no host")
+	}
+
+	return proxy, nil
+}
+
+// proxiedTransport returns a copy of transport that sends requests through proxy
+// Wrapping transports are copied with the proxy applied to the transport they wrap
+func proxiedTransport(transport http.RoundTripper, proxy *url.URL) (http.RoundTripper, error) {
+	switch t := transport.(type) {
+	case nil:
+		return proxiedTransport(http.DefaultTransport, proxy)
+	case ddns.WrappingTransport:
+		next, err := proxiedTransport(t.Unwrap(), proxy)
+		if err != nil {
+			return nil, err
+		}
+		return t.Wrap(next), nil
+	case *http.Transport:
+		clone := t.Clone()
+		clone.Proxy = http.ProxyURL(proxy)
+		return clone, nil
+	default:
+		return nil, fmt.Errorf("cannot configure a proxy on HTTP transport %T", transport)
+	}
+}
+
+// headerTransport sets the configured User-Agent and headers on every outgoing request
+type headerTransport struct {
+	userAgent string
+	headers   map[string]string
+	next      http.RoundTripper
+}
+
+// Unwrap returns the transport requests are passed on to
+func (t *headerTransport) Unwrap() http.RoundTripper {
+	return t.next
+}
+
+// Wrap returns a copy of the header transport that passes requests on to next
+func (t *headerTransport) Wrap(next http.RoundTripper) http.RoundTripper {
+	return &headerTransport{userAgent: t.userAgent, headers: t.headers, next: next}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent == "" && len(t.headers) == 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	clone := req.Clone(req.Context())
+	if t.userAgent != "" {
+		clone.Header.Set("User-Agent", t.userAgent)
+	}
+	for name, value := range t.headers {
+		clone.Header.Set(name, value)
+	}
+	return t.next.RoundTrip(clone)
+}
+
+// rateLimitError returns an error for a 429 response, or nil otherwise
+// When Retry-After is present it's an executor.RetryAfterError, so the executor waits as asked
+func rateLimitError(resp *http.Response) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return nil
+	}
+
+	err := httpStatusError(resp, nil)
+	delay, ok := executor.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	if !ok {
+		return err
+	}
+	return &executor.RetryAfterError{Err: err, RetryAfter: delay}
+}
+
+// executorOrDefault returns the given executor, or one with the default API retry policy if nil
+func executorOrDefault(exec *executor.Executor) *executor.Executor {
+	if exec != nil {
+		return exec
+	}
+
+	return executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(30*time.Second)),
+	)
+}
+
+// executorOrDefaultRetrying is like executorOrDefault, but the default policy gives up
+// immediately on errors that retryable reports as permanent
+func executorOrDefaultRetrying(exec *executor.Executor, retryable func(error) bool) *executor.Executor {
+	if exec != nil {
+		return exec
+	}
+
+	backoff := executor.NewExponentialBackoffStrategy(3, time.Second, 2.0)
+	shouldRetry := func(attempt int, err error) bool {
+		return err != nil && retryable(err)
+	}
+
+	return executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewConditionalRetryStrategy(3, time.Second, shouldRetry, backoff.GetDelay)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(30*time.Second)),
+	)
+}
+
+// httpClientOrDefault returns the given client, or a new bare client if nil
+func httpClientOrDefault(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return &http.Client{}
+}
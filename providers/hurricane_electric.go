@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const hurricaneElectricUpdateURL = "https://dyn.dns.he.net/nic/update"
+
+// HurricaneElectricProvider implements the DDNS Provider interface for Hurricane Electric (dns.he.net)
+type HurricaneElectricProvider struct {
+	hostname   string
+	client     *DynDNS2Client
+	nameserver string
+}
+
+// HEConfig holds Hurricane Electric-specific configuration
+type HEConfig struct {
+	Hostname   string             // Record used for credential validation
+	Password   string             // The DDNS key generated for the record
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+	Nameserver string             // Optional nameserver records are read from; the system resolver is used when empty
+}
+
+// NewHurricaneElectricProvider creates a new Hurricane Electric DDNS provider
+func NewHurricaneElectricProvider(config HEConfig) *HurricaneElectricProvider {
+	return &HurricaneElectricProvider{
+		hostname: config.Hostname,
+		client: &DynDNS2Client{
+			name:            "Hurricane Electric",
+			updateURL:       hurricaneElectricUpdateURL,
+			password:        config.Password,
+			passwordInQuery: true, // HE authenticates with the per-record key
+			httpClient:      httpClientOrDefault(config.HTTPClient),
+			executor:        executorOrDefaultRetrying(config.Executor, isRetryableDynDNS2Error),
+		},
+		nameserver: config.Nameserver,
+	}
+}
+
+// UpdateRecord updates the record through HE's DynDNS v2 endpoint
+func (h *HurricaneElectricProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, h.GetProviderName(), "UpdateRecord")
+
+	if req.RecordType != "A" && req.RecordType != "AAAA" {
+		return nil, fmt.Errorf("Hurricane Electric dynamic DNS does not support %s records, only A and AAAA", req.RecordType)
+	}
+
+	result, err := h.client.Update(ctx, req.Domain, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	message := "Hurricane Electric record updated successfully"
+	if !result.Changed {
+		message = "Hurricane Electric record already up to date"
+	}
+
+	return &ddns.UpdateResponse{
+		Success:   true,
+		Message:   message,
+		RecordID:  req.Domain, // HE doesn't expose record IDs through the dynamic endpoint
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+// GetCurrentRecord resolves the record through DNS since HE's dynamic endpoint can't read records
+func (h *HurricaneElectricProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, h.GetProviderName(), "GetCurrentRecord")
+
+	return ResolverBackedGetCurrentRecord(ctx, domain, recordType, h.nameserver)
+}
+
+// ValidateCredentials re-submits the record's current address, which HE answers with nochg
+func (h *HurricaneElectricProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, h.GetProviderName(), "ValidateCredentials")
+
+	// Without an explicit address HE would publish the validator's source address instead
+	ip, err := h.GetCurrentRecord(ctx, h.hostname, "A")
+	if err != nil {
+		return fmt.Errorf("can't resolve %s to re-submit its address: %w", h.hostname, err)
+	}
+
+	_, err = h.client.Update(ctx, h.hostname, ip)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (h *HurricaneElectricProvider) GetProviderName() string {
+	return "hurricane_electric"
+}
+
+// Capabilities reports the record types Hurricane Electric supports; records are read through DNS
+func (h *HurricaneElectricProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("hurricane_electric")
+}
+
+// registerHurricaneElectric adds the Hurricane Electric provider to the factory
+func registerHurricaneElectric(f *Factory) {
+	f.Register("hurricane_electric", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("hurricane_electric provider requires API key (the record's DDNS key)")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewHurricaneElectricProvider(HEConfig{
+			Hostname:   config.Domain,
+			Password:   config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+			Nameserver: config.Nameserver,
+		}), nil
+	})
+}
@@ -0,0 +1,111 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+func TestParseDynDNS2Response(t *testing.T) {
+	tests := []struct {
+		body        string
+		wantChanged bool
+		wantIP      string
+		wantCode    string
+	}{
+		{body: "good 203.0.113.1", wantChanged: true, wantIP: "203.0.113.1"},
+		{body: "nochg 203.0.113.1\n", wantIP: "203.0.113.1"},
+		{body: "badauth", wantCode: "badauth"},
+		{body: "abuse", wantCode: "abuse"},
+	}
+
+	for _, tt := range tests {
+		result, err := parseDynDNS2Response("Test", tt.body)
+		if tt.wantCode != "" {
+			var protocolErr *DynDNS2Error
+			if !errors.As(err, &protocolErr) || protocolErr.Code != tt.wantCode {
+				t.Errorf("%q: expected DynDNS2Error %s, got %v", tt.body, tt.wantCode, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: expected no error, got %v", tt.body, err)
+			continue
+		}
+		if result.Changed != tt.wantChanged || result.IP != tt.wantIP {
+			t.Errorf("%q: expected changed=%v ip=%s, got %+v", tt.body, tt.wantChanged, tt.wantIP, result)
+		}
+	}
+}
+
+func TestHurricaneElectricUpdateRecord(t *testing.T) {
+	var requests int32
+	response := "good 203.0.113.1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		query := r.URL.Query()
+		if query.Get("hostname") != "home.example.com" || query.Get("password") != "ddns-key" || query.Get("myip") != "203.0.113.1" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	provider := NewHurricaneElectricProvider(HEConfig{Hostname: "home.example.com", Password: "ddns-key"})
+	provider.client.updateURL = server.URL
+	req := ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1"}
+
+	resp, err := provider.UpdateRecord(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !resp.Success {
+		t.Error("Expected successful update")
+	}
+
+	// badauth is permanent and must not be retried
+	response = "badauth"
+	atomic.StoreInt32(&requests, 0)
+	if _, err := provider.UpdateRecord(context.Background(), req); err == nil {
+		t.Fatal("Expected badauth error")
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected badauth not to be retried, got %d requests", got)
+	}
+}
+
+func TestHurricaneElectricValidateCredentialsResubmitsResolvedAddress(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if myip := r.URL.Query().Get("myip"); myip != "203.0.113.1" {
+			t.Errorf("Expected the resolved address to be re-submitted, got myip=%q", myip)
+		}
+		fmt.Fprint(w, "nochg 203.0.113.1")
+	}))
+	defer server.Close()
+
+	nameserver := startMockDNSServer(t, map[string][]string{"home.example.com.": {"203.0.113.1"}})
+
+	provider := NewHurricaneElectricProvider(HEConfig{Hostname: "home.example.com", Password: "ddns-key", Nameserver: nameserver})
+	provider.client.updateURL = server.URL
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Fatalf("Expected validation to succeed, got %v", err)
+	}
+
+	// Without a resolved address nothing is sent, so HE can't fall back to the source address
+	provider.hostname = "missing.example.com"
+	if err := provider.ValidateCredentials(context.Background()); err == nil {
+		t.Error("Expected an error for an unresolvable record")
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected only the first validation to contact HE, got %d requests", got)
+	}
+}
@@ -0,0 +1,388 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const linodeBaseURL = "https://api.linode.com/v4"
+
+// LinodeProvider implements the DDNS Provider interface for Linode (Akamai Cloud) DNS
+type LinodeProvider struct {
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+
+	// The token can be replaced by SetToken; domains are cached since they don't change between updates
+	mu       sync.Mutex
+	apiToken string
+	domainID int                     // Configured domain every record is in, or zero to find each record's zone
+	domains  map[string]linodeDomain // Domain of each record name, keyed by lowercase FQDN
+}
+
+// LinodeConfig holds Linode-specific configuration
+type LinodeConfig struct {
+	APIToken   string
+	DomainID   int                // Numeric Linode domain ID; looked up by name when zero
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// linodeDomain represents a domain (zone) in the Linode API
+type linodeDomain struct {
+	ID     int    `json:"id"`
+	Domain string `json:"domain"`
+}
+
+// linodeRecord represents a DNS record in the Linode API
+type linodeRecord struct {
+	ID     int    `json:"id,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	TTLSec int    `json:"ttl_sec,omitempty"`
+}
+
+// linodeErrorResponse represents Linode's error envelope
+type linodeErrorResponse struct {
+	Errors []struct {
+		Field  string `json:"field"`
+		Reason string `json:"reason"`
+	} `json:"errors"`
+}
+
+// Error formats all reported errors into a single message
+func (e *linodeErrorResponse) Error() string {
+	reasons := make([]string, 0, len(e.Errors))
+	for _, linodeErr := range e.Errors {
+		if linodeErr.Field != "" {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", linodeErr.Field, linodeErr.Reason))
+		} else {
+			reasons = append(reasons, linodeErr.Reason)
+		}
+	}
+	return fmt.Sprintf("Linode API error: %s", strings.Join(reasons, "; "))
+}
+
+// NewLinodeProvider creates a new Linode DDNS provider
+func NewLinodeProvider(config LinodeConfig) *LinodeProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &LinodeProvider{
+		apiToken:   config.APIToken,
+		baseURL:    linodeBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+		executor:   exec,
+		domainID:   config.DomainID,
+		domains:    make(map[string]linodeDomain),
+	}
+}
+
+// UpdateRecord updates a DNS record in Linode, creating it if it does not exist yet
+func (l *LinodeProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, l.GetProviderName(), "UpdateRecord")
+
+	domain, err := l.resolveDomain(ctx, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	name := relativeName(req.Domain, domain.Domain)
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		existing, err := l.findRecord(taskCtx, domain.ID, name, req.RecordType)
+		if err != nil {
+			return nil, err
+		}
+
+		record := linodeRecord{
+			Name:   name,
+			Target: req.Value,
+			TTLSec: req.TTL,
+		}
+
+		var result linodeRecord
+		if existing != nil {
+			path := fmt.Sprintf("/domains/%d/records/%d", domain.ID, existing.ID)
+			err = l.do(taskCtx, "PUT", path, record, &result)
+		} else {
+			record.Type = req.RecordType
+			path := fmt.Sprintf("/domains/%d/records", domain.ID)
+			err = l.do(taskCtx, "POST", path, record, &result)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "Linode record updated successfully",
+			RecordID:  fmt.Sprintf("%d", result.ID),
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(l.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value
+func (l *LinodeProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, l.GetProviderName(), "GetCurrentRecord")
+
+	linodeDomain, err := l.resolveDomain(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	name := relativeName(domain, linodeDomain.Domain)
+
+	task := func(taskCtx context.Context) (*linodeRecord, error) {
+		return l.findRecord(taskCtx, linodeDomain.ID, name, recordType)
+	}
+
+	record, err := executor.ExecuteSimple(l.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("Linode %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return record.Target, nil
+}
+
+// ValidateCredentials checks if the Linode API token is valid
+func (l *LinodeProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, l.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		l.mu.Lock()
+		domainID := l.domainID
+		l.mu.Unlock()
+
+		if domainID != 0 {
+			return nil, l.do(taskCtx, "GET", fmt.Sprintf("/domains/%d", domainID), nil, nil)
+		}
+
+		// Without a domain ID, listing domains still proves the token works
+		return nil, l.do(taskCtx, "GET", "/domains", nil, nil)
+	}
+
+	_, err = executor.ExecuteSimple(l.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (l *LinodeProvider) GetProviderName() string {
+	return "linode"
+}
+
+// Capabilities reports the record types Linode supports
+func (l *LinodeProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("linode")
+}
+
+// SetToken replaces the token sent with API requests, e.g. with a fresh OAuth access token
+func (l *LinodeProvider) SetToken(token string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.apiToken = token
+}
+
+// resolveDomain returns the Linode domain for the configured ID, or finds the zone fqdn is in by name
+// Records of different zones are cached separately, so each is written to its own zone
+func (l *LinodeProvider) resolveDomain(ctx context.Context, fqdn string) (*linodeDomain, error) {
+	key := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+
+	l.mu.Lock()
+	domainID := l.domainID
+	if domainID != 0 {
+		// Every record shares the configured domain
+		key = ""
+	}
+	cached, ok := l.domains[key]
+	l.mu.Unlock()
+	if ok {
+		return &cached, nil
+	}
+
+	task := func(taskCtx context.Context) (*linodeDomain, error) {
+		if domainID != 0 {
+			var domain linodeDomain
+			if err := l.do(taskCtx, "GET", fmt.Sprintf("/domains/%d", domainID), nil, &domain); err != nil {
+				return nil, err
+			}
+			return &domain, nil
+		}
+
+		var best *linodeDomain
+		for page := 1; ; page++ {
+			var list struct {
+				Data  []linodeDomain `json:"data"`
+				Pages int            `json:"pages"`
+			}
+			if err := l.do(taskCtx, "GET", fmt.Sprintf("/domains?page=%d", page), nil, &list); err != nil {
+				return nil, err
+			}
+
+			// Prefer the longest zone name the record falls under
+			if match := closestZone(fqdn, list.Data, linodeDomainName); match != nil && (best == nil || len(match.Domain) > len(best.Domain)) {
+				best = match
+			}
+
+			if page >= list.Pages {
+				break
+			}
+		}
+
+		if best == nil {
+			return nil, fmt.Errorf("no Linode domain found for %s", fqdn)
+		}
+		return best, nil
+	}
+
+	domain, err := executor.ExecuteSimple(l.executor, ctx, task)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	l.domains[key] = *domain
+	l.mu.Unlock()
+
+	return domain, nil
+}
+
+// findRecord looks up a record by name and type, returning nil if none exists
+func (l *LinodeProvider) findRecord(ctx context.Context, domainID int, name, recordType string) (*linodeRecord, error) {
+	for page := 1; ; page++ {
+		var list struct {
+			Data  []linodeRecord `json:"data"`
+			Pages int            `json:"pages"`
+		}
+		path := fmt.Sprintf("/domains/%d/records?page=%d", domainID, page)
+		if err := l.do(ctx, "GET", path, nil, &list); err != nil {
+			return nil, err
+		}
+
+		if record := findRecordByNameType(list.Data, name, recordType, linodeRecordFields); record != nil {
+			return record, nil
+		}
+
+		if page >= list.Pages {
+			return nil, nil
+		}
+	}
+}
+
+// linodeDomainName returns the zone name of a Linode domain
+func linodeDomainName(d linodeDomain) string {
+	return d.Domain
+}
+
+// linodeRecordFields returns the name and type used to match Linode records
+func linodeRecordFields(r linodeRecord) (string, string) {
+	return r.Name, r.Type
+}
+
+// do performs an authenticated Linode API request, decoding the response into out if non-nil
+func (l *LinodeProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, l.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	l.mu.Lock()
+	token := l.apiToken
+	l.mu.Unlock()
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr linodeErrorResponse
+		if err := json.Unmarshal(data, &apiErr); err == nil && len(apiErr.Errors) > 0 {
+			return httpStatusError(resp, &apiErr)
+		}
+		return httpStatusError(resp, nil)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// registerLinode adds the Linode provider to the factory
+func registerLinode(f *Factory) {
+	f.Register("linode", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("linode provider requires API key (personal access token)")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		linodeConfig := LinodeConfig{
+			APIToken:   config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}
+
+		if config.ZoneID != "" {
+			domainID, err := strconv.Atoi(config.ZoneID)
+			if err != nil {
+				return nil, fmt.Errorf("linode provider requires a numeric zone ID (domain ID), got %q", config.ZoneID)
+			}
+			linodeConfig.DomainID = domainID
+		}
+
+		return NewLinodeProvider(linodeConfig), nil
+	})
+}
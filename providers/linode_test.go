@@ -0,0 +1,217 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+// linodeTestServer serves the given zones, each with its records, and records every write
+type linodeTestServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	zones   []linodeDomain
+	records map[int][]linodeRecord
+	writes  []string // "METHOD path target" of every PUT and POST
+	lookups int      // Domain list requests
+}
+
+// newLinodeTestServer starts a fake Linode API for the zones and their records
+func newLinodeTestServer(t *testing.T, zones []linodeDomain, records map[int][]linodeRecord) *linodeTestServer {
+	s := &linodeTestServer{zones: zones, records: records}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"reason":"Invalid Token"}]}`)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		var domainID, recordID int
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/domains":
+			s.lookups++
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": s.zones, "pages": 1})
+		case r.Method == "GET" && scanPath(r.URL.Path, "/domains/%d/records", &domainID):
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": s.records[domainID], "pages": 1})
+		case r.Method == "PUT" && scanPath(r.URL.Path, "/domains/%d/records/%d", &domainID, &recordID):
+			var record linodeRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			s.writes = append(s.writes, fmt.Sprintf("PUT %s %s", r.URL.Path, record.Target))
+			record.ID = recordID
+			json.NewEncoder(w).Encode(record)
+		case r.Method == "POST" && scanPath(r.URL.Path, "/domains/%d/records", &domainID):
+			var record linodeRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			s.writes = append(s.writes, fmt.Sprintf("POST %s %s", r.URL.Path, record.Target))
+			record.ID = 999
+			json.NewEncoder(w).Encode(record)
+		case r.Method == "GET" && scanPath(r.URL.Path, "/domains/%d", &domainID):
+			for _, zone := range s.zones {
+				if zone.ID == domainID {
+					json.NewEncoder(w).Encode(zone)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"errors":[{"reason":"Not found"}]}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// scanPath reports whether path matches format exactly, storing the numbers it holds
+func scanPath(path, format string, ids ...interface{}) bool {
+	n, err := fmt.Sscanf(path, format, ids...)
+	return err == nil && n == len(ids) && fmt.Sprintf(format, derefInts(ids)...) == path
+}
+
+// derefInts returns the values behind int pointers, for formatting them back
+func derefInts(ids []interface{}) []interface{} {
+	values := make([]interface{}, len(ids))
+	for i, id := range ids {
+		values[i] = *id.(*int)
+	}
+	return values
+}
+
+// newTestLinodeProvider returns a provider talking to the test server without retrying
+func newTestLinodeProvider(server *linodeTestServer, domainID int) *LinodeProvider {
+	provider := NewLinodeProvider(LinodeConfig{
+		APIToken: "token",
+		DomainID: domainID,
+		Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())),
+	})
+	provider.baseURL = server.URL
+	return provider
+}
+
+func TestLinodeUpdateRecordInSeveralZones(t *testing.T) {
+	server := newLinodeTestServer(t,
+		[]linodeDomain{{ID: 1, Domain: "example.com"}, {ID: 2, Domain: "example.net"}},
+		map[int][]linodeRecord{
+			1: {{ID: 10, Type: "A", Name: "home", Target: "198.51.100.1"}},
+			2: {{ID: 20, Type: "A", Name: "home", Target: "198.51.100.1"}},
+		},
+	)
+	provider := newTestLinodeProvider(server, 0)
+	ctx := context.Background()
+
+	for _, domain := range []string{"home.example.com", "home.example.net", "home.example.com"} {
+		if _, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: domain, RecordType: "A", Value: "203.0.113.1"}); err != nil {
+			t.Fatalf("UpdateRecord(%s) error = %v", domain, err)
+		}
+	}
+
+	want := []string{
+		"PUT /domains/1/records/10 203.0.113.1",
+		"PUT /domains/2/records/20 203.0.113.1",
+		"PUT /domains/1/records/10 203.0.113.1",
+	}
+	if fmt.Sprint(server.writes) != fmt.Sprint(want) {
+		t.Errorf("Expected each record written to its own zone %v, got %v", want, server.writes)
+	}
+	if server.lookups != 2 {
+		t.Errorf("Expected one domain lookup per zone, got %d", server.lookups)
+	}
+}
+
+func TestLinodeUpdateRecordCreatesMissingRecord(t *testing.T) {
+	server := newLinodeTestServer(t, []linodeDomain{{ID: 1, Domain: "example.com"}}, map[int][]linodeRecord{})
+	provider := newTestLinodeProvider(server, 0)
+
+	resp, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "home.example.com", RecordType: "AAAA", Value: "2001:db8::1", TTL: 300})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.RecordID != "999" {
+		t.Errorf("Expected the created record's ID, got %s", resp.RecordID)
+	}
+	if len(server.writes) != 1 || server.writes[0] != "POST /domains/1/records 2001:db8::1" {
+		t.Errorf("Expected the record to be created, got %v", server.writes)
+	}
+}
+
+func TestLinodeUpdateRecordWithConfiguredDomain(t *testing.T) {
+	server := newLinodeTestServer(t,
+		[]linodeDomain{{ID: 1, Domain: "example.com"}, {ID: 2, Domain: "example.net"}},
+		map[int][]linodeRecord{2: {{ID: 20, Type: "A", Name: "", Target: "198.51.100.1"}}},
+	)
+	provider := newTestLinodeProvider(server, 2)
+
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "example.net", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(server.writes) != 1 || server.writes[0] != "PUT /domains/2/records/20 203.0.113.1" {
+		t.Errorf("Expected the apex record of the configured domain to be updated, got %v", server.writes)
+	}
+	if server.lookups != 0 {
+		t.Errorf("Expected no domain list lookup with a configured domain, got %d", server.lookups)
+	}
+}
+
+func TestLinodeGetCurrentRecord(t *testing.T) {
+	server := newLinodeTestServer(t,
+		[]linodeDomain{{ID: 1, Domain: "example.com"}},
+		map[int][]linodeRecord{1: {
+			{ID: 10, Type: "A", Name: "home", Target: "203.0.113.1"},
+			{ID: 11, Type: "AAAA", Name: "home", Target: "2001:db8::1"},
+		}},
+	)
+	provider := newTestLinodeProvider(server, 0)
+	ctx := context.Background()
+
+	value, err := provider.GetCurrentRecord(ctx, "home.example.com", "AAAA")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "2001:db8::1" {
+		t.Errorf("Expected 2001:db8::1, got %s", value)
+	}
+
+	if _, err := provider.GetCurrentRecord(ctx, "other.example.com", "A"); !errors.Is(err, ddns.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing record, got %v", err)
+	}
+	if _, err := provider.GetCurrentRecord(ctx, "home.example.org", "A"); err == nil {
+		t.Error("Expected an error for a record outside every zone")
+	}
+}
+
+func TestLinodeValidateCredentials(t *testing.T) {
+	server := newLinodeTestServer(t, []linodeDomain{{ID: 1, Domain: "example.com"}}, nil)
+
+	if err := newTestLinodeProvider(server, 0).ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("Expected valid credentials, got %v", err)
+	}
+	if err := newTestLinodeProvider(server, 1).ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("Expected valid credentials for the configured domain, got %v", err)
+	}
+
+	provider := newTestLinodeProvider(server, 0)
+	provider.SetToken("wrong")
+	err := provider.ValidateCredentials(context.Background())
+	if !errors.Is(err, ddns.ErrAuthFailed) {
+		t.Fatalf("Expected ErrAuthFailed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Invalid Token") {
+		t.Errorf("Expected the Linode error reason in %q", err)
+	}
+	if providerErr, ok := ddns.IsProviderError(err); !ok || providerErr.Retryable {
+		t.Errorf("Expected a non-retryable provider error, got %v", err)
+	}
+}
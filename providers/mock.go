@@ -3,9 +3,11 @@ package providers
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
 )
 
 // MockProvider is a simple mock implementation for testing
@@ -14,6 +16,28 @@ type MockProvider struct {
 	records        map[string]string // domain -> IP mapping
 	shouldFail     bool
 	validateResult error
+	callDelay      time.Duration
+	failCount      int                // Update attempts left to fail before succeeding
+	executor       *executor.Executor // Runs update attempts when set, like the real providers
+
+	// Calls records every method invocation in order, for assertions in tests
+	Calls   []ProviderCall
+	callsMu sync.Mutex
+}
+
+// ProviderCall records a single invocation of a MockProvider method
+type ProviderCall struct {
+	Method   string
+	Args     interface{} // ddns.UpdateRequest, []ddns.UpdateRequest, RecordLookup or nil depending on Method
+	CalledAt time.Time
+	Result   interface{}
+	Err      error
+}
+
+// RecordLookup holds the arguments of a GetCurrentRecord call
+type RecordLookup struct {
+	Domain     string
+	RecordType string
 }
 
 // NewMockProvider creates a new mock DDNS provider
@@ -36,14 +60,131 @@ func (m *MockProvider) WithValidationError(err error) *MockProvider {
 	return m
 }
 
+// WithLatency makes every call wait for d before returning, or until its context is done, to simulate slow APIs
+func (m *MockProvider) WithLatency(d time.Duration) *MockProvider {
+	m.callDelay = d
+	return m
+}
+
+// WithCallDelay makes every call wait for d before returning
+//
+// Deprecated: use WithLatency
+func (m *MockProvider) WithCallDelay(d time.Duration) *MockProvider {
+	return m.WithLatency(d)
+}
+
+// WithFailCount makes the first n update attempts fail before updates succeed, to simulate a flaky API
+func (m *MockProvider) WithFailCount(n int) *MockProvider {
+	m.failCount = n
+	return m
+}
+
+// WithExecutor runs every update through exec, so each retry is a separate recorded UpdateRecord attempt
+func (m *MockProvider) WithExecutor(exec *executor.Executor) *MockProvider {
+	m.executor = exec
+	return m
+}
+
+// CallsForMethod returns the recorded calls of the named method
+func (m *MockProvider) CallsForMethod(name string) []ProviderCall {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+
+	var calls []ProviderCall
+	for _, call := range m.Calls {
+		if call.Method == name {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// ResetCalls clears the recorded call history
+func (m *MockProvider) ResetCalls() {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+	m.Calls = nil
+}
+
+// record appends a call to the history
+func (m *MockProvider) record(method string, args interface{}, calledAt time.Time, result interface{}, err error) {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+	m.Calls = append(m.Calls, ProviderCall{
+		Method:   method,
+		Args:     args,
+		CalledAt: calledAt,
+		Result:   result,
+		Err:      err,
+	})
+}
+
+// consumeFailure reports whether the current update attempt should fail, using up one configured failure
+func (m *MockProvider) consumeFailure() bool {
+	m.callsMu.Lock()
+	defer m.callsMu.Unlock()
+
+	if m.failCount <= 0 {
+		return false
+	}
+	m.failCount--
+	return true
+}
+
+// delay waits for the configured call delay or until the context is done
+func (m *MockProvider) delay(ctx context.Context) error {
+	if m.callDelay <= 0 {
+		return nil
+	}
+
+	select {
+	case <-time.After(m.callDelay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // UpdateRecord updates a DNS record (mock implementation)
-func (m *MockProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (*ddns.UpdateResponse, error) {
+func (m *MockProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, m.GetProviderName(), "UpdateRecord")
+
+	if m.executor == nil {
+		return m.updateRecord(ctx, req)
+	}
+
+	return executor.ExecuteSimple(m.executor, ctx, func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		return m.updateRecord(taskCtx, req)
+	})
+}
+
+// updateRecord makes a single update attempt
+func (m *MockProvider) updateRecord(ctx context.Context, req ddns.UpdateRequest) (resp *ddns.UpdateResponse, err error) {
+	defer func(calledAt time.Time) {
+		m.record("UpdateRecord", req, calledAt, resp, err)
+	}(time.Now())
+
+	if err := m.delay(ctx); err != nil {
+		return nil, err
+	}
+
 	if m.shouldFail {
 		return nil, fmt.Errorf("mock provider configured to fail")
 	}
+	if m.consumeFailure() {
+		return nil, fmt.Errorf("mock provider simulating a transient failure")
+	}
+
+	value := req.Value
+	if req.SRVValue != nil {
+		if err := req.SRVValue.Validate(); err != nil {
+			return nil, err
+		}
+		value = req.SRVValue.FormatSRV()
+	}
 
 	key := fmt.Sprintf("%s:%s", req.Domain, req.RecordType)
-	m.records[key] = req.Value
+	m.records[key] = value
 
 	return &ddns.UpdateResponse{
 		Success:   true,
@@ -53,8 +194,38 @@ func (m *MockProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest)
 	}, nil
 }
 
+// UpdateRecords updates several DNS records by updating each one in turn (mock implementation)
+func (m *MockProvider) UpdateRecords(ctx context.Context, reqs []ddns.UpdateRequest) (resps []*ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, m.GetProviderName(), "UpdateRecords")
+
+	defer func(calledAt time.Time) {
+		m.record("UpdateRecords", reqs, calledAt, resps, err)
+	}(time.Now())
+
+	resps = make([]*ddns.UpdateResponse, 0, len(reqs))
+	for _, req := range reqs {
+		resp, err := m.UpdateRecord(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", req.Domain, err)
+		}
+		resps = append(resps, resp)
+	}
+
+	return resps, nil
+}
+
 // GetCurrentRecord retrieves the current DNS record value (mock implementation)
-func (m *MockProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (string, error) {
+func (m *MockProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (value string, err error) {
+	defer wrapProviderError(&err, m.GetProviderName(), "GetCurrentRecord")
+
+	defer func(calledAt time.Time) {
+		m.record("GetCurrentRecord", RecordLookup{Domain: domain, RecordType: recordType}, calledAt, value, err)
+	}(time.Now())
+
+	if err := m.delay(ctx); err != nil {
+		return "", err
+	}
+
 	if m.shouldFail {
 		return "", fmt.Errorf("mock provider configured to fail")
 	}
@@ -64,11 +235,21 @@ func (m *MockProvider) GetCurrentRecord(ctx context.Context, domain, recordType
 		return value, nil
 	}
 
-	return "", fmt.Errorf("record not found")
+	return "", ddns.ErrNotFound
 }
 
 // ValidateCredentials checks if the provider credentials are valid (mock implementation)
-func (m *MockProvider) ValidateCredentials(ctx context.Context) error {
+func (m *MockProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, m.GetProviderName(), "ValidateCredentials")
+
+	defer func(calledAt time.Time) {
+		m.record("ValidateCredentials", nil, calledAt, nil, err)
+	}(time.Now())
+
+	if err := m.delay(ctx); err != nil {
+		return err
+	}
+
 	if m.validateResult != nil {
 		return m.validateResult
 	}
@@ -85,6 +266,11 @@ func (m *MockProvider) GetProviderName() string {
 	return fmt.Sprintf("mock-%s", m.name)
 }
 
+// Capabilities reports that the mock supports everything
+func (m *MockProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("mock")
+}
+
 // SetRecord manually sets a record (for testing)
 func (m *MockProvider) SetRecord(domain, recordType, value string) {
 	key := fmt.Sprintf("%s:%s", domain, recordType)
@@ -95,3 +281,81 @@ func (m *MockProvider) SetRecord(domain, recordType, value string) {
 func (m *MockProvider) GetRecords() map[string]string {
 	return m.records
 }
+
+// MockIPDetector is a ddns.IPDetector and ddns.IPv6Detector for tests that reports fixed addresses
+type MockIPDetector struct {
+	mu    sync.Mutex
+	ip    string
+	ipv6  string
+	err   error
+	calls int
+}
+
+// NewMockIPDetector creates a mock IP detector reporting ip
+func NewMockIPDetector(ip string) *MockIPDetector {
+	return &MockIPDetector{ip: ip}
+}
+
+// WithIP sets the address GetPublicIP reports
+func (d *MockIPDetector) WithIP(ip string) *MockIPDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ip = ip
+	return d
+}
+
+// WithIPv6 sets the address GetPublicIPv6 reports
+func (d *MockIPDetector) WithIPv6(ip string) *MockIPDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ipv6 = ip
+	return d
+}
+
+// WithError makes every detection fail with err; nil restores the configured addresses
+func (d *MockIPDetector) WithError(err error) *MockIPDetector {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.err = err
+	return d
+}
+
+// GetPublicIP returns the configured IPv4 address (mock implementation)
+func (d *MockIPDetector) GetPublicIP(ctx context.Context) (string, error) {
+	return d.detect(d.ip, "IPv4")
+}
+
+// GetPublicIPv6 returns the configured IPv6 address (mock implementation)
+func (d *MockIPDetector) GetPublicIPv6(ctx context.Context) (string, error) {
+	return d.detect(d.ipv6, "IPv6")
+}
+
+// Calls returns how many detections have been made, of either family
+func (d *MockIPDetector) Calls() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.calls
+}
+
+// detect counts the call and returns ip, the configured error, or an error if ip isn't set
+func (d *MockIPDetector) detect(ip, family string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.calls++
+	if d.err != nil {
+		return "", d.err
+	}
+	if ip == "" {
+		return "", fmt.Errorf("mock IP detector has no %s address", family)
+	}
+	return ip, nil
+}
+
+// registerMock adds the in-memory mock provider to the factory
+func registerMock(f *Factory) {
+	f.Register("mock", func(config ddns.Config) (ddns.Provider, error) {
+		// Mock provider doesn't require any specific configuration
+		return NewMockProvider("test").WithExecutor(f.executor), nil
+	})
+}
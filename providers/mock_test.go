@@ -0,0 +1,206 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestMockProviderRecordsCalls(t *testing.T) {
+	provider := NewMockProvider("test")
+	ctx := context.Background()
+
+	req := ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.1"}
+	if _, err := provider.UpdateRecord(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	provider.GetCurrentRecord(ctx, "missing.com", "A")
+
+	updates := provider.CallsForMethod("UpdateRecord")
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 UpdateRecord call, got %d", len(updates))
+	}
+	if updates[0].Args != req {
+		t.Errorf("Expected recorded request %+v, got %+v", req, updates[0].Args)
+	}
+	if updates[0].Err != nil || updates[0].Result == nil {
+		t.Errorf("Expected successful result to be recorded, got result %v err %v", updates[0].Result, updates[0].Err)
+	}
+
+	lookups := provider.CallsForMethod("GetCurrentRecord")
+	if len(lookups) != 1 {
+		t.Fatalf("Expected 1 GetCurrentRecord call, got %d", len(lookups))
+	}
+	if lookups[0].Args != (RecordLookup{Domain: "missing.com", RecordType: "A"}) {
+		t.Errorf("Unexpected lookup args %+v", lookups[0].Args)
+	}
+	if lookups[0].Err == nil {
+		t.Error("Expected lookup error to be recorded")
+	}
+
+	if len(provider.Calls) != 2 {
+		t.Errorf("Expected 2 calls in history, got %d", len(provider.Calls))
+	}
+
+	provider.ResetCalls()
+	if len(provider.Calls) != 0 {
+		t.Errorf("Expected history to be empty after reset, got %d calls", len(provider.Calls))
+	}
+}
+
+func TestMockProviderLatency(t *testing.T) {
+	provider := NewMockProvider("test").WithLatency(20 * time.Millisecond)
+
+	start := time.Now()
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected call to take at least 20ms, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := provider.ValidateCredentials(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled while delayed, got %v", err)
+	}
+}
+
+func TestMockProviderUpdateRecords(t *testing.T) {
+	provider := NewMockProvider("test")
+	ctx := context.Background()
+
+	reqs := []ddns.UpdateRequest{
+		{Domain: "a.example.com", RecordType: "A", Value: "203.0.113.1"},
+		{Domain: "b.example.com", RecordType: "A", Value: "203.0.113.1"},
+	}
+	resps, err := provider.UpdateRecords(ctx, reqs)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(resps) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(resps))
+	}
+
+	if len(provider.CallsForMethod("UpdateRecords")) != 1 || len(provider.CallsForMethod("UpdateRecord")) != 2 {
+		t.Errorf("Expected one batch call made of 2 updates, got %+v", provider.Calls)
+	}
+	if value, _ := provider.GetCurrentRecord(ctx, "b.example.com", "A"); value != "203.0.113.1" {
+		t.Errorf("Expected b.example.com to be updated, got %s", value)
+	}
+
+	if _, err := provider.WithFailure(true).UpdateRecords(ctx, reqs); err == nil {
+		t.Error("Expected error when the mock is configured to fail")
+	}
+}
+
+func TestMockIPDetector(t *testing.T) {
+	detector := NewMockIPDetector("203.0.113.1").WithIPv6("2001:db8::1")
+	ctx := context.Background()
+
+	if ip, err := detector.GetPublicIP(ctx); err != nil || ip != "203.0.113.1" {
+		t.Errorf("Expected 203.0.113.1, got %q, %v", ip, err)
+	}
+	if ip, err := detector.GetPublicIPv6(ctx); err != nil || ip != "2001:db8::1" {
+		t.Errorf("Expected 2001:db8::1, got %q, %v", ip, err)
+	}
+
+	detectErr := errors.New("no connectivity")
+	detector.WithError(detectErr)
+	if _, err := detector.GetPublicIP(ctx); !errors.Is(err, detectErr) {
+		t.Errorf("Expected configured error, got %v", err)
+	}
+
+	detector.WithError(nil).WithIP("203.0.113.2")
+	if ip, _ := detector.GetPublicIP(ctx); ip != "203.0.113.2" {
+		t.Errorf("Expected updated IP, got %s", ip)
+	}
+
+	if _, err := NewMockIPDetector("203.0.113.1").GetPublicIPv6(ctx); err == nil {
+		t.Error("Expected error when no IPv6 address is set")
+	}
+
+	if detector.Calls() != 4 {
+		t.Errorf("Expected 4 calls, got %d", detector.Calls())
+	}
+}
+
+func TestMockIPDetectorWithService(t *testing.T) {
+	provider := NewMockProvider("test")
+	detector := NewMockIPDetector("203.0.113.1")
+	service := ddns.NewServiceWithIPDetector(provider, ddns.Config{Domain: "example.com", RecordType: "A"}, detector)
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value, _ := provider.GetCurrentRecord(context.Background(), "example.com", "A"); value != "203.0.113.1" {
+		t.Errorf("Expected the detected IP to be published, got %s", value)
+	}
+	if detector.Calls() != 1 {
+		t.Errorf("Expected one detection, got %d", detector.Calls())
+	}
+}
+
+func TestMockProviderFailCount(t *testing.T) {
+	provider := NewMockProvider("test").WithFailCount(2)
+	req := ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.1"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := provider.UpdateRecord(context.Background(), req); err == nil {
+			t.Fatalf("Expected attempt %d to fail", i+1)
+		}
+	}
+	if _, err := provider.UpdateRecord(context.Background(), req); err != nil {
+		t.Fatalf("Expected third attempt to succeed, got %v", err)
+	}
+}
+
+func TestServiceRetriesFlakyProviderThroughExecutor(t *testing.T) {
+	exec := executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewFixedDelayStrategy(3, time.Millisecond)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(time.Second)),
+	)
+	provider := NewMockProvider("flaky").WithFailCount(2).WithLatency(5 * time.Millisecond).WithExecutor(exec)
+	service := ddns.NewServiceWithIPDetector(provider, ddns.Config{Domain: "example.com", RecordType: "A"}, NewMockIPDetector("203.0.113.1"))
+
+	if _, err := service.UpdateIP(context.Background()); err != nil {
+		t.Fatalf("Expected update to succeed after retries, got %v", err)
+	}
+
+	attempts := provider.CallsForMethod("UpdateRecord")
+	if len(attempts) != 3 {
+		t.Fatalf("Expected 3 update attempts, got %d", len(attempts))
+	}
+	if attempts[0].Err == nil || attempts[1].Err == nil || attempts[2].Err != nil {
+		t.Errorf("Expected two failed attempts and a successful one, got %+v", attempts)
+	}
+	if got := provider.GetRecords()["example.com:A"]; got != "203.0.113.1" {
+		t.Errorf("Expected record to be updated, got %q", got)
+	}
+}
+
+func TestServiceTimesOutSlowProviderThroughExecutor(t *testing.T) {
+	exec := executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewFixedDelayStrategy(2, time.Millisecond)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(10*time.Millisecond)),
+	)
+	provider := NewMockProvider("slow").WithLatency(time.Second).WithExecutor(exec)
+	// Forced, so the slow record lookup outside the executor is skipped
+	service := ddns.NewServiceWithIPDetector(provider, ddns.Config{Domain: "example.com", RecordType: "A", ForceUpdate: true}, NewMockIPDetector("203.0.113.1"))
+
+	start := time.Now()
+	_, err := service.UpdateIP(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected attempts to time out, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected latency to be cut short by the attempt timeout, took %s", elapsed)
+	}
+	if attempts := provider.CallsForMethod("UpdateRecord"); len(attempts) != 2 {
+		t.Errorf("Expected 2 timed out attempts, got %d", len(attempts))
+	}
+}
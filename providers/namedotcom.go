@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const nameDotComBaseURL = "https://api.name.com/v4"
+
+// nameDotComMinTTL is the lowest TTL Name.com accepts for a record
+const nameDotComMinTTL = 300
+
+// NameDotComProvider implements the DDNS Provider interface for Name.com
+type NameDotComProvider struct {
+	username   string
+	apiToken   string
+	zone       string
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+}
+
+// NameDotComConfig holds Name.com-specific configuration
+type NameDotComConfig struct {
+	Username   string
+	APIToken   string
+	Zone       string             // Registered domain holding the records; derived from the record name when empty
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// nameDotComRecord represents a DNS record in the Name.com API
+// Host is relative to the domain and empty for the apex
+type nameDotComRecord struct {
+	ID     int    `json:"id,omitempty"`
+	Host   string `json:"host"`
+	Type   string `json:"type"`
+	Answer string `json:"answer"`
+	TTL    int    `json:"ttl,omitempty"`
+
+	// Priority is the SRV priority; the answer holds the remaining "weight port target"
+	Priority int `json:"priority,omitempty"`
+}
+
+// nameDotComErrorResponse represents Name.com's error envelope
+type nameDotComErrorResponse struct {
+	Message string `json:"message"`
+	Details string `json:"details"`
+}
+
+// Error formats the message and details into a single message
+func (e *nameDotComErrorResponse) Error() string {
+	if e.Details != "" {
+		return fmt.Sprintf("Name.com API error: %s: %s", e.Message, e.Details)
+	}
+	return fmt.Sprintf("Name.com API error: %s", e.Message)
+}
+
+// NewNameDotComProvider creates a new Name.com DDNS provider
+func NewNameDotComProvider(config NameDotComConfig) *NameDotComProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &NameDotComProvider{
+		username:   config.Username,
+		apiToken:   config.APIToken,
+		zone:       config.Zone,
+		baseURL:    nameDotComBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+		executor:   exec,
+	}
+}
+
+// UpdateRecord updates a DNS record in Name.com, creating it if it does not exist yet
+func (n *NameDotComProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "UpdateRecord")
+
+	zone, host := n.splitDomain(req.Domain)
+
+	record := nameDotComRecord{
+		Host:   host,
+		Type:   req.RecordType,
+		Answer: req.Value,
+	}
+	if req.TTL >= nameDotComMinTTL {
+		record.TTL = req.TTL
+	}
+	if req.RecordType == "SRV" {
+		if req.SRVValue == nil {
+			return nil, fmt.Errorf("SRV records require an SRV value")
+		}
+		if err := req.SRVValue.Validate(); err != nil {
+			return nil, err
+		}
+		record.Priority = req.SRVValue.Priority
+		record.Answer = fmt.Sprintf("%d %d %s", req.SRVValue.Weight, req.SRVValue.Port, req.SRVValue.Target)
+	}
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		existing, err := n.findRecord(taskCtx, zone, host, req.RecordType)
+		if err != nil {
+			return nil, err
+		}
+
+		var result nameDotComRecord
+		if existing != nil {
+			path := fmt.Sprintf("/domains/%s/records/%d", url.PathEscape(zone), existing.ID)
+			err = n.do(taskCtx, "PUT", path, record, &result)
+		} else {
+			path := fmt.Sprintf("/domains/%s/records", url.PathEscape(zone))
+			err = n.do(taskCtx, "POST", path, record, &result)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "Name.com record updated successfully",
+			RecordID:  fmt.Sprintf("%d", result.ID),
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(n.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value
+func (n *NameDotComProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "GetCurrentRecord")
+
+	zone, host := n.splitDomain(domain)
+
+	task := func(taskCtx context.Context) (*nameDotComRecord, error) {
+		return n.findRecord(taskCtx, zone, host, recordType)
+	}
+
+	record, err := executor.ExecuteSimple(n.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("Name.com %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	if recordType == "SRV" {
+		return fmt.Sprintf("%d %s", record.Priority, record.Answer), nil
+	}
+	return record.Answer, nil
+}
+
+// ValidateCredentials checks if the Name.com username and token are valid
+func (n *NameDotComProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		return nil, n.do(taskCtx, "GET", "/hello", nil, nil)
+	}
+
+	_, err = executor.ExecuteSimple(n.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (n *NameDotComProvider) GetProviderName() string {
+	return "namedotcom"
+}
+
+// Capabilities reports the record types Name.com supports
+func (n *NameDotComProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("namedotcom")
+}
+
+// splitDomain returns the zone and the host relative to it, empty for the apex
+func (n *NameDotComProvider) splitDomain(fqdn string) (string, string) {
+	if n.zone == "" {
+		return splitZone(fqdn)
+	}
+	return n.zone, relativeName(fqdn, n.zone)
+}
+
+// findRecord looks up a record by host and type, returning nil if none exists
+func (n *NameDotComProvider) findRecord(ctx context.Context, zone, host, recordType string) (*nameDotComRecord, error) {
+	// Name.com reports the next page number, or omits it on the last page
+	for page := 1; page != 0; {
+		var list struct {
+			Records  []nameDotComRecord `json:"records"`
+			NextPage int                `json:"nextPage"`
+		}
+		path := fmt.Sprintf("/domains/%s/records?page=%d", url.PathEscape(zone), page)
+		if err := n.do(ctx, "GET", path, nil, &list); err != nil {
+			return nil, err
+		}
+
+		if record := findRecordByNameType(list.Records, host, recordType, nameDotComRecordFields); record != nil {
+			return record, nil
+		}
+
+		page = list.NextPage
+	}
+
+	return nil, nil
+}
+
+// nameDotComRecordFields returns the host and type used to match Name.com records
+func nameDotComRecordFields(r nameDotComRecord) (string, string) {
+	return r.Host, r.Type
+}
+
+// do performs an authenticated Name.com API request, decoding the response into out if non-nil
+func (n *NameDotComProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.SetBasicAuth(n.username, n.apiToken)
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if err := parseNameDotComError(resp, data); err != nil {
+		return err
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseNameDotComError returns the API error for a non-2xx response, or nil on success
+func parseNameDotComError(resp *http.Response, data []byte) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	var apiErr nameDotComErrorResponse
+	if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Message != "" {
+		return httpStatusError(resp, &apiErr)
+	}
+	return httpStatusError(resp, nil)
+}
+
+// registerNameDotCom adds the Name.com provider to the factory
+func registerNameDotCom(f *Factory) {
+	f.Register("namedotcom", func(config ddns.Config) (ddns.Provider, error) {
+		if config.Username == "" {
+			return nil, fmt.Errorf("namedotcom provider requires a username")
+		}
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("namedotcom provider requires API key (API token)")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNameDotComProvider(NameDotComConfig{
+			Username:   config.Username,
+			APIToken:   config.APIKey,
+			Zone:       config.ZoneID,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}), nil
+	})
+}
@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestNameDotComUpdateRecordApexAndSubdomain(t *testing.T) {
+	var created, updated []nameDotComRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "alice" || token != "secret" {
+			t.Errorf("Expected basic auth alice/secret, got %q/%q", user, token)
+		}
+
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/domains/example.com/records":
+			fmt.Fprint(w, `{"records":[{"id":7,"host":"","type":"A","answer":"203.0.113.1"}]}`)
+		case r.Method == "PUT" && r.URL.Path == "/domains/example.com/records/7":
+			var record nameDotComRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			updated = append(updated, record)
+			fmt.Fprint(w, `{"id":7}`)
+		case r.Method == "POST" && r.URL.Path == "/domains/example.com/records":
+			var record nameDotComRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			created = append(created, record)
+			fmt.Fprint(w, `{"id":8}`)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewNameDotComProvider(NameDotComConfig{Username: "alice", APIToken: "secret"})
+	provider.baseURL = server.URL
+	ctx := context.Background()
+
+	// The apex record exists with an empty host and is updated in place
+	if _, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.2"}); err != nil {
+		t.Fatalf("Expected no error updating apex, got %v", err)
+	}
+	if len(updated) != 1 || updated[0].Host != "" || updated[0].Answer != "203.0.113.2" {
+		t.Errorf("Expected apex record to be updated with empty host, got %+v", updated)
+	}
+
+	// The subdomain record doesn't exist yet and is created with a relative host
+	resp, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.3"})
+	if err != nil {
+		t.Fatalf("Expected no error creating subdomain, got %v", err)
+	}
+	if len(created) != 1 || created[0].Host != "home" || created[0].Type != "A" {
+		t.Errorf("Expected home A record to be created, got %+v", created)
+	}
+	if resp.RecordID != "8" {
+		t.Errorf("Expected record ID 8, got %s", resp.RecordID)
+	}
+}
+
+func TestNameDotComUpdateRecordSRV(t *testing.T) {
+	var created []nameDotComRecord
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"records":[]}`)
+		case "POST":
+			var record nameDotComRecord
+			json.NewDecoder(r.Body).Decode(&record)
+			created = append(created, record)
+			fmt.Fprint(w, `{"id":9}`)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewNameDotComProvider(NameDotComConfig{Username: "alice", APIToken: "secret"})
+	provider.baseURL = server.URL
+	ctx := context.Background()
+
+	req := ddns.UpdateRequest{
+		Domain:     "_sip._udp.example.com",
+		RecordType: "SRV",
+		SRVValue:   &ddns.SRVValue{Priority: 10, Weight: 5, Port: 5060, Target: "sip.example.com"},
+	}
+	if _, err := provider.UpdateRecord(ctx, req); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(created) != 1 || created[0].Host != "_sip._udp" || created[0].Priority != 10 || created[0].Answer != "5 5060 sip.example.com" {
+		t.Errorf("Expected SRV record with priority split from the answer, got %+v", created)
+	}
+
+	req.SRVValue = &ddns.SRVValue{Port: 70000, Target: "sip.example.com"}
+	if _, err := provider.UpdateRecord(ctx, req); err == nil {
+		t.Error("Expected error for out-of-range SRV port")
+	}
+	if len(created) != 1 {
+		t.Errorf("Expected no request for an invalid SRV value, got %d records", len(created))
+	}
+}
+
+func TestNameDotComValidateCredentialsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/hello" {
+			t.Errorf("Expected /hello, got %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"message":"Unauthenticated","details":"invalid token"}`)
+	}))
+	defer server.Close()
+
+	provider := NewNameDotComProvider(NameDotComConfig{Username: "alice", APIToken: "wrong"})
+	provider.baseURL = server.URL
+	provider.executor = executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	err := provider.ValidateCredentials(context.Background())
+	if err == nil || err.Error() != "namedotcom ValidateCredentials: Name.com API error: Unauthenticated: invalid token" {
+		t.Errorf("Expected parsed Name.com error, got %v", err)
+	}
+}
@@ -0,0 +1,277 @@
+package providers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const nameSiloBaseURL = "https://www.namesilo.com/api"
+
+// nameSiloSuccessCode is the reply code NameSilo returns for successful operations
+const nameSiloSuccessCode = "300"
+
+// nameSiloMinTTL is the lowest TTL NameSilo accepts for a resource record
+const nameSiloMinTTL = 3600
+
+// NameSiloProvider implements the DDNS Provider interface for NameSilo
+type NameSiloProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+}
+
+// NameSiloConfig holds NameSilo-specific configuration
+type NameSiloConfig struct {
+	APIKey     string
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+}
+
+// nameSiloResponse represents the XML envelope returned by the NameSilo API
+type nameSiloResponse struct {
+	Reply struct {
+		Code            string                   `xml:"code"`
+		Detail          string                   `xml:"detail"`
+		RecordID        string                   `xml:"record_id"`
+		ResourceRecords []nameSiloResourceRecord `xml:"resource_record"`
+		Domains         []string                 `xml:"domains>domain"`
+	} `xml:"reply"`
+}
+
+// nameSiloResourceRecord represents a single DNS record in a dnsListRecords reply
+type nameSiloResourceRecord struct {
+	RecordID string `xml:"record_id"`
+	Type     string `xml:"type"`
+	Host     string `xml:"host"`
+	Value    string `xml:"value"`
+	TTL      int    `xml:"ttl"`
+}
+
+// NewNameSiloProvider creates a new NameSilo DDNS provider
+func NewNameSiloProvider(config NameSiloConfig) *NameSiloProvider {
+	// Set up executor with retry logic for API calls
+	exec := executorOrDefault(config.Executor)
+
+	return &NameSiloProvider{
+		apiKey:     config.APIKey,
+		baseURL:    nameSiloBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+		executor:   exec,
+	}
+}
+
+// UpdateRecord updates a DNS record in NameSilo, creating it if it does not exist yet
+func (n *NameSiloProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "UpdateRecord")
+
+	zone, err := n.findZone(ctx, req.Domain)
+	if err != nil {
+		return nil, err
+	}
+	host := relativeName(req.Domain, zone)
+
+	record, err := n.findRecord(ctx, zone, req.Domain, req.RecordType)
+	if err != nil {
+		return nil, err
+	}
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		params := url.Values{}
+		params.Set("domain", zone)
+		params.Set("rrhost", host)
+		params.Set("rrvalue", req.Value)
+		if req.TTL >= nameSiloMinTTL {
+			params.Set("rrttl", strconv.Itoa(req.TTL))
+		}
+
+		operation := "dnsAddRecord"
+		if record != nil {
+			operation = "dnsUpdateRecord"
+			params.Set("rrid", record.RecordID)
+		} else {
+			params.Set("rrtype", req.RecordType)
+		}
+
+		reply, err := n.call(taskCtx, operation, params)
+		if err != nil {
+			return nil, err
+		}
+
+		recordID := reply.Reply.RecordID
+		if recordID == "" && record != nil {
+			recordID = record.RecordID
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "NameSilo record updated successfully",
+			RecordID:  recordID,
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(n.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value from dnsListRecords
+func (n *NameSiloProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "GetCurrentRecord")
+
+	zone, err := n.findZone(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+
+	record, err := n.findRecord(ctx, zone, domain, recordType)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("NameSilo %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return record.Value, nil
+}
+
+// ValidateCredentials checks if the NameSilo API key is valid
+func (n *NameSiloProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "ValidateCredentials")
+
+	_, err = n.listDomains(ctx)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (n *NameSiloProvider) GetProviderName() string {
+	return "namesilo"
+}
+
+// Capabilities reports the record types NameSilo supports
+func (n *NameSiloProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("namesilo")
+}
+
+// listDomains returns the domains registered in the account
+func (n *NameSiloProvider) listDomains(ctx context.Context) ([]string, error) {
+	task := func(taskCtx context.Context) ([]string, error) {
+		reply, err := n.call(taskCtx, "listDomains", url.Values{})
+		if err != nil {
+			return nil, err
+		}
+		return reply.Reply.Domains, nil
+	}
+
+	return executor.ExecuteSimple(n.executor, ctx, task)
+}
+
+// findZone returns the longest domain in the account that fqdn falls under, e.g. example.co.uk
+func (n *NameSiloProvider) findZone(ctx context.Context, fqdn string) (string, error) {
+	domains, err := n.listDomains(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	best := closestZone(fqdn, domains, func(domain string) string { return domain })
+	if best == nil {
+		return "", fmt.Errorf("NameSilo %w: no domain in the account for %s", ddns.ErrNotFound, fqdn)
+	}
+
+	return *best, nil
+}
+
+// findRecord looks up the record matching the domain and type, returning nil if none exists
+func (n *NameSiloProvider) findRecord(ctx context.Context, zone, domain, recordType string) (*nameSiloResourceRecord, error) {
+	task := func(taskCtx context.Context) (*nameSiloResourceRecord, error) {
+		params := url.Values{}
+		params.Set("domain", zone)
+
+		reply, err := n.call(taskCtx, "dnsListRecords", params)
+		if err != nil {
+			return nil, err
+		}
+
+		// NameSilo reports hosts as fully qualified names
+		return findRecordByNameType(reply.Reply.ResourceRecords, domain, recordType, func(r nameSiloResourceRecord) (string, string) {
+			return r.Host, r.Type
+		}), nil
+	}
+
+	return executor.ExecuteSimple(n.executor, ctx, task)
+}
+
+// call performs a NameSilo API operation and checks the reply code
+func (n *NameSiloProvider) call(ctx context.Context, operation string, params url.Values) (*nameSiloResponse, error) {
+	params.Set("version", "1")
+	params.Set("type", "xml")
+	params.Set("key", n.apiKey)
+
+	callURL := fmt.Sprintf("%s/%s?%s", n.baseURL, operation, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", callURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := rateLimitError(resp); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, httpStatusError(resp, nil)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var reply nameSiloResponse
+	if err := xml.Unmarshal(body, &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if reply.Reply.Code != nameSiloSuccessCode {
+		return nil, fmt.Errorf("NameSilo %s failed with code %s: %s", operation, reply.Reply.Code, reply.Reply.Detail)
+	}
+
+	return &reply, nil
+}
+
+// registerNameSilo adds the NameSilo provider to the factory
+func registerNameSilo(f *Factory) {
+	f.Register("namesilo", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("namesilo provider requires API key")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNameSiloProvider(NameSiloConfig{
+			APIKey:     config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}), nil
+	})
+}
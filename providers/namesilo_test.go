@@ -0,0 +1,175 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+// nameSiloListReply is a dnsListRecords reply holding an A and a TXT record of home.example.com
+const nameSiloListReply = `<namesilo><reply><code>300</code><detail>success</detail>
+<resource_record><record_id>a1</record_id><type>A</type><host>home.example.com</host><value>198.51.100.1</value><ttl>7207</ttl></resource_record>
+<resource_record><record_id>t1</record_id><type>TXT</type><host>home.example.com</host><value>hello</value><ttl>7207</ttl></resource_record>
+</reply></namesilo>`
+
+// nameSiloDomainsReply is a listDomains reply holding example.com and example.co.uk
+const nameSiloDomainsReply = `<namesilo><reply><code>300</code><detail>success</detail>
+<domains><domain>example.com</domain><domain>example.co.uk</domain></domains>
+</reply></namesilo>`
+
+// newNameSiloTestServer answers NameSilo operations with replies[operation] and records every call's query
+func newNameSiloTestServer(t *testing.T, replies map[string]string, calls *[]url.Values) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("key") != "secret" {
+			fmt.Fprint(w, `<namesilo><reply><code>110</code><detail>Invalid API Key</detail></reply></namesilo>`)
+			return
+		}
+
+		operation := strings.TrimPrefix(r.URL.Path, "/")
+		query.Set("operation", operation)
+		*calls = append(*calls, query)
+
+		reply, ok := replies[operation]
+		if !ok {
+			t.Errorf("Unexpected operation %s", operation)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, reply)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newTestNameSiloProvider returns a provider talking to the test server without retrying
+func newTestNameSiloProvider(server *httptest.Server, apiKey string) *NameSiloProvider {
+	provider := NewNameSiloProvider(NameSiloConfig{
+		APIKey:   apiKey,
+		Executor: executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy())),
+	})
+	provider.baseURL = server.URL
+	return provider
+}
+
+func TestNameSiloUpdateRecord(t *testing.T) {
+	var calls []url.Values
+	server := newNameSiloTestServer(t, map[string]string{
+		"listDomains":     nameSiloDomainsReply,
+		"dnsListRecords":  nameSiloListReply,
+		"dnsUpdateRecord": `<namesilo><reply><code>300</code><detail>success</detail><record_id>a2</record_id></reply></namesilo>`,
+		"dnsAddRecord":    `<namesilo><reply><code>300</code><detail>success</detail><record_id>n1</record_id></reply></namesilo>`,
+	}, &calls)
+	provider := newTestNameSiloProvider(server, "secret")
+	ctx := context.Background()
+
+	// An existing record is updated by ID; a TTL below NameSilo's minimum is left out
+	resp, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "home.example.com", RecordType: "A", Value: "203.0.113.1", TTL: 300})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.RecordID != "a2" {
+		t.Errorf("Expected the updated record ID a2, got %s", resp.RecordID)
+	}
+	update := calls[len(calls)-1]
+	if update.Get("operation") != "dnsUpdateRecord" || update.Get("rrid") != "a1" || update.Get("domain") != "example.com" ||
+		update.Get("rrhost") != "home" || update.Get("rrvalue") != "203.0.113.1" || update.Has("rrttl") {
+		t.Errorf("Unexpected update call %v", update)
+	}
+
+	// A missing record is added with its type
+	resp, err = provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "home.example.com", RecordType: "AAAA", Value: "2001:db8::1", TTL: 7200})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.RecordID != "n1" {
+		t.Errorf("Expected the added record ID n1, got %s", resp.RecordID)
+	}
+	add := calls[len(calls)-1]
+	if add.Get("operation") != "dnsAddRecord" || add.Get("rrtype") != "AAAA" || add.Get("rrttl") != "7200" || add.Has("rrid") {
+		t.Errorf("Unexpected add call %v", add)
+	}
+}
+
+func TestNameSiloGetCurrentRecord(t *testing.T) {
+	var calls []url.Values
+	server := newNameSiloTestServer(t, map[string]string{"listDomains": nameSiloDomainsReply, "dnsListRecords": nameSiloListReply}, &calls)
+	provider := newTestNameSiloProvider(server, "secret")
+	ctx := context.Background()
+
+	value, err := provider.GetCurrentRecord(ctx, "home.example.com", "TXT")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if value != "hello" {
+		t.Errorf("Expected hello, got %s", value)
+	}
+
+	if _, err := provider.GetCurrentRecord(ctx, "home.example.com", "AAAA"); !errors.Is(err, ddns.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing record, got %v", err)
+	}
+	if _, err := provider.GetCurrentRecord(ctx, "home.example.net", "A"); !errors.Is(err, ddns.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a domain outside the account, got %v", err)
+	}
+}
+
+func TestNameSiloFindsZoneUnderPublicSuffix(t *testing.T) {
+	var calls []url.Values
+	server := newNameSiloTestServer(t, map[string]string{
+		"listDomains":    nameSiloDomainsReply,
+		"dnsListRecords": `<namesilo><reply><code>300</code><detail>success</detail></reply></namesilo>`,
+		"dnsAddRecord":   `<namesilo><reply><code>300</code><detail>success</detail><record_id>n1</record_id></reply></namesilo>`,
+	}, &calls)
+	provider := newTestNameSiloProvider(server, "secret")
+
+	if _, err := provider.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "www.example.co.uk", RecordType: "A", Value: "203.0.113.1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	add := calls[len(calls)-1]
+	if add.Get("domain") != "example.co.uk" || add.Get("rrhost") != "www" {
+		t.Errorf("Expected www under example.co.uk, got %v", add)
+	}
+}
+
+func TestNameSiloValidateCredentials(t *testing.T) {
+	var calls []url.Values
+	server := newNameSiloTestServer(t, map[string]string{
+		"listDomains": `<namesilo><reply><code>300</code><detail>success</detail></reply></namesilo>`,
+	}, &calls)
+
+	if err := newTestNameSiloProvider(server, "secret").ValidateCredentials(context.Background()); err != nil {
+		t.Errorf("Expected valid credentials, got %v", err)
+	}
+
+	err := newTestNameSiloProvider(server, "wrong").ValidateCredentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "code 110: Invalid API Key") {
+		t.Errorf("Expected the NameSilo reply code and detail, got %v", err)
+	}
+	if _, ok := ddns.IsProviderError(err); !ok {
+		t.Errorf("Expected a provider error, got %T", err)
+	}
+}
+
+func TestNameSiloHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	_, err := newTestNameSiloProvider(server, "secret").GetCurrentRecord(context.Background(), "home.example.com", "A")
+	providerErr, ok := ddns.IsProviderError(err)
+	if !ok {
+		t.Fatalf("Expected a provider error, got %v", err)
+	}
+	if providerErr.StatusCode != http.StatusServiceUnavailable || !providerErr.Retryable {
+		t.Errorf("Expected a retryable 503, got status %d retryable %t", providerErr.StatusCode, providerErr.Retryable)
+	}
+}
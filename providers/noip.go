@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const noIPUpdateURL = "https://dynupdate.no-ip.com/nic/update"
+
+// noIPBackoff is how long No-IP asks clients to stop updating after a 911 response
+const noIPBackoff = 30 * time.Minute
+
+// NoIPProvider implements the DDNS Provider interface for No-IP (noip.com)
+type NoIPProvider struct {
+	hostname   string
+	client     *DynDNS2Client
+	nameserver string
+}
+
+// NoIPConfig holds No-IP-specific configuration
+type NoIPConfig struct {
+	Hostname   string             // Record used for credential validation
+	Username   string             // Account username or email, or a DDNS key's username
+	Password   string             // Account password, or the DDNS key's password
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; a default retry policy is used when nil
+	Nameserver string             // Optional nameserver records are read from; the system resolver is used when empty
+}
+
+// NewNoIPProvider creates a new No-IP DDNS provider
+func NewNoIPProvider(config NoIPConfig) *NoIPProvider {
+	return &NoIPProvider{
+		hostname: config.Hostname,
+		client: &DynDNS2Client{
+			name:       "No-IP",
+			updateURL:  noIPUpdateURL,
+			username:   config.Username,
+			password:   config.Password,
+			httpClient: httpClientOrDefault(config.HTTPClient),
+			executor:   executorOrDefaultRetrying(config.Executor, isRetryableDynDNS2Error),
+			// 911 means No-IP is having problems and clients must wait before trying again
+			backoff: map[string]time.Duration{"911": noIPBackoff},
+		},
+		nameserver: config.Nameserver,
+	}
+}
+
+// UpdateRecord updates the hostname through No-IP's DynDNS v2 endpoint
+func (n *NoIPProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "UpdateRecord")
+
+	if req.RecordType != "A" {
+		return nil, fmt.Errorf("No-IP dynamic DNS does not support %s records, only A", req.RecordType)
+	}
+
+	result, err := n.client.Update(ctx, req.Domain, req.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	message := "No-IP hostname updated successfully"
+	if !result.Changed {
+		message = "No-IP hostname already up to date"
+	}
+
+	return &ddns.UpdateResponse{
+		Success:   true,
+		Message:   message,
+		RecordID:  req.Domain, // No-IP doesn't expose record IDs through the update endpoint
+		UpdatedAt: time.Now(),
+		Unchanged: !result.Changed,
+	}, nil
+}
+
+// GetCurrentRecord resolves the hostname through DNS since No-IP's update endpoint can't read records
+func (n *NoIPProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "GetCurrentRecord")
+
+	return ResolverBackedGetCurrentRecord(ctx, domain, recordType, n.nameserver)
+}
+
+// ValidateCredentials checks that the hostname resolves without contacting No-IP
+// No-IP has no read-only endpoint and treats repeated nochg updates as abuse, so bad credentials
+// are only reported (as badauth) by the first real update
+func (n *NoIPProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, n.GetProviderName(), "ValidateCredentials")
+
+	_, err = n.GetCurrentRecord(ctx, n.hostname, "A")
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (n *NoIPProvider) GetProviderName() string {
+	return "noip"
+}
+
+// Capabilities reports the record types No-IP supports; records are read through DNS
+func (n *NoIPProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("noip")
+}
+
+// registerNoIP adds the No-IP provider to the factory
+func registerNoIP(f *Factory) {
+	f.Register("noip", func(config ddns.Config) (ddns.Provider, error) {
+		if config.Username == "" {
+			return nil, fmt.Errorf("noip provider requires a username")
+		}
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("noip provider requires API key (account or DDNS key password)")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewNoIPProvider(NoIPConfig{
+			Hostname:   config.Domain,
+			Username:   config.Username,
+			Password:   config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+			Nameserver: config.Nameserver,
+		}), nil
+	})
+}
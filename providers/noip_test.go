@@ -0,0 +1,132 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestNoIPUpdateRecord(t *testing.T) {
+	var requests int32
+	response := "good 203.0.113.1"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "alice" || password != "secret" {
+			t.Errorf("Expected Basic auth for alice, got %q %q", username, password)
+		}
+		if query := r.URL.Query(); query.Get("hostname") != "home.ddns.net" || query.Get("myip") != "203.0.113.1" {
+			t.Errorf("Unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, response)
+	}))
+	defer server.Close()
+
+	provider := NewNoIPProvider(NoIPConfig{Hostname: "home.ddns.net", Username: "alice", Password: "secret"})
+	provider.client.updateURL = server.URL
+	req := ddns.UpdateRequest{Domain: "home.ddns.net", RecordType: "A", Value: "203.0.113.1"}
+
+	resp, err := provider.UpdateRecord(context.Background(), req)
+	if err != nil || !resp.Success || resp.Unchanged {
+		t.Fatalf("Expected a changed record, got %+v and %v", resp, err)
+	}
+
+	response = "nochg 203.0.113.1"
+	resp, err = provider.UpdateRecord(context.Background(), req)
+	if err != nil || !resp.Success || !resp.Unchanged {
+		t.Fatalf("Expected an unchanged record, got %+v and %v", resp, err)
+	}
+
+	// nohost and !donator are permanent and must not be retried
+	for _, code := range []string{"nohost", "!donator"} {
+		response = code
+		atomic.StoreInt32(&requests, 0)
+		_, err := provider.UpdateRecord(context.Background(), req)
+		if providerErr, ok := ddns.IsProviderError(err); !ok || providerErr.Retryable {
+			t.Errorf("%s: expected a non-retryable provider error, got %v", code, err)
+		}
+		if got := atomic.LoadInt32(&requests); got != 1 {
+			t.Errorf("%s: expected no retries, got %d requests", code, got)
+		}
+	}
+	response = "nohost"
+	if _, err := provider.UpdateRecord(context.Background(), req); !errors.Is(err, ddns.ErrNotFound) {
+		t.Errorf("Expected nohost to be ErrNotFound, got %v", err)
+	}
+}
+
+func TestNoIPBacksOffAfter911(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, "911")
+	}))
+	defer server.Close()
+
+	// The default executor retries, but must not wait out the backoff inside a single update
+	provider := NewNoIPProvider(NoIPConfig{Username: "alice", Password: "secret"})
+	provider.client.updateURL = server.URL
+	req := ddns.UpdateRequest{Domain: "home.ddns.net", RecordType: "A", Value: "203.0.113.1"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	_, err := provider.UpdateRecord(ctx, req)
+	var retryAfter *executor.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.RetryAfter != 30*time.Minute {
+		t.Fatalf("Expected a 30 minute Retry-After error, got %v", err)
+	}
+	var protocolErr *DynDNS2Error
+	if !errors.As(err, &protocolErr) || protocolErr.Code != "911" {
+		t.Errorf("Expected the 911 response as the cause, got %v", err)
+	}
+	if providerErr, ok := ddns.IsProviderError(err); !ok || providerErr.Retryable {
+		t.Errorf("Expected a non-retryable provider error, got %v", err)
+	}
+
+	// Later updates wait out the backoff without contacting No-IP
+	_, err = provider.UpdateRecord(context.Background(), req)
+	if !errors.As(err, &retryAfter) || retryAfter.RetryAfter <= 29*time.Minute {
+		t.Errorf("Expected the remaining backoff, got %v", err)
+	}
+	if !errors.As(err, &protocolErr) || protocolErr.Code != "911" {
+		t.Errorf("Expected the backoff error to keep the 911 cause, got %v", err)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("Expected a single request during the backoff, got %d", got)
+	}
+}
+
+func TestNoIPValidateCredentialsDoesNotUpdate(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprint(w, "nochg 203.0.113.1")
+	}))
+	defer server.Close()
+
+	nameserver := startMockDNSServer(t, map[string][]string{"home.ddns.net.": {"203.0.113.1"}})
+
+	provider := NewNoIPProvider(NoIPConfig{Hostname: "home.ddns.net", Username: "alice", Password: "secret", Nameserver: nameserver})
+	provider.client.updateURL = server.URL
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Fatalf("Expected validation to succeed, got %v", err)
+	}
+
+	// An unresolvable hostname is reported instead of letting No-IP fall back to the source address
+	provider.hostname = "missing.ddns.net"
+	if err := provider.ValidateCredentials(context.Background()); err == nil {
+		t.Error("Expected an error for an unresolvable hostname")
+	}
+
+	if got := atomic.LoadInt32(&requests); got != 0 {
+		t.Errorf("Expected no update requests, got %d", got)
+	}
+}
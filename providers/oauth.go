@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// defaultOAuthRefreshThreshold is how long before expiry an OAuth token is refreshed by default
+const defaultOAuthRefreshThreshold = time.Minute
+
+// OAuthToken is an OAuth2 access token and when it expires
+type OAuthToken struct {
+	AccessToken string
+	Expiry      time.Time // Zero if the token doesn't expire
+}
+
+// TokenSource supplies OAuth2 access tokens
+// It has the shape of golang.org/x/oauth2.TokenSource, which a small adapter can satisfy
+type TokenSource interface {
+	Token() (*OAuthToken, error)
+}
+
+// TokenSettable is implemented by providers whose API token can be replaced at runtime
+type TokenSettable interface {
+	SetToken(token string)
+}
+
+// OAuthProvider wraps a provider authenticating with short-lived OAuth2 tokens
+// It fetches a fresh token from its source before each call once the current one is about to expire
+type OAuthProvider struct {
+	inner            ddns.Provider
+	source           TokenSource
+	refreshThreshold time.Duration
+	now              func() time.Time
+
+	mu    sync.Mutex
+	token *OAuthToken
+}
+
+// NewOAuthProvider creates a provider that keeps inner supplied with tokens from source
+// inner must implement TokenSettable, or every call fails
+func NewOAuthProvider(inner ddns.Provider, source TokenSource) *OAuthProvider {
+	return &OAuthProvider{
+		inner:            inner,
+		source:           source,
+		refreshThreshold: defaultOAuthRefreshThreshold,
+		now:              time.Now,
+	}
+}
+
+// WithRefreshThreshold sets how long before expiry the token is refreshed
+func (o *OAuthProvider) WithRefreshThreshold(threshold time.Duration) *OAuthProvider {
+	o.refreshThreshold = threshold
+	return o
+}
+
+// UpdateRecord refreshes the token if needed and delegates to the inner provider
+func (o *OAuthProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, o.GetProviderName(), "UpdateRecord")
+
+	if err := o.refreshToken(); err != nil {
+		return nil, err
+	}
+	return o.inner.UpdateRecord(ctx, req)
+}
+
+// GetCurrentRecord refreshes the token if needed and delegates to the inner provider
+func (o *OAuthProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, o.GetProviderName(), "GetCurrentRecord")
+
+	if err := o.refreshToken(); err != nil {
+		return "", err
+	}
+	return o.inner.GetCurrentRecord(ctx, domain, recordType)
+}
+
+// ValidateCredentials fetches a token and checks it with the inner provider
+func (o *OAuthProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, o.GetProviderName(), "ValidateCredentials")
+
+	if err := o.refreshToken(); err != nil {
+		return err
+	}
+	return o.inner.ValidateCredentials(ctx)
+}
+
+// GetProviderName returns the name of the inner provider
+func (o *OAuthProvider) GetProviderName() string {
+	return o.inner.GetProviderName()
+}
+
+// Capabilities returns the capabilities of the inner provider
+func (o *OAuthProvider) Capabilities() ddns.Capabilities {
+	return o.inner.Capabilities()
+}
+
+// refreshToken hands the inner provider a new token unless the current one is still good past the threshold
+func (o *OAuthProvider) refreshToken() error {
+	settable, ok := o.inner.(TokenSettable)
+	if !ok {
+		return fmt.Errorf("%s provider does not accept OAuth tokens", o.inner.GetProviderName())
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != nil && (o.token.Expiry.IsZero() || o.now().Add(o.refreshThreshold).Before(o.token.Expiry)) {
+		return nil
+	}
+
+	token, err := o.source.Token()
+	if err != nil {
+		// Only the sentinel is wrapped, so the error isn't mistaken for joined attempt errors
+		return fmt.Errorf("%w: failed to refresh OAuth token: %v", ddns.ErrAuthFailed, err)
+	}
+	if token == nil || token.AccessToken == "" {
+		return fmt.Errorf("%w: token source returned no access token", ddns.ErrAuthFailed)
+	}
+
+	settable.SetToken(token.AccessToken)
+	o.token = token
+	return nil
+}
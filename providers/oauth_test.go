@@ -0,0 +1,109 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// sequenceTokenSource hands out token-1, token-2, ... each expiring after lifetime
+type sequenceTokenSource struct {
+	now      func() time.Time
+	lifetime time.Duration
+	issued   int
+	err      error
+}
+
+func (s *sequenceTokenSource) Token() (*OAuthToken, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.issued++
+	return &OAuthToken{AccessToken: fmt.Sprintf("token-%d", s.issued), Expiry: s.now().Add(s.lifetime)}, nil
+}
+
+// tokenRecordingProvider records the tokens set on a mock provider
+type tokenRecordingProvider struct {
+	*MockProvider
+	tokens []string
+}
+
+func (p *tokenRecordingProvider) SetToken(token string) {
+	p.tokens = append(p.tokens, token)
+}
+
+func TestOAuthProviderRefreshesNearExpiry(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	inner := &tokenRecordingProvider{MockProvider: NewMockProvider("test")}
+	inner.SetRecord("example.com", "A", "203.0.113.1")
+	source := &sequenceTokenSource{now: clock, lifetime: 10 * time.Minute}
+
+	provider := NewOAuthProvider(inner, source).WithRefreshThreshold(time.Minute)
+	provider.now = clock
+	ctx := context.Background()
+
+	if _, err := provider.GetCurrentRecord(ctx, "example.com", "A"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := provider.UpdateRecord(ctx, ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.2"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(inner.tokens) != 1 {
+		t.Fatalf("Expected the first token to be reused, got %v", inner.tokens)
+	}
+
+	// Within the threshold of expiry the token is replaced before the call
+	now = now.Add(9*time.Minute + 30*time.Second)
+	if _, err := provider.GetCurrentRecord(ctx, "example.com", "A"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(inner.tokens) != 2 || inner.tokens[1] != "token-2" {
+		t.Errorf("Expected a refreshed token, got %v", inner.tokens)
+	}
+}
+
+func TestOAuthProviderErrors(t *testing.T) {
+	source := &sequenceTokenSource{now: time.Now, lifetime: time.Hour, err: errors.New("invalid_grant")}
+	inner := &tokenRecordingProvider{MockProvider: NewMockProvider("test")}
+
+	err := NewOAuthProvider(inner, source).ValidateCredentials(context.Background())
+	if !errors.Is(err, ddns.ErrAuthFailed) {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+	if providerErr, ok := ddns.IsProviderError(err); !ok || providerErr.Retryable {
+		t.Errorf("Expected a non-retryable provider error, got %v", err)
+	}
+
+	source.err = nil
+	if err := NewOAuthProvider(NewMockProvider("test"), source).ValidateCredentials(context.Background()); err == nil {
+		t.Error("Expected an error for a provider that doesn't accept tokens")
+	}
+}
+
+func TestOAuthProviderSetsLinodeToken(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"id":42,"domain":"example.com"}`))
+	}))
+	defer server.Close()
+
+	linode := NewLinodeProvider(LinodeConfig{APIToken: "static", DomainID: 42})
+	linode.baseURL = server.URL
+	provider := NewOAuthProvider(linode, &sequenceTokenSource{now: time.Now, lifetime: time.Hour})
+
+	if err := provider.ValidateCredentials(context.Background()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if authorization != "Bearer token-1" {
+		t.Errorf("Expected the OAuth token to be sent, got %q", authorization)
+	}
+}
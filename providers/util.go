@@ -0,0 +1,14 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// ResolverBackedGetCurrentRecord reads the value DNS serves for a record, for providers without a query API
+// nameserver is a host or host:port to ask directly, such as the provider's authoritative server; an empty
+// nameserver uses the system resolver
+func ResolverBackedGetCurrentRecord(ctx context.Context, domain, recordType, nameserver string) (string, error) {
+	return ddns.NewResolverIPDetector(domain, recordType, nameserver).GetPublicIP(ctx)
+}
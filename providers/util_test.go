@@ -0,0 +1,133 @@
+package providers
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// startMockDNSServer answers A and AAAA queries over UDP from records, keyed by lowercase FQDN
+// Unknown names get an empty answer
+func startMockDNSServer(t *testing.T, records map[string][]string) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := mockDNSReply(buf[:n], records); reply != nil {
+				conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// mockDNSReply builds the response to a single-question DNS query
+func mockDNSReply(query []byte, records map[string][]string) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// Walk the question name to find the type that follows it
+	var labels []string
+	offset := 12
+	for offset < len(query) && query[offset] != 0 {
+		length := int(query[offset])
+		if offset+1+length > len(query) {
+			return nil
+		}
+		labels = append(labels, string(query[offset+1:offset+1+length]))
+		offset += 1 + length
+	}
+	questionEnd := offset + 5
+	if questionEnd > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[offset+1:])
+
+	var answers [][]byte
+	for _, value := range records[strings.ToLower(strings.Join(labels, ".")+".")] {
+		ip := net.ParseIP(value)
+		switch {
+		case qtype == 1 && ip.To4() != nil:
+			answers = append(answers, ip.To4())
+		case qtype == 28 && ip.To4() == nil:
+			answers = append(answers, ip.To16())
+		}
+	}
+
+	reply := append([]byte{}, query[:questionEnd]...)
+	binary.BigEndian.PutUint16(reply[2:], 0x8180) // Response, recursion desired and available
+	binary.BigEndian.PutUint16(reply[6:], uint16(len(answers)))
+	binary.BigEndian.PutUint16(reply[8:], 0)
+	binary.BigEndian.PutUint16(reply[10:], 0)
+	for _, rdata := range answers {
+		// Name pointer to the question, type, class IN, TTL 60 and the address
+		reply = append(reply, 0xc0, 0x0c)
+		reply = binary.BigEndian.AppendUint16(reply, qtype)
+		reply = binary.BigEndian.AppendUint16(reply, 1)
+		reply = binary.BigEndian.AppendUint32(reply, 60)
+		reply = binary.BigEndian.AppendUint16(reply, uint16(len(rdata)))
+		reply = append(reply, rdata...)
+	}
+	return reply
+}
+
+func TestResolverBackedGetCurrentRecord(t *testing.T) {
+	nameserver := startMockDNSServer(t, map[string][]string{
+		"home.example.com.": {"203.0.113.1", "2001:db8::1"},
+	})
+
+	for recordType, want := range map[string]string{"A": "203.0.113.1", "AAAA": "2001:db8::1"} {
+		value, err := ResolverBackedGetCurrentRecord(context.Background(), "home.example.com", recordType, nameserver)
+		if err != nil {
+			t.Fatalf("Expected %s record, got %v", recordType, err)
+		}
+		if value != want {
+			t.Errorf("Expected %s record %s, got %s", recordType, want, value)
+		}
+	}
+
+	if _, err := ResolverBackedGetCurrentRecord(context.Background(), "missing.example.com", "A", nameserver); err == nil {
+		t.Error("Expected error for a name without records")
+	}
+}
+
+func TestResolverBackedProvidersReadThroughNameserver(t *testing.T) {
+	nameserver := startMockDNSServer(t, map[string][]string{
+		"home.example.com.": {"203.0.113.1", "2001:db8::1"},
+		"home.duckdns.org.": {"203.0.113.1", "2001:db8::1"},
+	})
+
+	configs := []ddns.Config{
+		{Provider: "hurricane_electric", Domain: "home.example.com", APIKey: "key", Nameserver: nameserver},
+		{Provider: "noip", Domain: "home.example.com", Username: "user", APIKey: "key", Nameserver: nameserver},
+		{Provider: "duckdns", Domain: "home", APIKey: "c0ffee00-1234-4abc-9def-0123456789ab", Nameserver: nameserver},
+	}
+	for _, config := range configs {
+		provider, err := NewFactory().CreateProvider(config)
+		if err != nil {
+			t.Fatalf("Expected %s provider, got %v", config.Provider, err)
+		}
+
+		value, err := provider.GetCurrentRecord(context.Background(), config.Domain, "AAAA")
+		if err != nil || value != "2001:db8::1" {
+			t.Errorf("Expected %s to read 2001:db8::1 from the nameserver, got %q, %v", config.Provider, value, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// supportedRecordTypes lists the record types each provider can update; nil means any type
+var supportedRecordTypes = map[string][]string{
+	"duckdns":            {"A", "AAAA"},
+	"namesilo":           {"A", "AAAA", "CNAME", "TXT"},
+	"linode":             {"A", "AAAA", "CNAME", "TXT"},
+	"vultr":              {"A", "AAAA", "CNAME", "TXT"},
+	"namedotcom":         {"A", "AAAA", "CNAME", "TXT", "SRV"},
+	"hurricane_electric": {"A", "AAAA"},
+	"dnspod":             {"A", "AAAA", "CNAME", "TXT"},
+	"alidns":             {"A", "AAAA", "CNAME", "TXT"},
+	"dynu":               {"A", "AAAA"},
+	"noip":               {"A"},
+	"file":               {"A", "AAAA"},
+	"mock":               nil,
+}
+
+// ValidateConfig checks that the whole configuration is coherent, reporting every problem found
+func (f *Factory) ValidateConfig(cfg ddns.Config) error {
+	var errs []error
+
+	if err := f.ValidateProviderConfig(cfg); err != nil {
+		errs = append(errs, err)
+	}
+
+	domains := cfg.Domains
+	if len(domains) == 0 {
+		domains = []string{cfg.Domain}
+	}
+	for _, domain := range domains {
+		if err := validateDomainName(domain); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := validateRecordType(cfg.Provider, cfg.RecordType); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, record := range cfg.Records {
+		if err := validateRecordType(cfg.Provider, record.RecordType); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", record.Domain, err))
+		}
+	}
+
+	// SRV records need priority, weight, port and target, which only UpdateRecord's SRVValue carries
+	if strings.EqualFold(cfg.RecordType, "SRV") {
+		errs = append(errs, fmt.Errorf("SRV records can't be configured, use UpdateRecord with an SRVValue instead"))
+	}
+
+	if strings.EqualFold(cfg.RecordType, "CNAME") {
+		if err := validateCNAMETarget(cfg.Target); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if cfg.UpdateInterval <= 0 {
+		errs = append(errs, fmt.Errorf("update interval must be positive, got %s", cfg.UpdateInterval))
+	}
+
+	if cfg.TTL < 0 {
+		errs = append(errs, fmt.Errorf("TTL cannot be negative, got %d", cfg.TTL))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateRecordType checks that the provider can update records of the given type
+func validateRecordType(provider, recordType string) error {
+	if recordType == "" {
+		return fmt.Errorf("record type is required")
+	}
+
+	types, known := supportedRecordTypes[provider]
+	if !known || types == nil {
+		// Unknown providers are reported by ValidateProviderConfig
+		return nil
+	}
+
+	if supportsRecordType(provider, recordType) {
+		return nil
+	}
+
+	return fmt.Errorf("%s provider does not support %s records (supported: %s)", provider, recordType, strings.Join(types, ", "))
+}
+
+// validateCNAMETarget checks that the CNAME target is a fully qualified hostname
+func validateCNAMETarget(target string) error {
+	if target == "" {
+		return fmt.Errorf("CNAME records require a target hostname")
+	}
+
+	if err := validateDomainName(target); err != nil {
+		return fmt.Errorf("invalid CNAME target: %w", err)
+	}
+
+	if !strings.Contains(strings.TrimSuffix(target, "."), ".") {
+		return fmt.Errorf("invalid CNAME target: %q is not a fully qualified name", target)
+	}
+
+	return nil
+}
+
+// validateDomainName checks that the domain is a syntactically valid DNS name
+func validateDomainName(domain string) error {
+	name := strings.TrimSuffix(domain, ".")
+	if name == "" {
+		return fmt.Errorf("domain is required")
+	}
+
+	if len(name) > 253 {
+		return fmt.Errorf("domain %q is longer than 253 characters", domain)
+	}
+
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return fmt.Errorf("domain %q has a label that is empty or longer than 63 characters", domain)
+		}
+
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("domain %q has a label starting or ending with a hyphen", domain)
+		}
+
+		for _, c := range label {
+			// Underscores are allowed for service labels such as _acme-challenge
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+				return fmt.Errorf("domain %q contains invalid character %q", domain, c)
+			}
+		}
+	}
+
+	return nil
+}
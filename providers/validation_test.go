@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+func TestFactoryValidateConfig(t *testing.T) {
+	valid := ddns.Config{
+		Provider:       "duckdns",
+		APIKey:         "c0ffee00-1234-4abc-9def-0123456789ab",
+		Domain:         "home.duckdns.org",
+		RecordType:     "A",
+		TTL:            300,
+		UpdateInterval: 5 * time.Minute,
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*ddns.Config)
+		wantErr []string
+	}{
+		{
+			name:   "valid config",
+			modify: func(c *ddns.Config) {},
+		},
+		{
+			name:    "invalid domain",
+			modify:  func(c *ddns.Config) { c.Domain = "-bad-.example.com" },
+			wantErr: []string{"hyphen"},
+		},
+		{
+			name:    "unsupported record type",
+			modify:  func(c *ddns.Config) { c.RecordType = "TXT" },
+			wantErr: []string{"does not support TXT"},
+		},
+		{
+			name: "SRV record type",
+			modify: func(c *ddns.Config) {
+				c.Provider, c.Username, c.APIKey = "namedotcom", "alice", "token"
+				c.RecordType = "SRV"
+			},
+			wantErr: []string{"SRV records can't be configured"},
+		},
+		{
+			name: "CNAME with valid target",
+			modify: func(c *ddns.Config) {
+				c.Provider = "linode"
+				c.RecordType = "CNAME"
+				c.Target = "myhost.dyndns.example.net"
+			},
+		},
+		{
+			name: "CNAME without target",
+			modify: func(c *ddns.Config) {
+				c.Provider = "linode"
+				c.RecordType = "CNAME"
+			},
+			wantErr: []string{"require a target hostname"},
+		},
+		{
+			name: "CNAME with unqualified target",
+			modify: func(c *ddns.Config) {
+				c.Provider = "linode"
+				c.RecordType = "CNAME"
+				c.Target = "localhost"
+			},
+			wantErr: []string{"not a fully qualified name"},
+		},
+		{
+			name: "CNAME on an IP-only provider",
+			modify: func(c *ddns.Config) {
+				c.RecordType = "CNAME"
+				c.Target = "myhost.example.net"
+			},
+			wantErr: []string{"does not support CNAME"},
+		},
+		{
+			name:    "non-positive interval",
+			modify:  func(c *ddns.Config) { c.UpdateInterval = 0 },
+			wantErr: []string{"update interval"},
+		},
+		{
+			name: "multiple problems are all reported",
+			modify: func(c *ddns.Config) {
+				c.APIKey = ""
+				c.Domain = "bad domain"
+				c.UpdateInterval = -time.Second
+			},
+			wantErr: []string{"API key", "invalid character", "update interval"},
+		},
+	}
+
+	factory := NewFactory()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid
+			tt.modify(&config)
+
+			err := factory.ValidateConfig(config)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Expected validation error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Expected error to mention %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestFactoryCreateProviders(t *testing.T) {
+	factory := NewFactory()
+
+	providers, err := factory.CreateProviders([]ddns.Config{
+		{Provider: "duckdns", APIKey: "c0ffee00-1234-4abc-9def-0123456789ab"},
+		{Provider: "mock"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(providers) != 2 || providers[0].GetProviderName() != "duckdns" {
+		t.Errorf("Expected duckdns and mock providers, got %v", providers)
+	}
+
+	_, err = factory.CreateProviders([]ddns.Config{
+		{Provider: "duckdns"},
+		{Provider: "unknown"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "duckdns") || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Expected errors for both invalid configs, got %v", err)
+	}
+}
@@ -0,0 +1,385 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+const vultrBaseURL = "https://api.vultr.com/v2"
+
+// VultrProvider implements the DDNS Provider interface for Vultr DNS
+type VultrProvider struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+	executor   *executor.Executor
+
+	// rateLimitDelay is set from X-RateLimit-Reset when Vultr returns 429
+	mu             sync.Mutex
+	rateLimitDelay time.Duration
+}
+
+// VultrConfig holds Vultr-specific configuration
+type VultrConfig struct {
+	APIKey     string
+	HTTPClient *http.Client       // Optional shared client; a bare client is used when nil
+	Executor   *executor.Executor // Optional shared executor; by default retries wait for X-RateLimit-Reset
+}
+
+// vultrRecord represents a DNS record in the Vultr API
+type vultrRecord struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type,omitempty"`
+	Name string `json:"name"`
+	Data string `json:"data"`
+	TTL  int    `json:"ttl,omitempty"`
+}
+
+// vultrRateLimitError is returned when Vultr responds with 429 Too Many Requests
+type vultrRateLimitError struct {
+	resetAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *vultrRateLimitError) Error() string {
+	return fmt.Sprintf("Vultr rate limit exceeded, resets in %s", e.resetAfter)
+}
+
+// NewVultrProvider creates a new Vultr DDNS provider
+func NewVultrProvider(config VultrConfig) *VultrProvider {
+	v := &VultrProvider{
+		apiKey:     config.APIKey,
+		baseURL:    vultrBaseURL,
+		httpClient: httpClientOrDefault(config.HTTPClient),
+	}
+
+	if config.Executor != nil {
+		// Rate limit errors carry the reset delay, which the shared executor honours too
+		v.executor = config.Executor
+		return v
+	}
+
+	// Back off exponentially, unless Vultr told us exactly when the rate limit resets
+	v.executor = executor.NewExecutor(
+		executor.WithRetryStrategy(executor.NewConditionalRetryStrategy(3, time.Second, nil, v.retryDelay)),
+		executor.WithTimeoutStrategy(executor.NewFixedTimeoutStrategy(30*time.Second)),
+		executor.WithRetryCallback(v.onRetry),
+	)
+
+	return v
+}
+
+// UpdateRecord updates a DNS record in Vultr, creating it if it does not exist yet
+func (v *VultrProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, v.GetProviderName(), "UpdateRecord")
+
+	task := func(taskCtx context.Context) (*ddns.UpdateResponse, error) {
+		zone, err := v.findZone(taskCtx, req.Domain)
+		if err != nil {
+			return nil, err
+		}
+
+		name := relativeName(req.Domain, zone)
+
+		records, err := v.paginateVultrRecords(taskCtx, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		existing := findVultrRecord(records, name, req.RecordType)
+		recordID := ""
+		if existing != nil {
+			recordID = existing.ID
+			path := fmt.Sprintf("/domains/%s/records/%s", url.PathEscape(zone), url.PathEscape(existing.ID))
+			err = v.do(taskCtx, "PATCH", path, vultrRecord{Name: name, Data: req.Value, TTL: req.TTL}, nil)
+		} else {
+			var created struct {
+				Record vultrRecord `json:"record"`
+			}
+			record := vultrRecord{Type: req.RecordType, Name: name, Data: req.Value, TTL: req.TTL}
+			err = v.do(taskCtx, "POST", fmt.Sprintf("/domains/%s/records", url.PathEscape(zone)), record, &created)
+			recordID = created.Record.ID
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return &ddns.UpdateResponse{
+			Success:   true,
+			Message:   "Vultr record updated successfully",
+			RecordID:  recordID,
+			UpdatedAt: time.Now(),
+		}, nil
+	}
+
+	return executor.ExecuteSimple(v.executor, ctx, task)
+}
+
+// GetCurrentRecord retrieves the current DNS record value
+func (v *VultrProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, v.GetProviderName(), "GetCurrentRecord")
+
+	task := func(taskCtx context.Context) (*vultrRecord, error) {
+		zone, err := v.findZone(taskCtx, domain)
+		if err != nil {
+			return nil, err
+		}
+
+		records, err := v.paginateVultrRecords(taskCtx, zone)
+		if err != nil {
+			return nil, err
+		}
+
+		return findVultrRecord(records, relativeName(domain, zone), recordType), nil
+	}
+
+	record, err := executor.ExecuteSimple(v.executor, ctx, task)
+	if err != nil {
+		return "", err
+	}
+
+	if record == nil {
+		return "", fmt.Errorf("Vultr %w: %s %s", ddns.ErrNotFound, domain, recordType)
+	}
+
+	return record.Data, nil
+}
+
+// ValidateCredentials checks if the Vultr API key is valid
+func (v *VultrProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, v.GetProviderName(), "ValidateCredentials")
+
+	task := func(taskCtx context.Context) (interface{}, error) {
+		return nil, v.do(taskCtx, "GET", "/account", nil, nil)
+	}
+
+	_, err = executor.ExecuteSimple(v.executor, ctx, task)
+	return err
+}
+
+// GetProviderName returns the name of the provider
+func (v *VultrProvider) GetProviderName() string {
+	return "vultr"
+}
+
+// Capabilities reports the record types Vultr supports
+func (v *VultrProvider) Capabilities() ddns.Capabilities {
+	return recordCapabilities("vultr")
+}
+
+// findZone finds the Vultr domain (zone) the record belongs to
+func (v *VultrProvider) findZone(ctx context.Context, fqdn string) (string, error) {
+	var zones []string
+	cursor := ""
+	for {
+		var page struct {
+			Domains []struct {
+				Domain string `json:"domain"`
+			} `json:"domains"`
+			Meta vultrMeta `json:"meta"`
+		}
+		if err := v.do(ctx, "GET", "/domains"+vultrPageQuery(cursor), nil, &page); err != nil {
+			return "", err
+		}
+
+		for _, domain := range page.Domains {
+			zones = append(zones, domain.Domain)
+		}
+
+		cursor = page.Meta.Links.Next
+		if cursor == "" {
+			break
+		}
+	}
+
+	// Prefer the longest zone name the record falls under
+	best := closestZone(fqdn, zones, func(zone string) string { return zone })
+	if best == nil {
+		return "", fmt.Errorf("no Vultr domain found for %s", fqdn)
+	}
+
+	return *best, nil
+}
+
+// vultrMeta holds Vultr's cursor-based pagination metadata
+type vultrMeta struct {
+	Total int `json:"total"`
+	Links struct {
+		Next string `json:"next"`
+		Prev string `json:"prev"`
+	} `json:"links"`
+}
+
+// paginateVultrRecords fetches every record in the zone, following pagination cursors
+func (v *VultrProvider) paginateVultrRecords(ctx context.Context, zone string) ([]vultrRecord, error) {
+	var records []vultrRecord
+	cursor := ""
+	for {
+		var page struct {
+			Records []vultrRecord `json:"records"`
+			Meta    vultrMeta     `json:"meta"`
+		}
+		path := fmt.Sprintf("/domains/%s/records%s", url.PathEscape(zone), vultrPageQuery(cursor))
+		if err := v.do(ctx, "GET", path, nil, &page); err != nil {
+			return nil, err
+		}
+
+		records = append(records, page.Records...)
+
+		cursor = page.Meta.Links.Next
+		if cursor == "" {
+			return records, nil
+		}
+	}
+}
+
+// vultrPageQuery builds the query string for a page of results
+func vultrPageQuery(cursor string) string {
+	params := url.Values{}
+	params.Set("per_page", "500")
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	return "?" + params.Encode()
+}
+
+// findVultrRecord returns the record matching name and type, or nil
+func findVultrRecord(records []vultrRecord, name, recordType string) *vultrRecord {
+	return findRecordByNameType(records, name, recordType, func(r vultrRecord) (string, string) {
+		return r.Name, r.Type
+	})
+}
+
+// retryDelay returns the rate limit reset delay if one is pending, otherwise an exponential backoff
+func (v *VultrProvider) retryDelay(attempt int) time.Duration {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.rateLimitDelay > 0 {
+		return v.rateLimitDelay
+	}
+
+	return time.Duration(float64(time.Second) * math.Pow(2, float64(attempt-1)))
+}
+
+// onRetry clears the pending rate limit delay once the executor has applied it to a retry
+func (v *VultrProvider) onRetry(attempt int, err error, delay time.Duration) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.rateLimitDelay = 0
+}
+
+// do performs an authenticated Vultr API request, decoding the response into out if non-nil
+func (v *VultrProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, v.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+v.apiKey)
+	req.Header.Set("User-Agent", ddns.DefaultUserAgent)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resetAfter := parseRateLimitReset(resp.Header.Get("X-RateLimit-Reset"), time.Now())
+
+		v.mu.Lock()
+		v.rateLimitDelay = resetAfter
+		v.mu.Unlock()
+
+		return &executor.RetryAfterError{Err: httpStatusError(resp, &vultrRateLimitError{resetAfter: resetAfter}), RetryAfter: resetAfter}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(data, &apiErr); err == nil && apiErr.Error != "" {
+			return httpStatusError(resp, fmt.Errorf("Vultr API error (HTTP %d): %s", resp.StatusCode, apiErr.Error))
+		}
+		return httpStatusError(resp, nil)
+	}
+
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// parseRateLimitReset interprets X-RateLimit-Reset as a Unix timestamp or a number of seconds
+func parseRateLimitReset(value string, now time.Time) time.Duration {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || seconds <= 0 {
+		return time.Second
+	}
+
+	// Values this large are Unix timestamps rather than relative delays
+	if seconds > 1_000_000_000 {
+		delay := time.Unix(seconds, 0).Sub(now)
+		if delay < time.Second {
+			return time.Second
+		}
+		return delay
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// registerVultr adds the Vultr provider to the factory
+func registerVultr(f *Factory) {
+	f.Register("vultr", func(config ddns.Config) (ddns.Provider, error) {
+		if config.APIKey == "" {
+			return nil, fmt.Errorf("vultr provider requires API key")
+		}
+
+		client, err := f.clientFor(config)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewVultrProvider(VultrConfig{
+			APIKey:     config.APIKey,
+			HTTPClient: client,
+			Executor:   f.executor,
+		}), nil
+	})
+}
@@ -0,0 +1,107 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jq1836/DDNS/ddns"
+	"github.com/jq1836/DDNS/executor"
+)
+
+func TestVultrUsesFactoryExecutor(t *testing.T) {
+	exec := executor.NewExecutor(executor.WithRetryStrategy(executor.NewNoRetryStrategy()))
+
+	provider, err := NewFactory(WithExecutor(exec)).CreateProvider(ddns.Config{Provider: "vultr", APIKey: "key"})
+	if err != nil {
+		t.Fatalf("Expected provider, got %v", err)
+	}
+	if provider.(*VultrProvider).executor != exec {
+		t.Error("Expected the factory's executor to be used")
+	}
+}
+
+func TestVultrPaginateRecords(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Expected bearer token, got %q", r.Header.Get("Authorization"))
+		}
+
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"records":[{"id":"1","type":"A","name":"www","data":"203.0.113.1"}],"meta":{"links":{"next":"page2"}}}`)
+		case "page2":
+			fmt.Fprint(w, `{"records":[{"id":"2","type":"A","name":"home","data":"203.0.113.2"}],"meta":{"links":{"next":""}}}`)
+		default:
+			t.Errorf("Unexpected cursor %q", r.URL.Query().Get("cursor"))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewVultrProvider(VultrConfig{APIKey: "test-key"})
+	provider.baseURL = server.URL
+
+	records, err := provider.paginateVultrRecords(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 records across both pages, got %d", len(records))
+	}
+
+	if record := findVultrRecord(records, "home", "A"); record == nil || record.Data != "203.0.113.2" {
+		t.Errorf("Expected to find home A record on the second page, got %+v", record)
+	}
+}
+
+func TestVultrRateLimitSetsRetryDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Reset", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := NewVultrProvider(VultrConfig{APIKey: "test-key"})
+	provider.baseURL = server.URL
+
+	err := provider.do(context.Background(), "GET", "/account", nil, nil)
+	var retryAfter *executor.RetryAfterError
+	if !errors.As(err, &retryAfter) || retryAfter.RetryAfter != 7*time.Second {
+		t.Fatalf("Expected a rate limit error asking for a 7s delay, got %v", err)
+	}
+
+	if delay := provider.retryDelay(1); delay != 7*time.Second {
+		t.Errorf("Expected retry delay of 7s from X-RateLimit-Reset, got %s", delay)
+	}
+
+	// Once applied, the delay falls back to the normal backoff
+	provider.onRetry(1, nil, 7*time.Second)
+	if delay := provider.retryDelay(1); delay != time.Second {
+		t.Errorf("Expected default backoff of 1s after the reset delay was used, got %s", delay)
+	}
+}
+
+func TestParseRateLimitReset(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		value    string
+		expected time.Duration
+	}{
+		{"30", 30 * time.Second},
+		{"1700000060", time.Minute},
+		{"", time.Second},
+		{"garbage", time.Second},
+	}
+
+	for _, tt := range tests {
+		if result := parseRateLimitReset(tt.value, now); result != tt.expected {
+			t.Errorf("parseRateLimitReset(%q) = %v, want %v", tt.value, result, tt.expected)
+		}
+	}
+}
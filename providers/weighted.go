@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+// ErrNoWeightedProvider is returned when no entry of a WeightedProvider has a positive weight
+var ErrNoWeightedProvider = errors.New("no provider has a positive weight")
+
+// WeightedEntry is a provider and its share of the updates sent through a WeightedProvider
+type WeightedEntry struct {
+	Provider ddns.Provider
+	Weight   int // Entries with a weight below 1 are never picked for updates
+}
+
+// WeightedProvider spreads updates across several providers by weighted round-robin
+type WeightedProvider struct {
+	entries  []WeightedEntry
+	schedule []int // Indexes into entries, one per unit of weight, interleaved
+	next     atomic.Int64
+}
+
+// NewWeightedProvider creates a provider that sends each update to the next provider in rotation
+// A provider with weight 2 gets twice the updates of one with weight 1, interleaved rather than back to back
+func NewWeightedProvider(providers []WeightedEntry) *WeightedProvider {
+	return &WeightedProvider{
+		entries:  providers,
+		schedule: weightedSchedule(providers),
+	}
+}
+
+// UpdateRecord updates the record on the next provider in rotation
+func (w *WeightedProvider) UpdateRecord(ctx context.Context, req ddns.UpdateRequest) (_ *ddns.UpdateResponse, err error) {
+	defer wrapProviderError(&err, w.GetProviderName(), "UpdateRecord")
+
+	if len(w.schedule) == 0 {
+		return nil, ErrNoWeightedProvider
+	}
+
+	turn := w.next.Add(1) - 1
+	provider := w.entries[w.schedule[turn%int64(len(w.schedule))]].Provider
+
+	resp, err := provider.UpdateRecord(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetCurrentRecord queries every provider and returns the value held by a majority of those that answered
+func (w *WeightedProvider) GetCurrentRecord(ctx context.Context, domain, recordType string) (_ string, err error) {
+	defer wrapProviderError(&err, w.GetProviderName(), "GetCurrentRecord")
+
+	counts := make(map[string]int)
+	answered := 0
+	var errs []error
+
+	for _, entry := range w.entries {
+		value, err := entry.Provider.GetCurrentRecord(ctx, domain, recordType)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		counts[value]++
+		answered++
+	}
+
+	if answered == 0 {
+		if len(errs) == 0 {
+			return "", ErrNoWeightedProvider
+		}
+		return "", errors.Join(errs...)
+	}
+
+	for value, count := range counts {
+		if count*2 > answered {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("no majority value for %s %s among %d providers", domain, recordType, answered)
+}
+
+// ValidateCredentials validates every provider, reporting all failures together
+func (w *WeightedProvider) ValidateCredentials(ctx context.Context) (err error) {
+	defer wrapProviderError(&err, w.GetProviderName(), "ValidateCredentials")
+
+	var errs []error
+	for _, entry := range w.entries {
+		if err := entry.Provider.ValidateCredentials(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// GetProviderName returns the names of the wrapped providers
+func (w *WeightedProvider) GetProviderName() string {
+	names := make([]string, len(w.entries))
+	for i, entry := range w.entries {
+		names[i] = entry.Provider.GetProviderName()
+	}
+	return "weighted(" + strings.Join(names, ",") + ")"
+}
+
+// Capabilities reports what every wrapped provider supports, since any of them may get an update
+func (w *WeightedProvider) Capabilities() ddns.Capabilities {
+	if len(w.entries) == 0 {
+		return ddns.Capabilities{}
+	}
+
+	capabilities := w.entries[0].Provider.Capabilities()
+	for _, entry := range w.entries[1:] {
+		other := entry.Provider.Capabilities()
+		capabilities.SupportsRead = capabilities.SupportsRead && other.SupportsRead
+		capabilities.SupportsAAAA = capabilities.SupportsAAAA && other.SupportsAAAA
+		capabilities.SupportsTXT = capabilities.SupportsTXT && other.SupportsTXT
+		capabilities.SupportsCNAME = capabilities.SupportsCNAME && other.SupportsCNAME
+		capabilities.SupportsSRV = capabilities.SupportsSRV && other.SupportsSRV
+	}
+	return capabilities
+}
+
+// weightedSchedule lays out one rotation of smooth weighted round-robin: each step picks the entry
+// with the highest accumulated weight, so heavier entries recur evenly instead of in runs
+func weightedSchedule(entries []WeightedEntry) []int {
+	total := 0
+	for _, entry := range entries {
+		if entry.Weight > 0 {
+			total += entry.Weight
+		}
+	}
+
+	current := make([]int, len(entries))
+	schedule := make([]int, 0, total)
+	for len(schedule) < total {
+		best := -1
+		for i, entry := range entries {
+			if entry.Weight <= 0 {
+				continue
+			}
+			current[i] += entry.Weight
+			if best < 0 || current[i] > current[best] {
+				best = i
+			}
+		}
+		current[best] -= total
+		schedule = append(schedule, best)
+	}
+	return schedule
+}
@@ -0,0 +1,77 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jq1836/DDNS/ddns"
+)
+
+func TestWeightedProviderRotation(t *testing.T) {
+	a, b := NewMockProvider("a"), NewMockProvider("b")
+	weighted := NewWeightedProvider([]WeightedEntry{{Provider: a, Weight: 2}, {Provider: b, Weight: 1}, {Provider: NewMockProvider("off")}})
+
+	var picked []string
+	for i := 0; i < 6; i++ {
+		req := ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.1"}
+		if _, err := weighted.UpdateRecord(context.Background(), req); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, provider := range []*MockProvider{a, b} {
+			if calls := provider.CallsForMethod("UpdateRecord"); len(calls) > 0 {
+				picked = append(picked, provider.GetProviderName())
+				provider.ResetCalls()
+			}
+		}
+	}
+
+	if want := []string{"mock-a", "mock-b", "mock-a", "mock-a", "mock-b", "mock-a"}; !slices.Equal(picked, want) {
+		t.Errorf("Expected rotation %v, got %v", want, picked)
+	}
+}
+
+func TestWeightedProviderWithoutWeights(t *testing.T) {
+	weighted := NewWeightedProvider([]WeightedEntry{{Provider: NewMockProvider("off")}})
+
+	_, err := weighted.UpdateRecord(context.Background(), ddns.UpdateRequest{Domain: "example.com", RecordType: "A", Value: "203.0.113.1"})
+	if !errors.Is(err, ErrNoWeightedProvider) {
+		t.Errorf("Expected ErrNoWeightedProvider, got %v", err)
+	}
+}
+
+func TestWeightedProviderGetCurrentRecordMajority(t *testing.T) {
+	a, b, c := NewMockProvider("a"), NewMockProvider("b"), NewMockProvider("c")
+	a.SetRecord("example.com", "A", "203.0.113.1")
+	b.SetRecord("example.com", "A", "203.0.113.1")
+	c.SetRecord("example.com", "A", "203.0.113.2")
+	weighted := NewWeightedProvider([]WeightedEntry{{Provider: a, Weight: 1}, {Provider: b, Weight: 1}, {Provider: c, Weight: 1}})
+
+	value, err := weighted.GetCurrentRecord(context.Background(), "example.com", "A")
+	if err != nil || value != "203.0.113.1" {
+		t.Errorf("Expected majority value 203.0.113.1, got %q (%v)", value, err)
+	}
+
+	// Failed reads don't count, leaving a tie
+	a.WithFailure(true)
+	if _, err := weighted.GetCurrentRecord(context.Background(), "example.com", "A"); err == nil || !strings.Contains(err.Error(), "no majority") {
+		t.Errorf("Expected no majority error, got %v", err)
+	}
+}
+
+func TestWeightedProviderValidatesAll(t *testing.T) {
+	weighted := NewWeightedProvider([]WeightedEntry{
+		{Provider: NewMockProvider("a").WithValidationError(errors.New("bad key")), Weight: 1},
+		{Provider: NewMockProvider("b").WithValidationError(errors.New("expired")), Weight: 1},
+	})
+
+	err := weighted.ValidateCredentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "mock-a ValidateCredentials: bad key") || !strings.Contains(err.Error(), "mock-b ValidateCredentials: expired") {
+		t.Errorf("Expected both validation errors, got %v", err)
+	}
+	if name := weighted.GetProviderName(); name != "weighted(mock-a,mock-b)" {
+		t.Errorf("Unexpected provider name %s", name)
+	}
+}
@@ -0,0 +1,56 @@
+package providers
+
+import "strings"
+
+// splitZone splits a fully qualified domain into the registered domain (its last two labels)
+// and the host part relative to it, which is empty for the apex
+func splitZone(fqdn string) (string, string) {
+	labels := strings.Split(strings.TrimSuffix(fqdn, "."), ".")
+	if len(labels) <= 2 {
+		return strings.Join(labels, "."), ""
+	}
+
+	return strings.Join(labels[len(labels)-2:], "."), strings.Join(labels[:len(labels)-2], ".")
+}
+
+// relativeName returns the record name relative to its zone, empty for the apex
+func relativeName(fqdn, zone string) string {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	if strings.EqualFold(fqdn, zone) {
+		return ""
+	}
+	return strings.TrimSuffix(fqdn, "."+zone)
+}
+
+// isSubdomainOf reports whether fqdn equals zone or falls under it
+func isSubdomainOf(fqdn, zone string) bool {
+	fqdn = strings.ToLower(strings.TrimSuffix(fqdn, "."))
+	zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+	return fqdn == zone || strings.HasSuffix(fqdn, "."+zone)
+}
+
+// closestZone returns the zone with the longest name that fqdn falls under, or nil if none match
+func closestZone[Z any](fqdn string, zones []Z, zoneName func(Z) string) *Z {
+	var best *Z
+	bestLen := 0
+	for i, zone := range zones {
+		name := zoneName(zone)
+		if isSubdomainOf(fqdn, name) && (best == nil || len(name) > bestLen) {
+			best = &zones[i]
+			bestLen = len(name)
+		}
+	}
+	return best
+}
+
+// findRecordByNameType returns the first record whose name and type match, ignoring case
+// The fields function extracts the name and type from a provider-specific record
+func findRecordByNameType[R any](records []R, name, recordType string, fields func(R) (string, string)) *R {
+	for i, record := range records {
+		recordName, recordTypeValue := fields(record)
+		if strings.EqualFold(recordName, name) && strings.EqualFold(recordTypeValue, recordType) {
+			return &records[i]
+		}
+	}
+	return nil
+}
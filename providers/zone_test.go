@@ -0,0 +1,68 @@
+package providers
+
+import "testing"
+
+func TestSplitZone(t *testing.T) {
+	tests := []struct {
+		fqdn, zone, host string
+	}{
+		{"example.com", "example.com", ""},
+		{"home.example.com.", "example.com", "home"},
+		{"a.b.example.com", "example.com", "a.b"},
+	}
+
+	for _, tt := range tests {
+		zone, host := splitZone(tt.fqdn)
+		if zone != tt.zone || host != tt.host {
+			t.Errorf("splitZone(%q) = (%q, %q), expected (%q, %q)", tt.fqdn, zone, host, tt.zone, tt.host)
+		}
+	}
+}
+
+func TestRelativeName(t *testing.T) {
+	tests := []struct {
+		fqdn, zone, expected string
+	}{
+		{"example.com", "example.com", ""},
+		{"home.example.com.", "example.com", "home"},
+		{"a.b.example.co.uk", "example.co.uk", "a.b"},
+	}
+
+	for _, tt := range tests {
+		if got := relativeName(tt.fqdn, tt.zone); got != tt.expected {
+			t.Errorf("relativeName(%q, %q) = %q, expected %q", tt.fqdn, tt.zone, got, tt.expected)
+		}
+	}
+}
+
+func TestClosestZone(t *testing.T) {
+	zones := []string{"example.com", "sub.example.com", "other.com", "ample.com"}
+	name := func(zone string) string { return zone }
+
+	if best := closestZone("home.sub.example.com", zones, name); best == nil || *best != "sub.example.com" {
+		t.Errorf("Expected the longest matching zone sub.example.com, got %v", best)
+	}
+
+	if best := closestZone("example.com", zones, name); best == nil || *best != "example.com" {
+		t.Errorf("Expected the apex to match its own zone, got %v", best)
+	}
+
+	if best := closestZone("home.missing.org", zones, name); best != nil {
+		t.Errorf("Expected no zone, got %s", *best)
+	}
+}
+
+func TestFindRecordByNameType(t *testing.T) {
+	records := []linodeRecord{
+		{ID: 1, Name: "home", Type: "AAAA"},
+		{ID: 2, Name: "Home", Type: "a"},
+	}
+
+	if record := findRecordByNameType(records, "home", "A", linodeRecordFields); record == nil || record.ID != 2 {
+		t.Errorf("Expected case-insensitive match on record 2, got %+v", record)
+	}
+
+	if record := findRecordByNameType(records, "www", "A", linodeRecordFields); record != nil {
+		t.Errorf("Expected no match, got %+v", record)
+	}
+}
@@ -0,0 +1,51 @@
+// Package systemd implements the sd_notify protocol, so the client can report its state to systemd
+// when running as a Type=notify service, with or without WatchdogSec=
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notify sends state (e.g. "READY=1" or "WATCHDOG=1") to the socket named by NOTIFY_SOCKET
+// It does nothing when NOTIFY_SOCKET is unset, i.e. when not running under systemd
+func Notify(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	// A leading @ names an abstract socket, which the net package handles itself
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to send %q to notify socket: %w", state, err)
+	}
+	return nil
+}
+
+// WatchdogInterval returns the watchdog timeout systemd set through WATCHDOG_USEC, or 0 when the
+// watchdog is disabled or meant for another process (WATCHDOG_PID)
+func WatchdogInterval() (time.Duration, error) {
+	value := os.Getenv("WATCHDOG_USEC")
+	if value == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	usec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC %q", value)
+	}
+	return time.Duration(usec) * time.Microsecond, nil
+}
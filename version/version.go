@@ -0,0 +1,48 @@
+// Package version holds build information injected at build time with:
+//
+//	go build -ldflags "-X github.com/jq1836/DDNS/version.Version=1.2.3 -X github.com/jq1836/DDNS/version.GitCommit=$(git rev-parse --short HEAD) -X github.com/jq1836/DDNS/version.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// The Makefile's build target sets all of them.
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build information; builds without ldflags report "dev" and "unknown"
+var (
+	Version   = "dev"
+	GitCommit = "unknown"
+	BuildDate = "unknown"
+	GoVersion = "" // Defaults to the version of the running toolchain
+)
+
+// Info is the build information in a form suited to JSON output
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	goVersion := GoVersion
+	if goVersion == "" {
+		goVersion = runtime.Version()
+	}
+
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: goVersion,
+	}
+}
+
+// BuildInfo formats every field for human-readable output
+func BuildInfo() string {
+	info := Get()
+	return fmt.Sprintf("ddns-client %s (commit %s, built %s, %s)", info.Version, info.GitCommit, info.BuildDate, info.GoVersion)
+}
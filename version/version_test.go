@@ -0,0 +1,39 @@
+package version
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	original := Version
+	Version = "1.2.3"
+	t.Cleanup(func() { Version = original })
+
+	info := Get()
+	if info.Version != "1.2.3" {
+		t.Errorf("Expected version 1.2.3, got %s", info.Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected Go version to default to %s, got %s", runtime.Version(), info.GoVersion)
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		t.Fatalf("Failed to marshal info: %v", err)
+	}
+	if want := `{"version":"1.2.3","git_commit":"unknown","build_date":"unknown","go_version":"` + runtime.Version() + `"}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	original := GoVersion
+	GoVersion = "go1.24.5"
+	t.Cleanup(func() { GoVersion = original })
+
+	if want := "ddns-client dev (commit unknown, built unknown, go1.24.5)"; BuildInfo() != want {
+		t.Errorf("Expected %q, got %q", want, BuildInfo())
+	}
+}